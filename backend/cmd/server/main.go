@@ -134,7 +134,31 @@ func main() {
 	}
 
 	// Create proxy
-	proxy := gateway.NewProxy(repo, encryptor, authorizer, stdioManager, k8sManager, obsHub)
+	proxy := gateway.NewProxy(repo, encryptor, authorizer, stdioManager, k8sManager, obsHub, gateway.ProxyConfig{
+		ValidateOutputSchema: cfg.Gateway.ValidateOutputSchema,
+		ToolDelimiter:        cfg.Gateway.ToolDelimiter,
+		ApprovalTimeout:      cfg.Gateway.ApprovalTimeout,
+		RateLimitStore:       cfg.Gateway.RateLimitStore,
+		ResponseCacheStore:   cfg.Gateway.ResponseCacheStore,
+		UpstreamTimeout:      cfg.Gateway.UpstreamTimeout,
+		ToolCallTimeout:      cfg.Gateway.ToolCallTimeout,
+		UpstreamMaxAttempts:  cfg.Gateway.UpstreamMaxAttempts,
+		RetryBackoff:         cfg.Gateway.RetryBackoff,
+		BreakerFailures:      cfg.Gateway.BreakerFailures,
+		BreakerCooldown:      cfg.Gateway.BreakerCooldown,
+		SkipUnhealthyTargets: cfg.HealthCheck.Enabled && cfg.HealthCheck.SkipUnhealthy,
+		HealthCheckInterval:  cfg.HealthCheck.Interval,
+	})
+
+	// Start target health checks (optional)
+	if cfg.HealthCheck.Enabled {
+		healthChecker := gateway.NewHealthChecker(proxy, repo, gateway.HealthCheckerConfig{
+			Interval: cfg.HealthCheck.Interval,
+			Timeout:  cfg.HealthCheck.Timeout,
+		})
+		defer healthChecker.Stop()
+		log.Info().Dur("interval", cfg.HealthCheck.Interval).Msg("Target health checks enabled")
+	}
 
 	// Create MCP gateway handler
 	mcpHandler := gateway.NewHandler(sessionManager, proxy, repo, obsHub)
@@ -176,8 +200,10 @@ func main() {
 
 	// MCP Streamable HTTP endpoint (protected)
 	// Supports POST (JSON-RPC requests), GET (SSE notification stream), DELETE (session termination)
+	// and GET /mcp/ws (MCP over WebSocket)
 	r.Route("/mcp", func(r chi.Router) {
 		r.Use(authMiddleware.Authenticate)
+		r.Get("/ws", mcpHandler.HandleWebSocket)
 		r.HandleFunc("/*", mcpHandler.HandleMCP)
 		r.HandleFunc("/", mcpHandler.HandleMCP)
 	})
@@ -196,7 +222,7 @@ func main() {
 		if k8sManager != nil {
 			instanceRestarter = k8sManager
 		}
-		r.Mount("/", api.Router(repo, jwtManager, encryptor, authMiddleware, sessionManager, instanceRestarter))
+		r.Mount("/", api.Router(repo, jwtManager, encryptor, authMiddleware, sessionManager, instanceRestarter, cfg.Gateway.ToolDelimiter))
 
 		// Observability WebSocket (auth required)
 		r.Group(func(r chi.Router) {
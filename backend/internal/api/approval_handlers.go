@@ -0,0 +1,167 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/reflow/gateway/internal/auth"
+	"github.com/reflow/gateway/internal/database"
+)
+
+// ApprovalHandlers handles the approval queue for tool calls parked by
+// require_approval policies
+type ApprovalHandlers struct {
+	repo *database.Repository
+}
+
+// NewApprovalHandlers creates new approval handlers
+func NewApprovalHandlers(repo *database.Repository) *ApprovalHandlers {
+	return &ApprovalHandlers{repo: repo}
+}
+
+// ListApprovals returns the most recent approvals the caller may decide or has
+// requested; admins see all of them. ?status= filters by status.
+func (h *ApprovalHandlers) ListApprovals(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	role, _ := auth.GetUserRole(r.Context())
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", database.ApprovalPending, database.ApprovalApproved, database.ApprovalRejected,
+		database.ApprovalExpired, database.ApprovalCancelled:
+	default:
+		writeError(w, http.StatusBadRequest, "Invalid status")
+		return
+	}
+
+	limit := 50
+	if l := r.URL.Query().Get("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
+			limit = parsed
+		}
+	}
+
+	approvals, err := h.repo.ListApprovals(r.Context(), status, role == "admin", role, userID, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to get approvals")
+		return
+	}
+
+	if approvals == nil {
+		approvals = []*database.Approval{}
+	}
+
+	writeJSON(w, http.StatusOK, approvals)
+}
+
+// GetApproval returns an approval, including the full arguments of the call
+func (h *ApprovalHandlers) GetApproval(w http.ResponseWriter, r *http.Request) {
+	approval, ok := h.loadApproval(w, r)
+	if !ok {
+		return
+	}
+
+	userID, _ := auth.GetUserID(r.Context())
+	role, _ := auth.GetUserRole(r.Context())
+	if role != "admin" && role != approval.ApproverRole && userID != approval.UserID {
+		writeError(w, http.StatusNotFound, "Approval not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, approval)
+}
+
+// ApproveApproval lets the parked tool call proceed
+func (h *ApprovalHandlers) ApproveApproval(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, database.ApprovalApproved)
+}
+
+// RejectApproval fails the parked tool call
+func (h *ApprovalHandlers) RejectApproval(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, database.ApprovalRejected)
+}
+
+// decide records an approver's decision. Admins and users with the approval's
+// approver role may decide, but not on their own calls.
+func (h *ApprovalHandlers) decide(w http.ResponseWriter, r *http.Request, status string) {
+	approval, ok := h.loadApproval(w, r)
+	if !ok {
+		return
+	}
+
+	userID, _ := auth.GetUserID(r.Context())
+	userEmail, _ := auth.GetUserEmail(r.Context())
+	role, _ := auth.GetUserRole(r.Context())
+	if role != "admin" && role != approval.ApproverRole {
+		writeError(w, http.StatusForbidden, "Role '"+approval.ApproverRole+"' required to decide this approval")
+		return
+	}
+	if userID == approval.UserID {
+		writeError(w, http.StatusForbidden, "Cannot decide your own tool call")
+		return
+	}
+
+	var req database.DecideApprovalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if approval.Status == database.ApprovalPending && time.Now().After(approval.ExpiresAt) {
+		// The gateway waiting on it went away without closing it
+		h.repo.DecideApproval(r.Context(), approval.ID, database.ApprovalExpired, nil, nil, nil)
+		writeError(w, http.StatusConflict, "Approval has expired")
+		return
+	}
+
+	var reason *string
+	if req.Reason != "" {
+		reason = &req.Reason
+	}
+	decided, err := h.repo.DecideApproval(r.Context(), approval.ID, status, &userID, &userEmail, reason)
+	if err != nil {
+		if err == database.ErrAlreadyDecided {
+			writeError(w, http.StatusConflict, "Approval is no longer pending")
+			return
+		}
+		if err == database.ErrNotFound {
+			writeError(w, http.StatusNotFound, "Approval not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to decide approval")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, decided)
+}
+
+// loadApproval parses the {id} URL parameter and loads the approval, writing
+// the error response if that fails
+func (h *ApprovalHandlers) loadApproval(w http.ResponseWriter, r *http.Request) (*database.Approval, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid approval ID")
+		return nil, false
+	}
+
+	approval, err := h.repo.GetApprovalByID(r.Context(), id)
+	if err != nil {
+		if err == database.ErrNotFound {
+			writeError(w, http.StatusNotFound, "Approval not found")
+			return nil, false
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to get approval")
+		return nil, false
+	}
+	return approval, true
+}
@@ -396,6 +396,33 @@ func (h *Handlers) CreateTarget(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Namespace != "" {
+		if err := database.ValidateNamespace(req.Namespace); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid namespace: "+err.Error())
+			return
+		}
+	}
+
+	if req.ArgumentValidation != "" && !validArgumentValidation(req.ArgumentValidation) {
+		writeError(w, http.StatusBadRequest, "argument_validation must be 'off', 'warn' or 'enforce'")
+		return
+	}
+
+	if req.CacheTTLSeconds < 0 {
+		writeError(w, http.StatusBadRequest, "cache_ttl_seconds must not be negative")
+		return
+	}
+
+	if req.LoadBalancing != "" && !validLoadBalancing(req.LoadBalancing) {
+		writeError(w, http.StatusBadRequest, "load_balancing must be 'round_robin', 'least_outstanding' or 'consistent_hash'")
+		return
+	}
+
+	if err := normalizeEndpoints(req.Endpoints); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	if req.AuthType == "" {
 		req.AuthType = "none"
 	}
@@ -416,11 +443,19 @@ func (h *Handlers) CreateTarget(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	} else {
+		// The first endpoint doubles as the target's URL
+		if req.URL == "" && len(req.Endpoints) > 0 {
+			req.URL = req.Endpoints[0].URL
+		}
 		if req.URL == "" {
-			writeError(w, http.StatusBadRequest, "URL is required for HTTP/SSE transport")
+			writeError(w, http.StatusBadRequest, "URL is required for HTTP/SSE/WebSocket transport")
 			return
 		}
 	}
+	if len(req.Endpoints) > 0 && (req.TransportType == "stdio" || req.TransportType == "kubernetes") {
+		writeError(w, http.StatusBadRequest, "endpoints are only supported for HTTP/SSE/WebSocket transport")
+		return
+	}
 
 	// Validate statefulness
 	if req.Statefulness != "" {
@@ -443,7 +478,7 @@ func (h *Handlers) CreateTarget(w http.ResponseWriter, r *http.Request) {
 	target, err := h.repo.CreateTarget(r.Context(), &req)
 	if err != nil {
 		if err == database.ErrAlreadyExists {
-			writeError(w, http.StatusConflict, "Target name already exists")
+			writeError(w, http.StatusConflict, "Target name or namespace already exists")
 			return
 		}
 		writeError(w, http.StatusInternalServerError, "Failed to create target")
@@ -490,6 +525,38 @@ func (h *Handlers) UpdateTarget(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Namespace != nil {
+		if err := database.ValidateNamespace(*req.Namespace); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid namespace: "+err.Error())
+			return
+		}
+	}
+
+	if req.ArgumentValidation != nil && !validArgumentValidation(*req.ArgumentValidation) {
+		writeError(w, http.StatusBadRequest, "argument_validation must be 'off', 'warn' or 'enforce'")
+		return
+	}
+
+	if req.CacheTTLSeconds != nil && *req.CacheTTLSeconds < 0 {
+		writeError(w, http.StatusBadRequest, "cache_ttl_seconds must not be negative")
+		return
+	}
+
+	if req.LoadBalancing != nil && !validLoadBalancing(*req.LoadBalancing) {
+		writeError(w, http.StatusBadRequest, "load_balancing must be 'round_robin', 'least_outstanding' or 'consistent_hash'")
+		return
+	}
+
+	if req.Endpoints != nil {
+		if err := normalizeEndpoints(*req.Endpoints); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if (req.URL == nil || *req.URL == "") && len(*req.Endpoints) > 0 {
+			req.URL = &(*req.Endpoints)[0].URL
+		}
+	}
+
 	target, err := h.repo.UpdateTarget(r.Context(), id, &req)
 	if err != nil {
 		if err == database.ErrNotFound {
@@ -497,7 +564,7 @@ func (h *Handlers) UpdateTarget(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		if err == database.ErrAlreadyExists {
-			writeError(w, http.StatusConflict, "Target name already exists")
+			writeError(w, http.StatusConflict, "Target name or namespace already exists")
 			return
 		}
 		writeError(w, http.StatusInternalServerError, "Failed to update target")
@@ -1052,3 +1119,42 @@ func writeJSON(w http.ResponseWriter, status int, v interface{}) {
 func writeError(w http.ResponseWriter, status int, message string) {
 	writeJSON(w, status, map[string]string{"error": message})
 }
+
+func validArgumentValidation(mode string) bool {
+	switch mode {
+	case database.ArgumentValidationOff, database.ArgumentValidationWarn, database.ArgumentValidationEnforce:
+		return true
+	}
+	return false
+}
+
+func validLoadBalancing(strategy string) bool {
+	switch strategy {
+	case database.LoadBalancingRoundRobin, database.LoadBalancingLeastOutstanding, database.LoadBalancingConsistentHash:
+		return true
+	}
+	return false
+}
+
+// normalizeEndpoints checks a target's endpoints and gives those without a
+// weight the default weight of 1
+func normalizeEndpoints(endpoints []database.TargetEndpoint) error {
+	seen := make(map[string]bool, len(endpoints))
+	for i := range endpoints {
+		e := &endpoints[i]
+		if e.URL == "" {
+			return fmt.Errorf("endpoints[%d]: url is required", i)
+		}
+		if seen[e.URL] {
+			return fmt.Errorf("endpoints[%d]: duplicate url %s", i, e.URL)
+		}
+		seen[e.URL] = true
+		if e.Weight < 0 {
+			return fmt.Errorf("endpoints[%d]: weight must not be negative", i)
+		}
+		if e.Weight == 0 {
+			e.Weight = 1
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/reflow/gateway/internal/database"
+)
+
+// HealthHandlers handles target health operations
+type HealthHandlers struct {
+	repo *database.Repository
+}
+
+// NewHealthHandlers creates new target health handlers
+func NewHealthHandlers(repo *database.Repository) *HealthHandlers {
+	return &HealthHandlers{repo: repo}
+}
+
+// TargetHealthSummary is the health of every target with counts per status
+type TargetHealthSummary struct {
+	Healthy   int                      `json:"healthy"`
+	Unhealthy int                      `json:"unhealthy"`
+	Unknown   int                      `json:"unknown"`
+	Targets   []*database.TargetHealth `json:"targets"`
+}
+
+// GetTargetHealth returns the latest health check result of a target
+func (h *HealthHandlers) GetTargetHealth(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid target ID")
+		return
+	}
+
+	health, err := h.repo.GetTargetHealth(r.Context(), id)
+	if err != nil {
+		if err == database.ErrNotFound {
+			writeError(w, http.StatusNotFound, "Target not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to get target health")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, health)
+}
+
+// ListTargetHealth returns the health of every target. Disabled targets are
+// listed but not counted.
+func (h *HealthHandlers) ListTargetHealth(w http.ResponseWriter, r *http.Request) {
+	results, err := h.repo.ListTargetHealth(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to get target health")
+		return
+	}
+
+	summary := TargetHealthSummary{Targets: results}
+	if summary.Targets == nil {
+		summary.Targets = []*database.TargetHealth{}
+	}
+	for _, health := range results {
+		if !health.Enabled {
+			continue
+		}
+		switch health.Status {
+		case database.TargetHealthy:
+			summary.Healthy++
+		case database.TargetUnhealthy:
+			summary.Unhealthy++
+		default:
+			summary.Unknown++
+		}
+	}
+
+	writeJSON(w, http.StatusOK, summary)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/reflow/gateway/internal/auth"
+	"github.com/reflow/gateway/internal/condition"
 	"github.com/reflow/gateway/internal/database"
 )
 
@@ -59,13 +60,19 @@ func (h *PolicyHandlers) CreatePolicy(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "Name is required")
 		return
 	}
-	if req.Effect != "allow" && req.Effect != "deny" {
-		writeError(w, http.StatusBadRequest, "Effect must be 'allow' or 'deny'")
-		return
-	}
 	if req.ResourceType == "" {
 		req.ResourceType = "all"
 	}
+	if msg := validatePolicyEffect(req.Effect, req.ResourceType); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	if req.Condition != nil && *req.Condition != "" {
+		if _, err := condition.Compile(*req.Condition); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid condition: "+err.Error())
+			return
+		}
+	}
 
 	policy, err := h.repo.CreatePolicy(r.Context(), &req)
 	if err != nil {
@@ -119,6 +126,34 @@ func (h *PolicyHandlers) UpdatePolicy(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
+	if req.Condition != nil && *req.Condition != "" {
+		if _, err := condition.Compile(*req.Condition); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid condition: "+err.Error())
+			return
+		}
+	}
+	if req.Effect != nil || req.ResourceType != nil {
+		existing, err := h.repo.GetPolicyByID(r.Context(), id)
+		if err != nil {
+			if err == database.ErrNotFound {
+				writeError(w, http.StatusNotFound, "Policy not found")
+				return
+			}
+			writeError(w, http.StatusInternalServerError, "Failed to get policy")
+			return
+		}
+		effect, resourceType := existing.Effect, existing.ResourceType
+		if req.Effect != nil {
+			effect = *req.Effect
+		}
+		if req.ResourceType != nil {
+			resourceType = *req.ResourceType
+		}
+		if msg := validatePolicyEffect(effect, resourceType); msg != "" {
+			writeError(w, http.StatusBadRequest, msg)
+			return
+		}
+	}
 
 	policy, err := h.repo.UpdatePolicy(r.Context(), id, &req)
 	if err != nil {
@@ -161,6 +196,50 @@ func (h *PolicyHandlers) DeletePolicy(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// validatePolicyEffect checks a policy's effect against its resource type and
+// returns an error message, or "" if they are valid. Only tool calls can wait
+// for approval.
+func validatePolicyEffect(effect, resourceType string) string {
+	switch effect {
+	case database.EffectAllow, database.EffectDeny:
+		return ""
+	case database.EffectRequireApproval:
+		if resourceType != "tool" {
+			return "Effect 'require_approval' requires resource_type 'tool'"
+		}
+		return ""
+	}
+	return "Effect must be 'allow', 'deny' or 'require_approval'"
+}
+
+// ValidateCondition compiles a policy condition and, given sample input,
+// evaluates it. Invalid conditions are reported in the result, not as an error.
+func (h *PolicyHandlers) ValidateCondition(w http.ResponseWriter, r *http.Request) {
+	var req database.ValidateConditionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	expr, err := condition.Compile(req.Condition)
+	if err != nil {
+		writeJSON(w, http.StatusOK, database.ValidateConditionResult{Valid: false, Error: err.Error()})
+		return
+	}
+
+	result := database.ValidateConditionResult{Valid: true}
+	if req.Input != nil {
+		holds, err := expr.Eval(req.Input)
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Result = &holds
+		}
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
 // AddPolicySubject adds a subject to a policy
 func (h *PolicyHandlers) AddPolicySubject(w http.ResponseWriter, r *http.Request) {
 	// Check if user is admin
@@ -0,0 +1,263 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/reflow/gateway/internal/auth"
+	"github.com/reflow/gateway/internal/database"
+)
+
+// RateLimitHandlers handles rate limit operations
+type RateLimitHandlers struct {
+	repo *database.Repository
+}
+
+// NewRateLimitHandlers creates new rate limit handlers
+func NewRateLimitHandlers(repo *database.Repository) *RateLimitHandlers {
+	return &RateLimitHandlers{repo: repo}
+}
+
+// ListRateLimits returns all rate limits
+func (h *RateLimitHandlers) ListRateLimits(w http.ResponseWriter, r *http.Request) {
+	limits, err := h.repo.ListRateLimits(r.Context(), false)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to get rate limits")
+		return
+	}
+
+	if limits == nil {
+		limits = []*database.RateLimit{}
+	}
+
+	writeJSON(w, http.StatusOK, limits)
+}
+
+// CreateRateLimit creates a new rate limit
+func (h *RateLimitHandlers) CreateRateLimit(w http.ResponseWriter, r *http.Request) {
+	// Check if user is admin
+	role, _ := auth.GetUserRole(r.Context())
+	if role != "admin" {
+		writeError(w, http.StatusForbidden, "Admin access required")
+		return
+	}
+
+	var req database.CreateRateLimitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	limit := &database.RateLimit{
+		Name:          req.Name,
+		Description:   req.Description,
+		SubjectType:   req.SubjectType,
+		SubjectValue:  req.SubjectValue,
+		TargetID:      req.TargetID,
+		ToolPattern:   req.ToolPattern,
+		Requests:      req.Requests,
+		PeriodSeconds: req.PeriodSeconds,
+		Burst:         req.Burst,
+		PerUser:       true,
+		Enabled:       true,
+	}
+	if limit.SubjectType == "" {
+		limit.SubjectType = "everyone"
+	}
+	if limit.ToolPattern != nil && *limit.ToolPattern == "" {
+		limit.ToolPattern = nil
+	}
+	if limit.Burst == 0 {
+		limit.Burst = limit.Requests
+	}
+	if req.PerUser != nil {
+		limit.PerUser = *req.PerUser
+	}
+	if req.Enabled != nil {
+		limit.Enabled = *req.Enabled
+	}
+
+	if !h.validateRateLimit(w, r, limit) {
+		return
+	}
+
+	if err := h.repo.CreateRateLimit(r.Context(), limit); err != nil {
+		if err == database.ErrAlreadyExists {
+			writeError(w, http.StatusConflict, "Rate limit name already exists")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to create rate limit")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, limit)
+}
+
+// GetRateLimit returns a specific rate limit
+func (h *RateLimitHandlers) GetRateLimit(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid rate limit ID")
+		return
+	}
+
+	limit, err := h.repo.GetRateLimitByID(r.Context(), id)
+	if err != nil {
+		if err == database.ErrNotFound {
+			writeError(w, http.StatusNotFound, "Rate limit not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to get rate limit")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, limit)
+}
+
+// UpdateRateLimit updates an existing rate limit
+func (h *RateLimitHandlers) UpdateRateLimit(w http.ResponseWriter, r *http.Request) {
+	// Check if user is admin
+	role, _ := auth.GetUserRole(r.Context())
+	if role != "admin" {
+		writeError(w, http.StatusForbidden, "Admin access required")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid rate limit ID")
+		return
+	}
+
+	var req database.UpdateRateLimitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	limit, err := h.repo.GetRateLimitByID(r.Context(), id)
+	if err != nil {
+		if err == database.ErrNotFound {
+			writeError(w, http.StatusNotFound, "Rate limit not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to get rate limit")
+		return
+	}
+
+	if req.Name != nil {
+		limit.Name = *req.Name
+	}
+	if req.Description != nil {
+		limit.Description = *req.Description
+	}
+	if req.SubjectType != nil {
+		limit.SubjectType = *req.SubjectType
+		if limit.SubjectType == "everyone" {
+			limit.SubjectValue = nil
+		}
+	}
+	if req.SubjectValue != nil {
+		limit.SubjectValue = req.SubjectValue
+		if *req.SubjectValue == "" {
+			limit.SubjectValue = nil
+		}
+	}
+	if req.TargetID != nil {
+		limit.TargetID = nil
+		if *req.TargetID != "" {
+			targetID, err := uuid.Parse(*req.TargetID)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "Invalid target ID")
+				return
+			}
+			limit.TargetID = &targetID
+		}
+	}
+	if req.ToolPattern != nil {
+		limit.ToolPattern = req.ToolPattern
+		if *req.ToolPattern == "" {
+			limit.ToolPattern = nil
+		}
+	}
+	if req.Requests != nil {
+		limit.Requests = *req.Requests
+	}
+	if req.PeriodSeconds != nil {
+		limit.PeriodSeconds = *req.PeriodSeconds
+	}
+	if req.Burst != nil {
+		limit.Burst = *req.Burst
+	}
+	if req.PerUser != nil {
+		limit.PerUser = *req.PerUser
+	}
+	if req.Enabled != nil {
+		limit.Enabled = *req.Enabled
+	}
+
+	if !h.validateRateLimit(w, r, limit) {
+		return
+	}
+
+	if err := h.repo.UpdateRateLimit(r.Context(), limit); err != nil {
+		if err == database.ErrAlreadyExists {
+			writeError(w, http.StatusConflict, "Rate limit name already exists")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to update rate limit")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, limit)
+}
+
+// DeleteRateLimit deletes a rate limit and its buckets
+func (h *RateLimitHandlers) DeleteRateLimit(w http.ResponseWriter, r *http.Request) {
+	// Check if user is admin
+	role, _ := auth.GetUserRole(r.Context())
+	if role != "admin" {
+		writeError(w, http.StatusForbidden, "Admin access required")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid rate limit ID")
+		return
+	}
+
+	if err := h.repo.DeleteRateLimit(r.Context(), id); err != nil {
+		if err == database.ErrNotFound {
+			writeError(w, http.StatusNotFound, "Rate limit not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to delete rate limit")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validateRateLimit checks the rate limit and that its target exists, writing
+// the error response if not
+func (h *RateLimitHandlers) validateRateLimit(w http.ResponseWriter, r *http.Request, limit *database.RateLimit) bool {
+	if err := database.ValidateRateLimit(limit); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid rate limit: "+err.Error())
+		return false
+	}
+
+	if limit.TargetID != nil {
+		if _, err := h.repo.GetTargetByID(r.Context(), *limit.TargetID); err != nil {
+			if err == database.ErrNotFound {
+				writeError(w, http.StatusBadRequest, "Target not found")
+				return false
+			}
+			writeError(w, http.StatusInternalServerError, "Failed to get target")
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,248 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/reflow/gateway/internal/auth"
+	"github.com/reflow/gateway/internal/database"
+	"github.com/reflow/gateway/internal/redaction"
+)
+
+// RedactionHandlers handles redaction rule operations
+type RedactionHandlers struct {
+	repo *database.Repository
+}
+
+// NewRedactionHandlers creates new redaction handlers
+func NewRedactionHandlers(repo *database.Repository) *RedactionHandlers {
+	return &RedactionHandlers{repo: repo}
+}
+
+// ListDetectors returns the built-in detectors rules can use besides "regex"
+func (h *RedactionHandlers) ListDetectors(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, redaction.Detectors())
+}
+
+// ListRedactionRules returns all redaction rules
+func (h *RedactionHandlers) ListRedactionRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.repo.ListRedactionRules(r.Context(), false)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to get redaction rules")
+		return
+	}
+
+	if rules == nil {
+		rules = []*database.RedactionRule{}
+	}
+
+	writeJSON(w, http.StatusOK, rules)
+}
+
+// CreateRedactionRule creates a new redaction rule
+func (h *RedactionHandlers) CreateRedactionRule(w http.ResponseWriter, r *http.Request) {
+	// Check if user is admin
+	role, _ := auth.GetUserRole(r.Context())
+	if role != "admin" {
+		writeError(w, http.StatusForbidden, "Admin access required")
+		return
+	}
+
+	var req database.CreateRedactionRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	rule := &database.RedactionRule{
+		Name:        req.Name,
+		Description: req.Description,
+		Detector:    req.Detector,
+		Pattern:     req.Pattern,
+		Action:      req.Action,
+		TargetID:    req.TargetID,
+		Role:        req.Role,
+		Enabled:     true,
+	}
+	if rule.Action == "" {
+		rule.Action = redaction.ActionMask
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+
+	if !h.validateRedactionRule(w, r, rule) {
+		return
+	}
+
+	if err := h.repo.CreateRedactionRule(r.Context(), rule); err != nil {
+		if err == database.ErrAlreadyExists {
+			writeError(w, http.StatusConflict, "Redaction rule name already exists")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to create redaction rule")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, rule)
+}
+
+// GetRedactionRule returns a specific redaction rule
+func (h *RedactionHandlers) GetRedactionRule(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid redaction rule ID")
+		return
+	}
+
+	rule, err := h.repo.GetRedactionRuleByID(r.Context(), id)
+	if err != nil {
+		if err == database.ErrNotFound {
+			writeError(w, http.StatusNotFound, "Redaction rule not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to get redaction rule")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, rule)
+}
+
+// UpdateRedactionRule updates an existing redaction rule
+func (h *RedactionHandlers) UpdateRedactionRule(w http.ResponseWriter, r *http.Request) {
+	// Check if user is admin
+	role, _ := auth.GetUserRole(r.Context())
+	if role != "admin" {
+		writeError(w, http.StatusForbidden, "Admin access required")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid redaction rule ID")
+		return
+	}
+
+	var req database.UpdateRedactionRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	rule, err := h.repo.GetRedactionRuleByID(r.Context(), id)
+	if err != nil {
+		if err == database.ErrNotFound {
+			writeError(w, http.StatusNotFound, "Redaction rule not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to get redaction rule")
+		return
+	}
+
+	if req.Name != nil {
+		rule.Name = *req.Name
+	}
+	if req.Description != nil {
+		rule.Description = *req.Description
+	}
+	if req.Detector != nil {
+		rule.Detector = *req.Detector
+		if rule.Detector != redaction.DetectorRegex {
+			rule.Pattern = nil
+		}
+	}
+	if req.Pattern != nil {
+		rule.Pattern = req.Pattern
+		if *req.Pattern == "" {
+			rule.Pattern = nil
+		}
+	}
+	if req.Action != nil {
+		rule.Action = *req.Action
+	}
+	if req.TargetID != nil {
+		rule.TargetID = nil
+		if *req.TargetID != "" {
+			targetID, err := uuid.Parse(*req.TargetID)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "Invalid target ID")
+				return
+			}
+			rule.TargetID = &targetID
+		}
+	}
+	if req.Role != nil {
+		rule.Role = req.Role
+		if *req.Role == "" {
+			rule.Role = nil
+		}
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+
+	if !h.validateRedactionRule(w, r, rule) {
+		return
+	}
+
+	if err := h.repo.UpdateRedactionRule(r.Context(), rule); err != nil {
+		if err == database.ErrAlreadyExists {
+			writeError(w, http.StatusConflict, "Redaction rule name already exists")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to update redaction rule")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, rule)
+}
+
+// DeleteRedactionRule deletes a redaction rule
+func (h *RedactionHandlers) DeleteRedactionRule(w http.ResponseWriter, r *http.Request) {
+	// Check if user is admin
+	role, _ := auth.GetUserRole(r.Context())
+	if role != "admin" {
+		writeError(w, http.StatusForbidden, "Admin access required")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid redaction rule ID")
+		return
+	}
+
+	if err := h.repo.DeleteRedactionRule(r.Context(), id); err != nil {
+		if err == database.ErrNotFound {
+			writeError(w, http.StatusNotFound, "Redaction rule not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to delete redaction rule")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validateRedactionRule checks the rule and that its target exists, writing
+// the error response if not
+func (h *RedactionHandlers) validateRedactionRule(w http.ResponseWriter, r *http.Request, rule *database.RedactionRule) bool {
+	if err := database.ValidateRedactionRule(rule); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid redaction rule: "+err.Error())
+		return false
+	}
+
+	if rule.TargetID != nil {
+		if _, err := h.repo.GetTargetByID(r.Context(), *rule.TargetID); err != nil {
+			if err == database.ErrNotFound {
+				writeError(w, http.StatusBadRequest, "Target not found")
+				return false
+			}
+			writeError(w, http.StatusInternalServerError, "Failed to get target")
+			return false
+		}
+	}
+	return true
+}
@@ -7,12 +7,18 @@ import (
 )
 
 // Router creates and configures the API router
-func Router(repo *database.Repository, jwtManager *auth.JWTManager, encryptor *auth.TokenEncryptor, authMiddleware *auth.Middleware, sessionRecycler SessionRecycler, instanceRestarter InstanceRestarter) chi.Router {
+func Router(repo *database.Repository, jwtManager *auth.JWTManager, encryptor *auth.TokenEncryptor, authMiddleware *auth.Middleware, sessionRecycler SessionRecycler, instanceRestarter InstanceRestarter, toolDelimiter string) chi.Router {
 	r := chi.NewRouter()
 
 	h := NewHandlers(repo, jwtManager, encryptor, sessionRecycler, instanceRestarter)
 	policyHandlers := NewPolicyHandlers(repo, encryptor)
 	envHandlers := NewEnvHandlers(repo, encryptor, instanceRestarter)
+	toolHandlers := NewToolHandlers(repo, toolDelimiter)
+	redactionHandlers := NewRedactionHandlers(repo)
+	approvalHandlers := NewApprovalHandlers(repo)
+	rateLimitHandlers := NewRateLimitHandlers(repo)
+	usageHandlers := NewUsageHandlers(repo)
+	healthHandlers := NewHealthHandlers(repo)
 
 	// Public routes (no auth required)
 	r.Group(func(r chi.Router) {
@@ -45,6 +51,10 @@ func Router(repo *database.Repository, jwtManager *auth.JWTManager, encryptor *a
 		r.Put("/targets/{id}", h.UpdateTarget)
 		r.Delete("/targets/{id}", h.DeleteTarget)
 		r.Post("/targets/{id}/restart-instances", h.RestartInstances)
+		r.Get("/targets/{id}/health", healthHandlers.GetTargetHealth)
+
+		// Target health summary
+		r.Get("/health/targets", healthHandlers.ListTargetHealth)
 
 		// Target token configuration (view all tokens for a target)
 		r.Get("/targets/{id}/tokens", h.GetTargetTokenConfig)
@@ -72,6 +82,7 @@ func Router(repo *database.Repository, jwtManager *auth.JWTManager, encryptor *a
 		// Authorization policies routes
 		r.Get("/policies", policyHandlers.ListPolicies)
 		r.Post("/policies", policyHandlers.CreatePolicy)
+		r.Post("/policies/validate-condition", policyHandlers.ValidateCondition)
 		r.Get("/policies/{id}", policyHandlers.GetPolicy)
 		r.Put("/policies/{id}", policyHandlers.UpdatePolicy)
 		r.Delete("/policies/{id}", policyHandlers.DeletePolicy)
@@ -105,6 +116,52 @@ func Router(repo *database.Repository, jwtManager *auth.JWTManager, encryptor *a
 		r.Put("/targets/{id}/env/user/{scopeValue}", envHandlers.BulkSetEnvConfigs)
 		r.Post("/targets/{id}/env/user/{scopeValue}", envHandlers.SetEnvConfig)
 		r.Delete("/targets/{id}/env/user/{scopeValue}/{key}", envHandlers.DeleteEnvConfig)
+
+		// Tool override routes (writes admin only)
+		r.Get("/targets/{id}/tools", toolHandlers.ListToolOverrides)
+		r.Get("/targets/{id}/tools/{tool}", toolHandlers.GetToolOverride)
+		r.Put("/targets/{id}/tools/{tool}", toolHandlers.SetToolOverride)
+		r.Delete("/targets/{id}/tools/{tool}", toolHandlers.DeleteToolOverride)
+
+		// Virtual tool routes (writes admin only)
+		r.Get("/virtual-tools", toolHandlers.ListVirtualTools)
+		r.Post("/virtual-tools", toolHandlers.CreateVirtualTool)
+		r.Get("/virtual-tools/{id}", toolHandlers.GetVirtualTool)
+		r.Put("/virtual-tools/{id}", toolHandlers.UpdateVirtualTool)
+		r.Delete("/virtual-tools/{id}", toolHandlers.DeleteVirtualTool)
+
+		// Redaction rule routes (writes admin only)
+		r.Get("/redaction-rules", redactionHandlers.ListRedactionRules)
+		r.Post("/redaction-rules", redactionHandlers.CreateRedactionRule)
+		r.Get("/redaction-rules/detectors", redactionHandlers.ListDetectors)
+		r.Get("/redaction-rules/{id}", redactionHandlers.GetRedactionRule)
+		r.Put("/redaction-rules/{id}", redactionHandlers.UpdateRedactionRule)
+		r.Delete("/redaction-rules/{id}", redactionHandlers.DeleteRedactionRule)
+
+		// Rate limit routes (writes admin only)
+		r.Get("/rate-limits", rateLimitHandlers.ListRateLimits)
+		r.Post("/rate-limits", rateLimitHandlers.CreateRateLimit)
+		r.Get("/rate-limits/{id}", rateLimitHandlers.GetRateLimit)
+		r.Put("/rate-limits/{id}", rateLimitHandlers.UpdateRateLimit)
+		r.Delete("/rate-limits/{id}", rateLimitHandlers.DeleteRateLimit)
+
+		// Usage routes (quota and cost weight writes admin only)
+		r.Get("/usage", usageHandlers.GetUsageReport)
+		r.Get("/usage/quotas", usageHandlers.ListUsageQuotas)
+		r.Post("/usage/quotas", usageHandlers.CreateUsageQuota)
+		r.Get("/usage/quotas/{id}", usageHandlers.GetUsageQuota)
+		r.Put("/usage/quotas/{id}", usageHandlers.UpdateUsageQuota)
+		r.Delete("/usage/quotas/{id}", usageHandlers.DeleteUsageQuota)
+		r.Get("/usage/cost-weights", usageHandlers.ListCostWeights)
+		r.Post("/usage/cost-weights", usageHandlers.CreateCostWeight)
+		r.Put("/usage/cost-weights/{id}", usageHandlers.UpdateCostWeight)
+		r.Delete("/usage/cost-weights/{id}", usageHandlers.DeleteCostWeight)
+
+		// Approval routes (deciding needs the approval's approver role)
+		r.Get("/approvals", approvalHandlers.ListApprovals)
+		r.Get("/approvals/{id}", approvalHandlers.GetApproval)
+		r.Post("/approvals/{id}/approve", approvalHandlers.ApproveApproval)
+		r.Post("/approvals/{id}/reject", approvalHandlers.RejectApproval)
 	})
 
 	return r
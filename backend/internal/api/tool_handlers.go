@@ -0,0 +1,145 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/reflow/gateway/internal/auth"
+	"github.com/reflow/gateway/internal/database"
+)
+
+// ToolHandlers handles admin-defined tool overrides and virtual tools
+type ToolHandlers struct {
+	repo          *database.Repository
+	toolDelimiter string // separates a target's namespace from its tool names
+}
+
+// NewToolHandlers creates new tool handlers
+func NewToolHandlers(repo *database.Repository, toolDelimiter string) *ToolHandlers {
+	return &ToolHandlers{repo: repo, toolDelimiter: toolDelimiter}
+}
+
+// ListToolOverrides returns all tool overrides for a target
+func (h *ToolHandlers) ListToolOverrides(w http.ResponseWriter, r *http.Request) {
+	targetID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid target ID")
+		return
+	}
+
+	overrides, err := h.repo.GetToolOverridesForTarget(r.Context(), targetID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to get tool overrides")
+		return
+	}
+
+	if overrides == nil {
+		overrides = []*database.ToolOverride{}
+	}
+
+	writeJSON(w, http.StatusOK, overrides)
+}
+
+// GetToolOverride returns the override for one upstream tool
+func (h *ToolHandlers) GetToolOverride(w http.ResponseWriter, r *http.Request) {
+	targetID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid target ID")
+		return
+	}
+
+	override, err := h.repo.GetToolOverride(r.Context(), targetID, chi.URLParam(r, "tool"))
+	if err != nil {
+		if err == database.ErrNotFound {
+			writeError(w, http.StatusNotFound, "Tool override not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to get tool override")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, override)
+}
+
+// SetToolOverride creates or replaces the override for one upstream tool.
+// Sessions pick up the change on their next tools/list.
+func (h *ToolHandlers) SetToolOverride(w http.ResponseWriter, r *http.Request) {
+	// Check if user is admin
+	role, _ := auth.GetUserRole(r.Context())
+	if role != "admin" {
+		writeError(w, http.StatusForbidden, "Admin access required")
+		return
+	}
+
+	targetID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid target ID")
+		return
+	}
+
+	toolName := chi.URLParam(r, "tool")
+	if err := database.ValidateToolName(toolName); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid tool name: "+err.Error())
+		return
+	}
+
+	var req database.SetToolOverrideRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if err := database.ValidateToolOverride(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid tool override: "+err.Error())
+		return
+	}
+
+	if _, err := h.repo.GetTargetByID(r.Context(), targetID); err != nil {
+		if err == database.ErrNotFound {
+			writeError(w, http.StatusNotFound, "Target not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to get target")
+		return
+	}
+
+	override, err := h.repo.SetToolOverride(r.Context(), targetID, toolName, &req)
+	if err != nil {
+		if err == database.ErrAlreadyExists {
+			writeError(w, http.StatusConflict, "Another tool of this target is already renamed to that name")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to set tool override")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, override)
+}
+
+// DeleteToolOverride removes the override for one upstream tool
+func (h *ToolHandlers) DeleteToolOverride(w http.ResponseWriter, r *http.Request) {
+	// Check if user is admin
+	role, _ := auth.GetUserRole(r.Context())
+	if role != "admin" {
+		writeError(w, http.StatusForbidden, "Admin access required")
+		return
+	}
+
+	targetID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid target ID")
+		return
+	}
+
+	if err := h.repo.DeleteToolOverride(r.Context(), targetID, chi.URLParam(r, "tool")); err != nil {
+		if err == database.ErrNotFound {
+			writeError(w, http.StatusNotFound, "Tool override not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to delete tool override")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
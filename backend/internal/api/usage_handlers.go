@@ -0,0 +1,495 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/reflow/gateway/internal/auth"
+	"github.com/reflow/gateway/internal/database"
+)
+
+// UsageHandlers handles usage reports, usage quotas and cost weights
+type UsageHandlers struct {
+	repo *database.Repository
+}
+
+// NewUsageHandlers creates new usage handlers
+func NewUsageHandlers(repo *database.Repository) *UsageHandlers {
+	return &UsageHandlers{repo: repo}
+}
+
+// GetUsageReport totals metered calls by ?group_by= (default "target") between
+// ?from= and ?to= (RFC 3339 or YYYY-MM-DD, default the current month).
+// Admins may filter by ?user_id=, ?role=, ?group= and ?target_id=; other users
+// only see their own usage.
+func (h *UsageHandlers) GetUsageReport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	role, _ := auth.GetUserRole(r.Context())
+	query := r.URL.Query()
+
+	groupBy := query.Get("group_by")
+	if groupBy == "" {
+		groupBy = "target"
+	}
+	if !database.ValidUsageGroupBy(groupBy) {
+		writeError(w, http.StatusBadRequest, "Invalid group_by")
+		return
+	}
+
+	now := time.Now().UTC()
+	filter := database.UsageFilter{
+		From: time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC),
+	}
+	if from := query.Get("from"); from != "" {
+		parsed, ok := parseUsageTime(from)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "Invalid from")
+			return
+		}
+		filter.From = parsed
+	}
+	if to := query.Get("to"); to != "" {
+		parsed, ok := parseUsageTime(to)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "Invalid to")
+			return
+		}
+		filter.To = parsed
+	}
+
+	if role == "admin" {
+		if v := query.Get("user_id"); v != "" {
+			id, err := uuid.Parse(v)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "Invalid user ID")
+				return
+			}
+			filter.UserID = &id
+		}
+		if v := query.Get("target_id"); v != "" {
+			id, err := uuid.Parse(v)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "Invalid target ID")
+				return
+			}
+			filter.TargetID = &id
+		}
+		if v := query.Get("role"); v != "" {
+			filter.Role = &v
+		}
+		if v := query.Get("group"); v != "" {
+			filter.Group = &v
+		}
+	} else {
+		filter.UserID = &userID
+	}
+
+	report, err := h.repo.GetUsageReport(r.Context(), filter, groupBy)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to get usage")
+		return
+	}
+
+	if report == nil {
+		report = []*database.UsageReportRow{}
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+// parseUsageTime parses a report bound given as RFC 3339 or as a UTC date
+func parseUsageTime(value string) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, true
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// ==================== Usage Quotas ====================
+
+// ListUsageQuotas returns all usage quotas
+func (h *UsageHandlers) ListUsageQuotas(w http.ResponseWriter, r *http.Request) {
+	quotas, err := h.repo.ListUsageQuotas(r.Context(), false)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to get usage quotas")
+		return
+	}
+
+	if quotas == nil {
+		quotas = []*database.UsageQuota{}
+	}
+
+	writeJSON(w, http.StatusOK, quotas)
+}
+
+// CreateUsageQuota creates a new usage quota
+func (h *UsageHandlers) CreateUsageQuota(w http.ResponseWriter, r *http.Request) {
+	// Check if user is admin
+	role, _ := auth.GetUserRole(r.Context())
+	if role != "admin" {
+		writeError(w, http.StatusForbidden, "Admin access required")
+		return
+	}
+
+	var req database.CreateUsageQuotaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	quota := &database.UsageQuota{
+		Name:           req.Name,
+		Description:    req.Description,
+		SubjectType:    req.SubjectType,
+		SubjectValue:   req.SubjectValue,
+		TargetID:       req.TargetID,
+		Period:         req.Period,
+		MaxCost:        req.MaxCost,
+		WarnThresholds: req.WarnThresholds,
+		PerUser:        true,
+		Enabled:        true,
+	}
+	if quota.SubjectType == "" {
+		quota.SubjectType = "everyone"
+	}
+	if quota.WarnThresholds == nil {
+		quota.WarnThresholds = []int{80}
+	}
+	if req.PerUser != nil {
+		quota.PerUser = *req.PerUser
+	}
+	if req.Enabled != nil {
+		quota.Enabled = *req.Enabled
+	}
+
+	if !h.validateUsageQuota(w, r, quota) {
+		return
+	}
+
+	if err := h.repo.CreateUsageQuota(r.Context(), quota); err != nil {
+		if err == database.ErrAlreadyExists {
+			writeError(w, http.StatusConflict, "Usage quota name already exists")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to create usage quota")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, quota)
+}
+
+// GetUsageQuota returns a specific usage quota
+func (h *UsageHandlers) GetUsageQuota(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid usage quota ID")
+		return
+	}
+
+	quota, err := h.repo.GetUsageQuotaByID(r.Context(), id)
+	if err != nil {
+		if err == database.ErrNotFound {
+			writeError(w, http.StatusNotFound, "Usage quota not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to get usage quota")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, quota)
+}
+
+// UpdateUsageQuota updates an existing usage quota
+func (h *UsageHandlers) UpdateUsageQuota(w http.ResponseWriter, r *http.Request) {
+	// Check if user is admin
+	role, _ := auth.GetUserRole(r.Context())
+	if role != "admin" {
+		writeError(w, http.StatusForbidden, "Admin access required")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid usage quota ID")
+		return
+	}
+
+	var req database.UpdateUsageQuotaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	quota, err := h.repo.GetUsageQuotaByID(r.Context(), id)
+	if err != nil {
+		if err == database.ErrNotFound {
+			writeError(w, http.StatusNotFound, "Usage quota not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to get usage quota")
+		return
+	}
+
+	if req.Name != nil {
+		quota.Name = *req.Name
+	}
+	if req.Description != nil {
+		quota.Description = *req.Description
+	}
+	if req.SubjectType != nil {
+		quota.SubjectType = *req.SubjectType
+		if quota.SubjectType == "everyone" {
+			quota.SubjectValue = nil
+		}
+	}
+	if req.SubjectValue != nil {
+		quota.SubjectValue = req.SubjectValue
+		if *req.SubjectValue == "" {
+			quota.SubjectValue = nil
+		}
+	}
+	if req.TargetID != nil {
+		quota.TargetID = nil
+		if *req.TargetID != "" {
+			targetID, err := uuid.Parse(*req.TargetID)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "Invalid target ID")
+				return
+			}
+			quota.TargetID = &targetID
+		}
+	}
+	if req.Period != nil {
+		quota.Period = *req.Period
+	}
+	if req.MaxCost != nil {
+		quota.MaxCost = *req.MaxCost
+	}
+	if req.WarnThresholds != nil {
+		quota.WarnThresholds = *req.WarnThresholds
+	}
+	if req.PerUser != nil {
+		quota.PerUser = *req.PerUser
+	}
+	if req.Enabled != nil {
+		quota.Enabled = *req.Enabled
+	}
+
+	if !h.validateUsageQuota(w, r, quota) {
+		return
+	}
+
+	if err := h.repo.UpdateUsageQuota(r.Context(), quota); err != nil {
+		if err == database.ErrAlreadyExists {
+			writeError(w, http.StatusConflict, "Usage quota name already exists")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to update usage quota")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, quota)
+}
+
+// DeleteUsageQuota deletes a usage quota
+func (h *UsageHandlers) DeleteUsageQuota(w http.ResponseWriter, r *http.Request) {
+	// Check if user is admin
+	role, _ := auth.GetUserRole(r.Context())
+	if role != "admin" {
+		writeError(w, http.StatusForbidden, "Admin access required")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid usage quota ID")
+		return
+	}
+
+	if err := h.repo.DeleteUsageQuota(r.Context(), id); err != nil {
+		if err == database.ErrNotFound {
+			writeError(w, http.StatusNotFound, "Usage quota not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to delete usage quota")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validateUsageQuota checks the quota and that its target exists, writing the
+// error response if not
+func (h *UsageHandlers) validateUsageQuota(w http.ResponseWriter, r *http.Request, quota *database.UsageQuota) bool {
+	if err := database.ValidateUsageQuota(quota); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid usage quota: "+err.Error())
+		return false
+	}
+	if quota.TargetID != nil {
+		return h.checkTarget(w, r, *quota.TargetID)
+	}
+	return true
+}
+
+// ==================== Cost Weights ====================
+
+// ListCostWeights returns all cost weights
+func (h *UsageHandlers) ListCostWeights(w http.ResponseWriter, r *http.Request) {
+	weights, err := h.repo.ListCostWeights(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to get cost weights")
+		return
+	}
+
+	if weights == nil {
+		weights = []*database.CostWeight{}
+	}
+
+	writeJSON(w, http.StatusOK, weights)
+}
+
+// CreateCostWeight prices calls to a target, or to one of its tools
+func (h *UsageHandlers) CreateCostWeight(w http.ResponseWriter, r *http.Request) {
+	// Check if user is admin
+	role, _ := auth.GetUserRole(r.Context())
+	if role != "admin" {
+		writeError(w, http.StatusForbidden, "Admin access required")
+		return
+	}
+
+	var req database.CreateCostWeightRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	weight := &database.CostWeight{
+		TargetID: req.TargetID,
+		ToolName: req.ToolName,
+		Weight:   req.Weight,
+	}
+	if weight.ToolName != nil && *weight.ToolName == "" {
+		weight.ToolName = nil
+	}
+
+	if err := database.ValidateCostWeight(weight); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid cost weight: "+err.Error())
+		return
+	}
+	if !h.checkTarget(w, r, weight.TargetID) {
+		return
+	}
+
+	if err := h.repo.CreateCostWeight(r.Context(), weight); err != nil {
+		if err == database.ErrAlreadyExists {
+			writeError(w, http.StatusConflict, "Cost weight already exists for this target and tool")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to create cost weight")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, weight)
+}
+
+// UpdateCostWeight changes the weight of a cost weight
+func (h *UsageHandlers) UpdateCostWeight(w http.ResponseWriter, r *http.Request) {
+	// Check if user is admin
+	role, _ := auth.GetUserRole(r.Context())
+	if role != "admin" {
+		writeError(w, http.StatusForbidden, "Admin access required")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid cost weight ID")
+		return
+	}
+
+	var req database.UpdateCostWeightRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	weight, err := h.repo.GetCostWeightByID(r.Context(), id)
+	if err != nil {
+		if err == database.ErrNotFound {
+			writeError(w, http.StatusNotFound, "Cost weight not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to get cost weight")
+		return
+	}
+
+	if req.Weight != nil {
+		weight.Weight = *req.Weight
+	}
+
+	if err := database.ValidateCostWeight(weight); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid cost weight: "+err.Error())
+		return
+	}
+
+	if err := h.repo.UpdateCostWeight(r.Context(), weight); err != nil {
+		if err == database.ErrNotFound {
+			writeError(w, http.StatusNotFound, "Cost weight not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to update cost weight")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, weight)
+}
+
+// DeleteCostWeight deletes a cost weight; its calls go back to the default cost
+func (h *UsageHandlers) DeleteCostWeight(w http.ResponseWriter, r *http.Request) {
+	// Check if user is admin
+	role, _ := auth.GetUserRole(r.Context())
+	if role != "admin" {
+		writeError(w, http.StatusForbidden, "Admin access required")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid cost weight ID")
+		return
+	}
+
+	if err := h.repo.DeleteCostWeight(r.Context(), id); err != nil {
+		if err == database.ErrNotFound {
+			writeError(w, http.StatusNotFound, "Cost weight not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to delete cost weight")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// checkTarget checks that a target exists, writing the error response if not
+func (h *UsageHandlers) checkTarget(w http.ResponseWriter, r *http.Request, targetID uuid.UUID) bool {
+	if _, err := h.repo.GetTargetByID(r.Context(), targetID); err != nil {
+		if err == database.ErrNotFound {
+			writeError(w, http.StatusBadRequest, "Target not found")
+			return false
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to get target")
+		return false
+	}
+	return true
+}
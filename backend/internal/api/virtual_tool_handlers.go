@@ -0,0 +1,221 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/reflow/gateway/internal/auth"
+	"github.com/reflow/gateway/internal/database"
+)
+
+// defaultVirtualToolInputSchema accepts any arguments object
+var defaultVirtualToolInputSchema = json.RawMessage(`{"type":"object"}`)
+
+// checkVirtualToolNamespace rejects a virtual tool name that starts with a
+// target's namespace, where it would shadow that target's tool of the same
+// prefixed name. Writes the error response and returns false on conflict.
+func (h *ToolHandlers) checkVirtualToolNamespace(w http.ResponseWriter, r *http.Request, name string) bool {
+	namespace, _, found := strings.Cut(name, h.toolDelimiter)
+	if !found {
+		return true
+	}
+
+	targets, err := h.repo.GetAllTargets(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to get targets")
+		return false
+	}
+	for _, target := range targets {
+		if target.Namespace == namespace {
+			writeError(w, http.StatusConflict, "Virtual tool name collides with the tools of target "+target.Name)
+			return false
+		}
+	}
+	return true
+}
+
+// ListVirtualTools returns all virtual tools
+func (h *ToolHandlers) ListVirtualTools(w http.ResponseWriter, r *http.Request) {
+	tools, err := h.repo.ListVirtualTools(r.Context(), false)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to get virtual tools")
+		return
+	}
+
+	if tools == nil {
+		tools = []*database.VirtualTool{}
+	}
+
+	writeJSON(w, http.StatusOK, tools)
+}
+
+// CreateVirtualTool creates a new virtual tool
+func (h *ToolHandlers) CreateVirtualTool(w http.ResponseWriter, r *http.Request) {
+	// Check if user is admin
+	role, _ := auth.GetUserRole(r.Context())
+	if role != "admin" {
+		writeError(w, http.StatusForbidden, "Admin access required")
+		return
+	}
+
+	var req database.CreateVirtualToolRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	vt := &database.VirtualTool{
+		Name:        req.Name,
+		Description: req.Description,
+		InputSchema: req.InputSchema,
+		Steps:       req.Steps,
+		Output:      req.Output,
+		Enabled:     true,
+	}
+	if len(vt.InputSchema) == 0 {
+		vt.InputSchema = defaultVirtualToolInputSchema
+	}
+	if req.Enabled != nil {
+		vt.Enabled = *req.Enabled
+	}
+
+	if err := database.ValidateVirtualTool(vt); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid virtual tool: "+err.Error())
+		return
+	}
+	if !h.checkVirtualToolNamespace(w, r, vt.Name) {
+		return
+	}
+
+	if err := h.repo.CreateVirtualTool(r.Context(), vt); err != nil {
+		if err == database.ErrAlreadyExists {
+			writeError(w, http.StatusConflict, "Virtual tool name already exists")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to create virtual tool")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, vt)
+}
+
+// GetVirtualTool returns a specific virtual tool
+func (h *ToolHandlers) GetVirtualTool(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid virtual tool ID")
+		return
+	}
+
+	vt, err := h.repo.GetVirtualToolByID(r.Context(), id)
+	if err != nil {
+		if err == database.ErrNotFound {
+			writeError(w, http.StatusNotFound, "Virtual tool not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to get virtual tool")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, vt)
+}
+
+// UpdateVirtualTool updates an existing virtual tool
+func (h *ToolHandlers) UpdateVirtualTool(w http.ResponseWriter, r *http.Request) {
+	// Check if user is admin
+	role, _ := auth.GetUserRole(r.Context())
+	if role != "admin" {
+		writeError(w, http.StatusForbidden, "Admin access required")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid virtual tool ID")
+		return
+	}
+
+	var req database.UpdateVirtualToolRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	vt, err := h.repo.GetVirtualToolByID(r.Context(), id)
+	if err != nil {
+		if err == database.ErrNotFound {
+			writeError(w, http.StatusNotFound, "Virtual tool not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to get virtual tool")
+		return
+	}
+
+	if req.Name != nil {
+		vt.Name = *req.Name
+	}
+	if req.Description != nil {
+		vt.Description = *req.Description
+	}
+	if req.InputSchema != nil {
+		vt.InputSchema = *req.InputSchema
+	}
+	if req.Steps != nil {
+		vt.Steps = *req.Steps
+	}
+	if req.Output != nil {
+		vt.Output = *req.Output
+	}
+	if req.Enabled != nil {
+		vt.Enabled = *req.Enabled
+	}
+
+	if err := database.ValidateVirtualTool(vt); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid virtual tool: "+err.Error())
+		return
+	}
+	if !h.checkVirtualToolNamespace(w, r, vt.Name) {
+		return
+	}
+
+	if err := h.repo.UpdateVirtualTool(r.Context(), vt); err != nil {
+		if err == database.ErrAlreadyExists {
+			writeError(w, http.StatusConflict, "Virtual tool name already exists")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to update virtual tool")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, vt)
+}
+
+// DeleteVirtualTool deletes a virtual tool
+func (h *ToolHandlers) DeleteVirtualTool(w http.ResponseWriter, r *http.Request) {
+	// Check if user is admin
+	role, _ := auth.GetUserRole(r.Context())
+	if role != "admin" {
+		writeError(w, http.StatusForbidden, "Admin access required")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid virtual tool ID")
+		return
+	}
+
+	if err := h.repo.DeleteVirtualTool(r.Context(), id); err != nil {
+		if err == database.ErrNotFound {
+			writeError(w, http.StatusNotFound, "Virtual tool not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to delete virtual tool")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,99 @@
+// Package condition compiles and evaluates the condition expressions attached
+// to authorization policies. An expression is evaluated over JSON-like
+// variables and must yield a boolean:
+//
+//	args.database == "staging" && user.role in ["developer", "admin"]
+//	startsWith(args.project, "ABC-") || request.hour < 18
+//
+// Operators are ==, !=, <, <=, >, >=, in, &&, || and !. Paths select object
+// fields with '.' and list elements or keys with [...]; a path that does not
+// exist evaluates to null. Functions: has(path), size(x), lower(s),
+// startsWith(s, prefix), endsWith(s, suffix), contains(s or list, x) and
+// matches(s, "regex").
+package condition
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Roots are the variables an expression may refer to
+var Roots = map[string]string{
+	"args":     "the tool call arguments (prompt arguments for prompts/get)",
+	"user":     "the caller: id, email, role, groups",
+	"target":   "the upstream target: id, name",
+	"resource": "what is being accessed: type, name",
+	"request":  "when the request was made (UTC): time (RFC 3339), hour, weekday",
+}
+
+// Expr is a compiled condition expression
+type Expr struct {
+	source string
+	root   node
+}
+
+// Compile parses an expression and checks its variables, functions and regular
+// expressions
+func Compile(source string) (*Expr, error) {
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, fmt.Errorf("position %d: unexpected %s", tok.pos+1, tok)
+	}
+	return &Expr{source: source, root: root}, nil
+}
+
+// String returns the expression's source
+func (e *Expr) String() string {
+	return e.source
+}
+
+// Eval evaluates the expression. Numbers in vars should be float64, as decoded
+// from JSON.
+func (e *Expr) Eval(vars map[string]interface{}) (bool, error) {
+	value, err := e.root.eval(vars)
+	if err != nil {
+		return false, err
+	}
+	result, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("condition must be a boolean, got %s", typeName(value))
+	}
+	return result, nil
+}
+
+// rootNames lists Roots for error messages
+func rootNames() string {
+	names := make([]string, 0, len(Roots))
+	for name := range Roots {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "bool"
+	case float64, int, int64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}, []string:
+		return "list"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,355 @@
+package condition
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+type node interface {
+	eval(vars map[string]interface{}) (interface{}, error)
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+func (n *literalNode) eval(map[string]interface{}) (interface{}, error) {
+	return n.value, nil
+}
+
+type listNode struct {
+	items []node
+}
+
+func (n *listNode) eval(vars map[string]interface{}) (interface{}, error) {
+	list := make([]interface{}, 0, len(n.items))
+	for _, item := range n.items {
+		value, err := item.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+	}
+	return list, nil
+}
+
+// pathNode selects a value below a root variable; each step is a field name
+// (string) or a list index (int)
+type pathNode struct {
+	root  string
+	steps []interface{}
+}
+
+func (n *pathNode) eval(vars map[string]interface{}) (interface{}, error) {
+	value, _ := n.lookup(vars)
+	return value, nil
+}
+
+func (n *pathNode) lookup(vars map[string]interface{}) (interface{}, bool) {
+	value, ok := vars[n.root]
+	for _, step := range n.steps {
+		if !ok {
+			return nil, false
+		}
+		switch key := step.(type) {
+		case string:
+			var m map[string]interface{}
+			if m, ok = value.(map[string]interface{}); ok {
+				value, ok = m[key]
+			}
+		case int:
+			list := toList(value)
+			if ok = key >= 0 && key < len(list); ok {
+				value = list[key]
+			}
+		}
+	}
+	if !ok {
+		return nil, false
+	}
+	return value, true
+}
+
+type hasNode struct {
+	path *pathNode
+}
+
+func (n *hasNode) eval(vars map[string]interface{}) (interface{}, error) {
+	_, ok := n.path.lookup(vars)
+	return ok, nil
+}
+
+type notNode struct {
+	operand node
+}
+
+func (n *notNode) eval(vars map[string]interface{}) (interface{}, error) {
+	value, err := evalBool(n.operand, vars, "!")
+	if err != nil {
+		return nil, err
+	}
+	return !value, nil
+}
+
+type logicalNode struct {
+	and         bool
+	left, right node
+}
+
+func (n *logicalNode) eval(vars map[string]interface{}) (interface{}, error) {
+	op := "||"
+	if n.and {
+		op = "&&"
+	}
+	left, err := evalBool(n.left, vars, op)
+	if err != nil {
+		return nil, err
+	}
+	if left != n.and {
+		return left, nil
+	}
+	return evalBool(n.right, vars, op)
+}
+
+func evalBool(n node, vars map[string]interface{}, op string) (bool, error) {
+	value, err := n.eval(vars)
+	if err != nil {
+		return false, err
+	}
+	b, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("%s expects a boolean, got %s", op, typeName(value))
+	}
+	return b, nil
+}
+
+type compareNode struct {
+	op          string
+	left, right node
+}
+
+func (n *compareNode) eval(vars map[string]interface{}) (interface{}, error) {
+	left, err := n.left.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "in":
+		return contains(right, left, "in")
+	}
+
+	if l, ok := toNumber(left); ok {
+		if r, ok := toNumber(right); ok {
+			return compareOrdered(n.op, l, r), nil
+		}
+	}
+	if l, ok := left.(string); ok {
+		if r, ok := right.(string); ok {
+			return compareOrdered(n.op, l, r), nil
+		}
+	}
+	return nil, fmt.Errorf("cannot compare %s %s %s", typeName(left), n.op, typeName(right))
+}
+
+func compareOrdered[T float64 | string](op string, l, r T) bool {
+	switch op {
+	case "<":
+		return l < r
+	case "<=":
+		return l <= r
+	case ">":
+		return l > r
+	}
+	return l >= r
+}
+
+type matchesNode struct {
+	operand node
+	re      *regexp.Regexp
+}
+
+func (n *matchesNode) eval(vars map[string]interface{}) (interface{}, error) {
+	value, err := n.operand.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := value.(string)
+	if !ok {
+		return false, nil
+	}
+	return n.re.MatchString(s), nil
+}
+
+type callNode struct {
+	name string
+	fn   func(args []interface{}) (interface{}, error)
+	args []node
+}
+
+func (n *callNode) eval(vars map[string]interface{}) (interface{}, error) {
+	args := make([]interface{}, len(n.args))
+	for i, arg := range n.args {
+		value, err := arg.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+	result, err := n.fn(args)
+	if err != nil {
+		return nil, fmt.Errorf("%s(): %w", n.name, err)
+	}
+	return result, nil
+}
+
+type function struct {
+	arity int
+	call  func(args []interface{}) (interface{}, error)
+}
+
+// functions besides has() and matches(), which the parser handles itself
+var functions = map[string]function{
+	"size": {1, func(args []interface{}) (interface{}, error) {
+		switch v := args[0].(type) {
+		case string:
+			return float64(utf8.RuneCountInString(v)), nil
+		case map[string]interface{}:
+			return float64(len(v)), nil
+		case nil:
+			return float64(0), nil
+		}
+		if list := toList(args[0]); list != nil {
+			return float64(len(list)), nil
+		}
+		return nil, fmt.Errorf("expected a string, list or object, got %s", typeName(args[0]))
+	}},
+	"lower": {1, func(args []interface{}) (interface{}, error) {
+		s, err := stringArg(args[0])
+		return strings.ToLower(s), err
+	}},
+	"startsWith": {2, func(args []interface{}) (interface{}, error) {
+		return stringPredicate(args, strings.HasPrefix)
+	}},
+	"endsWith": {2, func(args []interface{}) (interface{}, error) {
+		return stringPredicate(args, strings.HasSuffix)
+	}},
+	"contains": {2, func(args []interface{}) (interface{}, error) {
+		return contains(args[0], args[1], "contains")
+	}},
+}
+
+// stringPredicate applies f to two string arguments; a null subject is false
+func stringPredicate(args []interface{}, f func(s, x string) bool) (interface{}, error) {
+	if args[0] == nil {
+		return false, nil
+	}
+	s, err := stringArg(args[0])
+	if err != nil {
+		return nil, err
+	}
+	x, err := stringArg(args[1])
+	if err != nil {
+		return nil, err
+	}
+	return f(s, x), nil
+}
+
+func stringArg(v interface{}) (string, error) {
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("expected a string, got %s", typeName(v))
+	}
+	return s, nil
+}
+
+// contains reports whether container (a list, object or string) holds item
+func contains(container, item interface{}, op string) (interface{}, error) {
+	switch c := container.(type) {
+	case nil:
+		return false, nil
+	case string:
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s: cannot look for %s in a string", op, typeName(item))
+		}
+		return strings.Contains(c, s), nil
+	case map[string]interface{}:
+		key, ok := item.(string)
+		if !ok {
+			return false, nil
+		}
+		_, found := c[key]
+		return found, nil
+	}
+	list := toList(container)
+	if list == nil {
+		return nil, fmt.Errorf("%s expects a list, object or string, got %s", op, typeName(container))
+	}
+	for _, element := range list {
+		if equal(element, item) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func equal(a, b interface{}) bool {
+	if x, ok := toNumber(a); ok {
+		y, ok := toNumber(b)
+		return ok && x == y
+	}
+	if la, lb := toList(a), toList(b); la != nil || lb != nil {
+		if la == nil || lb == nil || len(la) != len(lb) {
+			return false
+		}
+		for i := range la {
+			if !equal(la[i], lb[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func toNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// toList returns v as a list, or nil if it is not one
+func toList(v interface{}) []interface{} {
+	switch l := v.(type) {
+	case []interface{}:
+		if l == nil {
+			return []interface{}{}
+		}
+		return l
+	case []string:
+		list := make([]interface{}, len(l))
+		for i, s := range l {
+			list[i] = s
+		}
+		return list
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package condition
+
+import (
+	"strings"
+	"testing"
+)
+
+func testVars() map[string]interface{} {
+	return map[string]interface{}{
+		"args": map[string]interface{}{
+			"database": "staging",
+			"limit":    float64(50),
+			"tags":     []interface{}{"a", "b"},
+			"options":  map[string]interface{}{"dry_run": true},
+			"project":  "ABC-123",
+		},
+		"user": map[string]interface{}{
+			"email":  "dev@example.com",
+			"role":   "developer",
+			"groups": []interface{}{"platform", "oncall"},
+		},
+		"request": map[string]interface{}{
+			"hour": float64(9),
+		},
+	}
+}
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		want bool
+	}{
+		// Precedence: ! binds tighter than &&, which binds tighter than ||
+		{"and before or", `true || false && false`, true},
+		{"and before or, left", `false && false || true`, true},
+		{"parentheses", `(true || false) && false`, false},
+		{"not binds to operand", `!false && false`, false},
+		{"not of group", `!(false && false)`, true},
+		{"double not", `!!true`, true},
+		{"not before comparison operand", `!(args.limit > 10)`, false},
+
+		{"equal string", `args.database == "staging"`, true},
+		{"not equal", `args.database != "production"`, true},
+		{"number comparison", `args.limit >= 50 && args.limit < 51`, true},
+		{"string ordering", `"abc" < "abd"`, true},
+		{"equal lists", `args.tags == ["a", "b"]`, true},
+		{"unequal lists", `args.tags == ["b", "a"]`, false},
+		{"number is not string", `args.limit == "50"`, false},
+
+		{"in list", `user.role in ["developer", "admin"]`, true},
+		{"not in list", `user.role in ["admin"]`, false},
+		{"in path list", `"oncall" in user.groups`, true},
+		{"in object keys", `"dry_run" in args.options`, true},
+		{"not in object keys", `"force" in args.options`, false},
+		{"non-string in object", `1 in args.options`, false},
+		{"in string", `"ABC" in args.project`, true},
+		{"not in string", `"XYZ" in args.project`, false},
+		{"in null", `"x" in args.missing`, false},
+
+		{"missing path is null", `args.missing == null`, true},
+		{"missing nested path is null", `args.options.missing.deeper == null`, true},
+		{"index past end is null", `args.tags[5] == null`, true},
+		{"field of a list is null", `args.tags.first == null`, true},
+		{"index", `args.tags[1] == "b"`, true},
+		{"bracket key", `args["database"] == "staging"`, true},
+
+		{"has present", `has(args.options.dry_run)`, true},
+		{"has missing", `has(args.options.force)`, false},
+		{"has list element", `has(args.tags[0])`, true},
+
+		{"matches", `matches(args.project, "^ABC-[0-9]+$")`, true},
+		{"matches no match", `matches(args.database, "^prod")`, false},
+		{"matches non-string", `matches(args.limit, ".*")`, false},
+
+		{"size", `size(args.tags) == 2 && size("héllo") == 5 && size(args.missing) == 0`, true},
+		{"lower", `lower("ABC") == "abc"`, true},
+		{"startsWith", `startsWith(args.project, "ABC-")`, true},
+		{"startsWith null", `startsWith(args.missing, "ABC-")`, false},
+		{"endsWith", `endsWith(user.email, "@example.com")`, true},
+		{"contains list", `contains(user.groups, "platform")`, true},
+		{"contains string", `contains(user.email, "example")`, true},
+
+		// The right operand is not evaluated once the left decides the result
+		{"and short-circuits", `false && args.limit > "x"`, false},
+		{"or short-circuits", `true || args.limit > "x"`, true},
+		{"and short-circuits non-boolean", `false && args.limit`, false},
+		{"or short-circuits non-boolean", `true || args.limit`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := Compile(tt.expr)
+			if err != nil {
+				t.Fatalf("Compile(%q): %v", tt.expr, err)
+			}
+			got, err := expr.Eval(testVars())
+			if err != nil {
+				t.Fatalf("Eval(%q): %v", tt.expr, err)
+			}
+			if got != tt.want {
+				t.Errorf("Eval(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		wantErr string
+	}{
+		{"number against string", `args.limit > "10"`, "cannot compare number > string"},
+		{"string against number", `args.database <= 3`, "cannot compare string <= number"},
+		{"null ordering", `args.missing < 3`, "cannot compare null < number"},
+		{"bool ordering", `true > false`, "cannot compare bool > bool"},
+		{"list ordering", `args.tags >= args.tags`, "cannot compare list >= list"},
+		{"non-boolean and", `args.limit && true`, "&& expects a boolean, got number"},
+		{"non-boolean or right", `false || args.database`, "|| expects a boolean, got string"},
+		{"non-boolean not", `!args.database`, "! expects a boolean, got string"},
+		{"non-boolean result", `args.limit`, "condition must be a boolean, got number"},
+		{"in number", `"a" in args.limit`, "in expects a list, object or string, got number"},
+		{"number in string", `1 in args.project`, "cannot look for number in a string"},
+		{"function argument type", `lower(args.limit) == "x"`, "lower(): expected a string, got number"},
+		{"size of number", `size(args.limit) == 1`, "size(): expected a string, list or object, got number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := Compile(tt.expr)
+			if err != nil {
+				t.Fatalf("Compile(%q): %v", tt.expr, err)
+			}
+			_, err = expr.Eval(testVars())
+			if err == nil {
+				t.Fatalf("Eval(%q) succeeded, want error containing %q", tt.expr, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Eval(%q) error = %q, want it to contain %q", tt.expr, err, tt.wantErr)
+			}
+		})
+	}
+}
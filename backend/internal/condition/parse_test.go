@@ -0,0 +1,78 @@
+package condition
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		wantErr string
+	}{
+		{"unknown variable", `env.HOME == "x"`, `position 1: unknown variable "env"`},
+		{"unknown function", `upper(args.x) == "X"`, `position 1: unknown function "upper"`},
+		{"function arity", `startsWith(args.x)`, `startsWith() takes 2 argument(s)`},
+		{"has without argument", `has()`, `has() takes 1 argument`},
+		{"has of a literal", `has("args.x")`, `has() takes a path such as args.project`},
+		{"has of a call", `has(lower(args.x))`, `has() takes a path such as args.project`},
+		{"matches arity", `matches(args.x)`, `matches() takes 2 arguments`},
+		{"matches pattern from a path", `matches(args.x, args.pattern)`, `the pattern of matches() must be a string literal`},
+		{"matches number pattern", `matches(args.x, 1)`, `the pattern of matches() must be a string literal`},
+		{"matches invalid pattern", `matches(args.x, "(")`, `invalid pattern`},
+		{"unterminated string", `args.x == "abc`, `position 11: unterminated string`},
+		{"unexpected character", `args.x == #`, `position 11: unexpected character '#'`},
+		{"trailing tokens", `true false`, `position 6: unexpected "false"`},
+		{"missing operand", `args.x ==`, `unexpected end of expression`},
+		{"unclosed parenthesis", `(true || false`, `expected ")", got end of expression`},
+		{"unclosed list", `args.x in ["a", "b"`, `expected ",", got end of expression`},
+		{"field name", `args.["x"]`, `expected a field name, got "["`},
+		{"chained comparison", `1 < 2 < 3`, `position 7: unexpected "<"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.expr)
+			if err == nil {
+				t.Fatalf("Compile(%q) succeeded, want error containing %q", tt.expr, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Compile(%q) error = %q, want it to contain %q", tt.expr, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCompileLiterals(t *testing.T) {
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`'single' == "single"`, true},
+		{`"a\"b" == 'a"b'`, true},
+		{`"tab\there" != "tab here"`, true},
+		{`1.5 > 1`, true},
+		{`[] == []`, true},
+		{`null == null`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := Compile(tt.expr)
+			if err != nil {
+				t.Fatalf("Compile(%q): %v", tt.expr, err)
+			}
+			if expr.String() != tt.expr {
+				t.Errorf("String() = %q, want %q", expr.String(), tt.expr)
+			}
+			got, err := expr.Eval(nil)
+			if err != nil {
+				t.Fatalf("Eval(%q): %v", tt.expr, err)
+			}
+			if got != tt.want {
+				t.Errorf("Eval(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
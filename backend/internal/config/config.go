@@ -11,16 +11,39 @@ import (
 )
 
 type Config struct {
-	Server     ServerConfig     `yaml:"server"`
-	Database   DatabaseConfig   `yaml:"database"`
-	JWT        JWTConfig        `yaml:"jwt"`
-	CORS       CORSConfig       `yaml:"cors"`
-	Session    SessionConfig    `yaml:"session"`
-	Encryption EncryptionConfig `yaml:"encryption"`
-	Logging    LoggingConfig    `yaml:"logging"`
-	Stdio      StdioConfig      `yaml:"stdio"`
-	Kubernetes KubernetesConfig `yaml:"kubernetes"`
-	Telemetry  TelemetryConfig  `yaml:"telemetry"`
+	Server      ServerConfig      `yaml:"server"`
+	Database    DatabaseConfig    `yaml:"database"`
+	JWT         JWTConfig         `yaml:"jwt"`
+	CORS        CORSConfig        `yaml:"cors"`
+	Session     SessionConfig     `yaml:"session"`
+	Encryption  EncryptionConfig  `yaml:"encryption"`
+	Logging     LoggingConfig     `yaml:"logging"`
+	Stdio       StdioConfig       `yaml:"stdio"`
+	Kubernetes  KubernetesConfig  `yaml:"kubernetes"`
+	Telemetry   TelemetryConfig   `yaml:"telemetry"`
+	Gateway     GatewayConfig     `yaml:"gateway"`
+	HealthCheck HealthCheckConfig `yaml:"health_check"`
+}
+
+type GatewayConfig struct {
+	ValidateOutputSchema bool          `yaml:"validate_output_schema"`
+	ToolDelimiter        string        `yaml:"tool_delimiter"`
+	ApprovalTimeout      time.Duration `yaml:"approval_timeout"`
+	RateLimitStore       string        `yaml:"rate_limit_store"`
+	ResponseCacheStore   string        `yaml:"response_cache_store"`
+	UpstreamTimeout      time.Duration `yaml:"upstream_timeout"`
+	ToolCallTimeout      time.Duration `yaml:"tool_call_timeout"`
+	UpstreamMaxAttempts  int           `yaml:"upstream_max_attempts"`
+	RetryBackoff         time.Duration `yaml:"retry_backoff"`
+	BreakerFailures      int           `yaml:"breaker_failures"`
+	BreakerCooldown      time.Duration `yaml:"breaker_cooldown"`
+}
+
+type HealthCheckConfig struct {
+	Enabled       bool          `yaml:"enabled"`
+	Interval      time.Duration `yaml:"interval"`
+	Timeout       time.Duration `yaml:"timeout"`
+	SkipUnhealthy bool          `yaml:"skip_unhealthy"`
 }
 
 type TelemetryConfig struct {
@@ -103,6 +126,10 @@ func Load(path string) (*Config, error) {
 	// Set defaults
 	setDefaults(&cfg)
 
+	if err := validate(&cfg); err != nil {
+		return nil, err
+	}
+
 	return &cfg, nil
 }
 
@@ -176,6 +203,69 @@ func setDefaults(cfg *Config) {
 	if cfg.Telemetry.Endpoint == "" {
 		cfg.Telemetry.Endpoint = "localhost:4317"
 	}
+	if cfg.Gateway.ToolDelimiter == "" {
+		cfg.Gateway.ToolDelimiter = "_"
+	}
+	if cfg.Gateway.ApprovalTimeout == 0 {
+		cfg.Gateway.ApprovalTimeout = 5 * time.Minute
+	}
+	if cfg.Gateway.RateLimitStore == "" {
+		cfg.Gateway.RateLimitStore = "postgres"
+	}
+	if cfg.Gateway.ResponseCacheStore == "" {
+		cfg.Gateway.ResponseCacheStore = "memory"
+	}
+	if cfg.Gateway.UpstreamTimeout == 0 {
+		cfg.Gateway.UpstreamTimeout = 30 * time.Second
+	}
+	if cfg.Gateway.UpstreamMaxAttempts == 0 {
+		cfg.Gateway.UpstreamMaxAttempts = 3
+	}
+	if cfg.Gateway.RetryBackoff == 0 {
+		cfg.Gateway.RetryBackoff = 200 * time.Millisecond
+	}
+	if cfg.Gateway.BreakerFailures == 0 {
+		cfg.Gateway.BreakerFailures = 5
+	}
+	if cfg.Gateway.BreakerCooldown == 0 {
+		cfg.Gateway.BreakerCooldown = 30 * time.Second
+	}
+	if cfg.HealthCheck.Interval == 0 {
+		cfg.HealthCheck.Interval = 30 * time.Second
+	}
+	if cfg.HealthCheck.Timeout == 0 {
+		cfg.HealthCheck.Timeout = 10 * time.Second
+	}
+}
+
+// toolDelimiterPattern rejects delimiters that could occur inside a target
+// namespace (lowercase letters, digits and hyphens), which would make prefixed
+// names ambiguous
+var toolDelimiterPattern = regexp.MustCompile(`^[^A-Za-z0-9-]{1,4}$`)
+
+func validate(cfg *Config) error {
+	if !toolDelimiterPattern.MatchString(cfg.Gateway.ToolDelimiter) {
+		return fmt.Errorf("gateway.tool_delimiter %q must be 1-4 characters other than letters, digits and '-'", cfg.Gateway.ToolDelimiter)
+	}
+	if cfg.Gateway.RateLimitStore != "postgres" && cfg.Gateway.RateLimitStore != "memory" {
+		return fmt.Errorf("gateway.rate_limit_store %q must be \"postgres\" or \"memory\"", cfg.Gateway.RateLimitStore)
+	}
+	if cfg.Gateway.ResponseCacheStore != "memory" && cfg.Gateway.ResponseCacheStore != "postgres" {
+		return fmt.Errorf("gateway.response_cache_store %q must be \"memory\" or \"postgres\"", cfg.Gateway.ResponseCacheStore)
+	}
+	if cfg.Gateway.UpstreamTimeout < 0 || cfg.Gateway.ToolCallTimeout < 0 || cfg.Gateway.RetryBackoff < 0 || cfg.Gateway.BreakerCooldown < 0 {
+		return fmt.Errorf("gateway upstream timeouts, retry_backoff and breaker_cooldown must not be negative")
+	}
+	if cfg.Gateway.UpstreamMaxAttempts < 1 {
+		return fmt.Errorf("gateway.upstream_max_attempts must be at least 1")
+	}
+	if cfg.Gateway.BreakerFailures < 1 {
+		return fmt.Errorf("gateway.breaker_failures must be at least 1")
+	}
+	if cfg.HealthCheck.Interval < 0 || cfg.HealthCheck.Timeout < 0 {
+		return fmt.Errorf("health_check.interval and health_check.timeout must not be negative")
+	}
+	return nil
 }
 
 // GetDSN returns the PostgreSQL connection string
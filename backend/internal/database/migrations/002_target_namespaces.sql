@@ -0,0 +1,24 @@
+-- Stable per-target namespace used to prefix tool, resource and prompt names.
+-- Backfilled from the target name the same way as database.DefaultNamespace.
+
+ALTER TABLE targets ADD COLUMN IF NOT EXISTS namespace VARCHAR(64);
+
+UPDATE targets
+SET namespace = COALESCE(
+    NULLIF(RTRIM(LEFT(TRIM(BOTH '-' FROM REGEXP_REPLACE(LOWER(name), '[^a-z0-9]+', '-', 'g')), 64), '-'), ''),
+    'target')
+WHERE namespace IS NULL;
+
+-- Names that normalize to the same namespace keep the oldest target's namespace;
+-- the others get a short ID suffix
+UPDATE targets t
+SET namespace = RTRIM(LEFT(t.namespace, 55), '-') || '-' || LEFT(REPLACE(t.id::text, '-', ''), 8)
+WHERE EXISTS (
+    SELECT 1 FROM targets o
+    WHERE o.namespace = t.namespace
+      AND (o.created_at, o.id) < (t.created_at, t.id)
+);
+
+ALTER TABLE targets ALTER COLUMN namespace SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_targets_namespace ON targets(namespace);
@@ -0,0 +1,18 @@
+-- Admin-defined overlays on upstream tools: rename, replace the description or
+-- inputSchema, and hide arguments the gateway fills in on every call.
+
+CREATE TABLE IF NOT EXISTS tool_overrides (
+    id              UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    target_id       UUID         NOT NULL REFERENCES targets(id) ON DELETE CASCADE,
+    tool_name       VARCHAR(128) NOT NULL,
+    name            VARCHAR(128),
+    description     TEXT,
+    input_schema    JSONB,
+    fixed_arguments JSONB        NOT NULL DEFAULT '{}',
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    UNIQUE (target_id, tool_name)
+);
+
+-- Two overrides of one target cannot rename tools to the same name
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tool_overrides_name ON tool_overrides(target_id, name) WHERE name IS NOT NULL;
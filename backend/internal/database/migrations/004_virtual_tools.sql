@@ -0,0 +1,14 @@
+-- Composite virtual tools: gateway-level tools that run a sequence of upstream
+-- tool calls. Steps and the optional output template are stored as JSON.
+
+CREATE TABLE IF NOT EXISTS virtual_tools (
+    id              UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    name            VARCHAR(128) UNIQUE NOT NULL,
+    description     TEXT         NOT NULL DEFAULT '',
+    input_schema    JSONB        NOT NULL DEFAULT '{"type": "object"}',
+    steps           JSONB        NOT NULL DEFAULT '[]',
+    output          JSONB,
+    enabled         BOOLEAN      NOT NULL DEFAULT true,
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,6 @@
+-- Per-target validation of tool call arguments against the tool's inputSchema:
+-- 'off' forwards as before, 'warn' forwards and records violations in the audit
+-- log, 'enforce' rejects invalid calls.
+
+ALTER TABLE targets ADD COLUMN IF NOT EXISTS argument_validation VARCHAR(20) NOT NULL DEFAULT 'off'
+    CHECK (argument_validation IN ('off', 'warn', 'enforce'));
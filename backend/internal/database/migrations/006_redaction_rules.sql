@@ -0,0 +1,17 @@
+-- Redaction rules: detectors run over tool results, resource contents and
+-- prompt messages before they reach the client. A rule applies to every target
+-- and role unless target_id or role narrow it.
+
+CREATE TABLE IF NOT EXISTS redaction_rules (
+    id          UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    name        VARCHAR(255) UNIQUE NOT NULL,
+    description TEXT         NOT NULL DEFAULT '',
+    detector    VARCHAR(50)  NOT NULL,
+    pattern     TEXT,
+    action      VARCHAR(10)  NOT NULL DEFAULT 'mask' CHECK (action IN ('mask', 'drop', 'block')),
+    target_id   UUID         REFERENCES targets(id) ON DELETE CASCADE,
+    role        VARCHAR(50),
+    enabled     BOOLEAN      NOT NULL DEFAULT true,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
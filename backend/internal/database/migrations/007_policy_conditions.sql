@@ -0,0 +1,5 @@
+-- Optional condition expression on authorization policies, evaluated over the
+-- call arguments, the caller, the target and the request time. NULL means the
+-- policy applies unconditionally.
+
+ALTER TABLE authorization_policies ADD COLUMN IF NOT EXISTS condition TEXT;
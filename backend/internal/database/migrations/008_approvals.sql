@@ -0,0 +1,32 @@
+-- Human-in-the-loop approvals: a tools/call matched by a require_approval policy
+-- is parked in the approvals table until an approver with the policy's
+-- approver_role (default admin) approves or rejects it, or it expires.
+
+ALTER TABLE authorization_policies ALTER COLUMN effect TYPE VARCHAR(20);
+ALTER TABLE authorization_policies DROP CONSTRAINT IF EXISTS authorization_policies_effect_check;
+ALTER TABLE authorization_policies ADD CONSTRAINT authorization_policies_effect_check
+    CHECK (effect IN ('allow', 'deny', 'require_approval'));
+ALTER TABLE authorization_policies ADD COLUMN IF NOT EXISTS approver_role VARCHAR(50);
+
+CREATE TABLE IF NOT EXISTS approvals (
+    id               UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    policy_id        UUID         REFERENCES authorization_policies(id) ON DELETE SET NULL,
+    policy_name      VARCHAR(255) NOT NULL,
+    approver_role    VARCHAR(50)  NOT NULL,
+    session_id       VARCHAR(100) NOT NULL,
+    user_id          UUID         NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_email       VARCHAR(255) NOT NULL DEFAULT '',
+    target_name      VARCHAR(255) NOT NULL DEFAULT '',
+    tool_name        VARCHAR(255) NOT NULL,
+    arguments        JSONB        NOT NULL DEFAULT '{}',
+    status           VARCHAR(20)  NOT NULL DEFAULT 'pending'
+                     CHECK (status IN ('pending', 'approved', 'rejected', 'expired', 'cancelled')),
+    decided_by       UUID         REFERENCES users(id) ON DELETE SET NULL,
+    decided_by_email VARCHAR(255),
+    reason           TEXT,
+    created_at       TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    expires_at       TIMESTAMPTZ  NOT NULL,
+    decided_at       TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_approvals_status ON approvals(status, created_at DESC);
@@ -0,0 +1,31 @@
+-- Token-bucket rate limits on tools/call. A limit applies to calls by its
+-- subject (a user, role, group or everyone), optionally narrowed to one target
+-- and to tools matching a pattern. Each user draws from their own bucket unless
+-- per_user is false, in which case every matching call shares one bucket.
+-- Buckets are stored here so that all gateway replicas share the same tokens.
+
+CREATE TABLE IF NOT EXISTS rate_limits (
+    id             UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    name           VARCHAR(255) UNIQUE NOT NULL,
+    description    TEXT         NOT NULL DEFAULT '',
+    subject_type   VARCHAR(20)  NOT NULL DEFAULT 'everyone' CHECK (subject_type IN ('user', 'role', 'group', 'everyone')),
+    subject_value  VARCHAR(255),
+    target_id      UUID         REFERENCES targets(id) ON DELETE CASCADE,
+    tool_pattern   VARCHAR(255),
+    requests       INTEGER      NOT NULL CHECK (requests > 0),
+    period_seconds INTEGER      NOT NULL CHECK (period_seconds > 0),
+    burst          INTEGER      NOT NULL CHECK (burst > 0),
+    per_user       BOOLEAN      NOT NULL DEFAULT true,
+    enabled        BOOLEAN      NOT NULL DEFAULT true,
+    created_at     TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+    limit_id   UUID             NOT NULL REFERENCES rate_limits(id) ON DELETE CASCADE,
+    bucket_key VARCHAR(255)     NOT NULL,
+    tokens     DOUBLE PRECISION NOT NULL,
+    allowed    BOOLEAN          NOT NULL DEFAULT true, -- whether the last take got a token
+    updated_at TIMESTAMPTZ      NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (limit_id, bucket_key)
+);
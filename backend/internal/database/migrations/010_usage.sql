@@ -0,0 +1,59 @@
+-- Usage metering. Every tools/call, resources/read and prompts/get forwarded to
+-- an upstream target is recorded with who made it and what it cost. A call
+-- costs the weight configured for its tool, else for its target, else 1.
+-- Records keep the user's email, role and groups at the time of the call so
+-- that reports by team stay correct after users move between groups.
+
+CREATE TABLE IF NOT EXISTS usage_records (
+    id             BIGSERIAL        PRIMARY KEY,
+    user_id        UUID             REFERENCES users(id) ON DELETE SET NULL,
+    user_email     VARCHAR(255)     NOT NULL DEFAULT '',
+    role           VARCHAR(50)      NOT NULL DEFAULT '',
+    groups         TEXT[]           NOT NULL DEFAULT '{}',
+    target_id      UUID,            -- no foreign key: usage outlives targets
+    target_name    VARCHAR(255)     NOT NULL,
+    method         VARCHAR(50)      NOT NULL,
+    name           VARCHAR(1024)    NOT NULL, -- upstream tool, resource URI or prompt
+    request_bytes  INTEGER          NOT NULL DEFAULT 0,
+    response_bytes INTEGER          NOT NULL DEFAULT 0,
+    duration_ms    INTEGER          NOT NULL DEFAULT 0,
+    status         VARCHAR(20)      NOT NULL, -- "ok" or "error"
+    cost           DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created_at     TIMESTAMPTZ      NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_usage_records_created_at ON usage_records(created_at);
+CREATE INDEX IF NOT EXISTS idx_usage_records_user_created ON usage_records(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_usage_records_groups ON usage_records USING GIN (groups);
+
+-- Cost weights price calls to a target, or to one of its tools
+CREATE TABLE IF NOT EXISTS cost_weights (
+    id          UUID             PRIMARY KEY DEFAULT gen_random_uuid(),
+    target_id   UUID             NOT NULL REFERENCES targets(id) ON DELETE CASCADE,
+    tool_name   VARCHAR(255),    -- upstream tool name; NULL = every call to the target
+    weight      DOUBLE PRECISION NOT NULL CHECK (weight >= 0),
+    created_at  TIMESTAMPTZ      NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ      NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cost_weights_target_tool ON cost_weights(target_id, COALESCE(tool_name, ''));
+
+-- Usage quotas cap the cost of calls per day or calendar month (UTC). Like rate
+-- limits, a quota applies to a subject, optionally on one target, and counts
+-- each user's usage separately unless per_user is false, in which case all
+-- matching usage counts against one budget (e.g. a team's).
+CREATE TABLE IF NOT EXISTS usage_quotas (
+    id              UUID             PRIMARY KEY DEFAULT gen_random_uuid(),
+    name            VARCHAR(255)     UNIQUE NOT NULL,
+    description     TEXT             NOT NULL DEFAULT '',
+    subject_type    VARCHAR(20)      NOT NULL DEFAULT 'everyone' CHECK (subject_type IN ('user', 'role', 'group', 'everyone')),
+    subject_value   VARCHAR(255),
+    target_id       UUID             REFERENCES targets(id) ON DELETE CASCADE,
+    period          VARCHAR(10)      NOT NULL CHECK (period IN ('day', 'month')),
+    max_cost        DOUBLE PRECISION NOT NULL CHECK (max_cost > 0),
+    warn_thresholds INTEGER[]        NOT NULL DEFAULT '{80}', -- percentages of max_cost
+    per_user        BOOLEAN          NOT NULL DEFAULT true,
+    enabled         BOOLEAN          NOT NULL DEFAULT true,
+    created_at      TIMESTAMPTZ      NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ      NOT NULL DEFAULT NOW()
+);
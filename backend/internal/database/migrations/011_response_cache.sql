@@ -0,0 +1,25 @@
+-- Response caching for read-only tools, resources and prompts. A target's
+-- cache_ttl_seconds enables caching of its resources/read and prompts/get
+-- results and of tools annotated readOnlyHint; 0 disables it. A tool override's
+-- cache_ttl_seconds replaces that for one tool: NULL inherits, 0 never caches.
+
+ALTER TABLE targets ADD COLUMN IF NOT EXISTS cache_ttl_seconds INTEGER NOT NULL DEFAULT 0
+    CHECK (cache_ttl_seconds >= 0);
+
+ALTER TABLE tool_overrides ADD COLUMN IF NOT EXISTS cache_ttl_seconds INTEGER
+    CHECK (cache_ttl_seconds >= 0);
+
+-- Shared cache tier, used when gateway.response_cache_store is "postgres".
+-- Entries are keyed by a hash of the target, the caller's isolation subject and
+-- credentials, the method, the name and the arguments. The tag groups entries
+-- of one resource across callers so a resources/updated notification can drop
+-- them all.
+CREATE TABLE IF NOT EXISTS response_cache (
+    cache_key  VARCHAR(64)  PRIMARY KEY,
+    tag        TEXT         NOT NULL,
+    value      BYTEA        NOT NULL,
+    expires_at TIMESTAMPTZ  NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_response_cache_tag ON response_cache(tag);
+CREATE INDEX IF NOT EXISTS idx_response_cache_expires_at ON response_cache(expires_at);
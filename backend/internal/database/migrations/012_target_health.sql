@@ -0,0 +1,16 @@
+-- Results of the background health checker, one row per target. HTTP, SSE and
+-- WebSocket targets are probed with initialize and tools/list (or ping) using
+-- the target's default credentials; STDIO targets are checked for a live
+-- process. last_error keeps the most recent failure after the target recovers.
+
+CREATE TABLE IF NOT EXISTS target_health (
+    target_id            UUID         PRIMARY KEY REFERENCES targets(id) ON DELETE CASCADE,
+    status               VARCHAR(20)  NOT NULL CHECK (status IN ('healthy', 'unhealthy', 'unknown')),
+    latency_ms           INTEGER,
+    tool_count           INTEGER,
+    last_error           TEXT,
+    consecutive_failures INTEGER      NOT NULL DEFAULT 0,
+    last_checked_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    last_success_at      TIMESTAMPTZ,
+    last_failure_at      TIMESTAMPTZ
+);
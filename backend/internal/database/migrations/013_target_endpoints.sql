@@ -0,0 +1,14 @@
+-- Several upstream endpoints per HTTP, SSE or WebSocket target. endpoints is a
+-- JSON array of {"url", "weight"}; when empty the target's url is its only
+-- endpoint. load_balancing picks the endpoint of each new session.
+
+ALTER TABLE targets ADD COLUMN IF NOT EXISTS endpoints JSONB NOT NULL DEFAULT '[]';
+
+ALTER TABLE targets ADD COLUMN IF NOT EXISTS load_balancing VARCHAR(30) NOT NULL DEFAULT 'round_robin'
+    CHECK (load_balancing IN ('round_robin', 'least_outstanding', 'consistent_hash'));
+
+-- Stateful targets keep a subject on one endpoint
+UPDATE targets SET load_balancing = 'consistent_hash' WHERE statefulness = 'stateful';
+
+-- Health check results of each endpoint: [{"url", "status", "latency_ms", "error"}]
+ALTER TABLE target_health ADD COLUMN IF NOT EXISTS endpoints JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,4 @@
+-- Responses served from the response cache are metered too, marked cached and
+-- at no cost, so usage reports show the calls the cache saved.
+
+ALTER TABLE usage_records ADD COLUMN IF NOT EXISTS cached BOOLEAN NOT NULL DEFAULT false;
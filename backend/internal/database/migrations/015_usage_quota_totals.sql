@@ -0,0 +1,15 @@
+-- Running totals of the cost counted against each usage quota, so quota checks
+-- read one row instead of summing usage_records. A total covers one period of
+-- a quota for one user, or '*' when the quota's usage is shared. It is seeded
+-- from usage_records on first read; each metered call then adds its cost in
+-- the transaction that writes its usage record. A total first created by a
+-- metered call stays unseeded until it is read.
+
+CREATE TABLE IF NOT EXISTS usage_quota_totals (
+    quota_id     UUID             NOT NULL REFERENCES usage_quotas(id) ON DELETE CASCADE,
+    bucket_key   VARCHAR(255)     NOT NULL, -- user ID, or '*' for usage shared by the quota's subject
+    period_start TIMESTAMPTZ      NOT NULL,
+    cost         DOUBLE PRECISION NOT NULL DEFAULT 0,
+    seeded       BOOLEAN          NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (quota_id, bucket_key, period_start)
+);
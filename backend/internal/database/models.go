@@ -1,6 +1,7 @@
 package database
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -30,23 +31,57 @@ type APIToken struct {
 
 // Target represents an MCP server upstream
 type Target struct {
-	ID                    uuid.UUID `json:"id"`
-	Name                  string    `json:"name"`
-	URL                   string    `json:"url"`
-	TransportType         string    `json:"transport_type"`                    // "streamable-http" (default), "sse", or "stdio"
-	Command               string    `json:"command,omitempty"`                 // STDIO: command to execute
-	Args                  []string  `json:"args,omitempty"`                    // STDIO: command arguments
-	Image                 string    `json:"image,omitempty"`                   // Kubernetes: container image
-	Port                  int       `json:"port,omitempty"`                    // Kubernetes: MCP server port (default 8080)
-	HealthPath            string    `json:"health_path,omitempty"`             // Kubernetes: readiness probe path (default "/")
-	Statefulness          string    `json:"statefulness"`                      // "stateless" or "stateful"
-	IsolationBoundary     string    `json:"isolation_boundary"`                // "shared", "per_group", "per_role", "per_user"
-	AuthType              string    `json:"auth_type"`
-	AuthHeaderName        string    `json:"auth_header_name,omitempty"`
-	Enabled               bool      `json:"enabled"`
-	DefaultEncryptedToken *string   `json:"-"`
-	CreatedAt             time.Time `json:"created_at"`
-	UpdatedAt             time.Time `json:"updated_at"`
+	ID                    uuid.UUID        `json:"id"`
+	Name                  string           `json:"name"`
+	Namespace             string           `json:"namespace"`                         // Stable prefix for the target's tool, resource and prompt names
+	URL                   string           `json:"url"`
+	TransportType         string           `json:"transport_type"`                    // "streamable-http" (default), "sse", "websocket", or "stdio"
+	Command               string           `json:"command,omitempty"`                 // STDIO: command to execute
+	Args                  []string         `json:"args,omitempty"`                    // STDIO: command arguments
+	Image                 string           `json:"image,omitempty"`                   // Kubernetes: container image
+	Port                  int              `json:"port,omitempty"`                    // Kubernetes: MCP server port (default 8080)
+	HealthPath            string           `json:"health_path,omitempty"`             // Kubernetes: readiness probe path (default "/")
+	Statefulness          string           `json:"statefulness"`                      // "stateless" or "stateful"
+	IsolationBoundary     string           `json:"isolation_boundary"`                // "shared", "per_group", "per_role", "per_user"
+	ArgumentValidation    string           `json:"argument_validation"`               // "off", "warn" or "enforce": checks tool arguments against inputSchema
+	CacheTTLSeconds       int              `json:"cache_ttl_seconds"`                 // response cache TTL for read-only tools, resources and prompts; 0 = off
+	Endpoints             []TargetEndpoint `json:"endpoints"`                         // HTTP: upstream replicas; empty = URL only
+	LoadBalancing         string           `json:"load_balancing"`                    // "round_robin", "least_outstanding" or "consistent_hash"
+	AuthType              string           `json:"auth_type"`
+	AuthHeaderName        string           `json:"auth_header_name,omitempty"`
+	Enabled               bool             `json:"enabled"`
+	DefaultEncryptedToken *string          `json:"-"`
+	CreatedAt             time.Time        `json:"created_at"`
+	UpdatedAt             time.Time        `json:"updated_at"`
+}
+
+// Target argument validation modes
+const (
+	ArgumentValidationOff     = "off"     // forward arguments unchecked
+	ArgumentValidationWarn    = "warn"    // forward, recording violations in the audit log
+	ArgumentValidationEnforce = "enforce" // reject calls whose arguments violate the inputSchema
+)
+
+// TargetEndpoint is one upstream replica of an HTTP, SSE or WebSocket target
+type TargetEndpoint struct {
+	URL    string `json:"url"`
+	Weight int    `json:"weight"` // relative share of sessions, at least 1
+}
+
+// Target load balancing strategies
+const (
+	LoadBalancingRoundRobin       = "round_robin"       // weighted round-robin
+	LoadBalancingLeastOutstanding = "least_outstanding" // fewest in-flight requests per unit of weight
+	LoadBalancingConsistentHash   = "consistent_hash"   // weighted rendezvous hashing on the isolation subject key
+)
+
+// UpstreamEndpoints returns the endpoints sessions are spread across: the
+// configured endpoints, or the target's URL
+func (t *Target) UpstreamEndpoints() []TargetEndpoint {
+	if len(t.Endpoints) > 0 {
+		return t.Endpoints
+	}
+	return []TargetEndpoint{{URL: t.URL, Weight: 1}}
 }
 
 // MCPInstance represents a running MCP process instance
@@ -133,35 +168,45 @@ type LoginRequest struct {
 
 // CreateTargetRequest is used for creating a new target
 type CreateTargetRequest struct {
-	Name              string   `json:"name"`
-	URL               string   `json:"url"`
-	TransportType     string   `json:"transport_type,omitempty"`     // "streamable-http" (default), "sse", or "stdio"
-	Command           string   `json:"command,omitempty"`            // STDIO: command to execute
-	Args              []string `json:"args,omitempty"`               // STDIO: command arguments
-	Image             string   `json:"image,omitempty"`              // Kubernetes: container image
-	Port              int      `json:"port,omitempty"`               // Kubernetes: MCP server port (default 8080)
-	HealthPath        string   `json:"health_path,omitempty"`        // Kubernetes: readiness probe path (default "/")
-	Statefulness      string   `json:"statefulness,omitempty"`       // "stateless" or "stateful"; default: "stateless"
-	IsolationBoundary string   `json:"isolation_boundary,omitempty"` // default: "shared"
-	AuthType          string   `json:"auth_type"`
-	AuthHeaderName    string   `json:"auth_header_name,omitempty"`
+	Name               string           `json:"name"`
+	Namespace          string           `json:"namespace,omitempty"` // default: derived from name
+	URL                string           `json:"url"`
+	TransportType      string           `json:"transport_type,omitempty"`      // "streamable-http" (default), "sse", "websocket", or "stdio"
+	Command            string           `json:"command,omitempty"`             // STDIO: command to execute
+	Args               []string         `json:"args,omitempty"`                // STDIO: command arguments
+	Image              string           `json:"image,omitempty"`               // Kubernetes: container image
+	Port               int              `json:"port,omitempty"`                // Kubernetes: MCP server port (default 8080)
+	HealthPath         string           `json:"health_path,omitempty"`         // Kubernetes: readiness probe path (default "/")
+	Statefulness       string           `json:"statefulness,omitempty"`        // "stateless" or "stateful"; default: "stateless"
+	IsolationBoundary  string           `json:"isolation_boundary,omitempty"`  // default: "shared"
+	ArgumentValidation string           `json:"argument_validation,omitempty"` // default: "off"
+	CacheTTLSeconds    int              `json:"cache_ttl_seconds,omitempty"`   // default: 0 (no caching)
+	Endpoints          []TargetEndpoint `json:"endpoints,omitempty"`           // default: url only
+	LoadBalancing      string           `json:"load_balancing,omitempty"`      // default: "consistent_hash" for stateful targets, else "round_robin"
+	AuthType           string           `json:"auth_type"`
+	AuthHeaderName     string           `json:"auth_header_name,omitempty"`
 }
 
 // UpdateTargetRequest is used for updating an existing target
 type UpdateTargetRequest struct {
-	Name              *string   `json:"name,omitempty"`
-	URL               *string   `json:"url,omitempty"`
-	TransportType     *string   `json:"transport_type,omitempty"`
-	Command           *string   `json:"command,omitempty"`
-	Args              *[]string `json:"args,omitempty"`
-	Image             *string   `json:"image,omitempty"`
-	Port              *int      `json:"port,omitempty"`
-	HealthPath        *string   `json:"health_path,omitempty"`
-	Statefulness      *string   `json:"statefulness,omitempty"`
-	IsolationBoundary *string   `json:"isolation_boundary,omitempty"`
-	AuthType          *string   `json:"auth_type,omitempty"`
-	AuthHeaderName    *string   `json:"auth_header_name,omitempty"`
-	Enabled           *bool     `json:"enabled,omitempty"`
+	Name               *string           `json:"name,omitempty"`
+	Namespace          *string           `json:"namespace,omitempty"`
+	URL                *string           `json:"url,omitempty"`
+	TransportType      *string           `json:"transport_type,omitempty"`
+	Command            *string           `json:"command,omitempty"`
+	Args               *[]string         `json:"args,omitempty"`
+	Image              *string           `json:"image,omitempty"`
+	Port               *int              `json:"port,omitempty"`
+	HealthPath         *string           `json:"health_path,omitempty"`
+	Statefulness       *string           `json:"statefulness,omitempty"`
+	IsolationBoundary  *string           `json:"isolation_boundary,omitempty"`
+	ArgumentValidation *string           `json:"argument_validation,omitempty"`
+	CacheTTLSeconds    *int              `json:"cache_ttl_seconds,omitempty"`
+	Endpoints          *[]TargetEndpoint `json:"endpoints,omitempty"`
+	LoadBalancing      *string           `json:"load_balancing,omitempty"`
+	AuthType           *string           `json:"auth_type,omitempty"`
+	AuthHeaderName     *string           `json:"auth_header_name,omitempty"`
+	Enabled            *bool             `json:"enabled,omitempty"`
 }
 
 // SetTokenRequest is used for setting a user's token for a target
@@ -236,16 +281,28 @@ type AuthorizationPolicy struct {
 	Name            string          `json:"name"`
 	Description     string          `json:"description,omitempty"`
 	TargetID        *uuid.UUID      `json:"target_id,omitempty"` // NULL = applies to all targets
-	ResourceType    string          `json:"resource_type"`       // "all", "tool", "resource", "prompt"
+	ResourceType    string          `json:"resource_type"`       // "all", "tool", "resource", "prompt", "sampling", "elicitation", "roots"
 	ResourcePattern *string         `json:"resource_pattern,omitempty"`
-	Effect          string          `json:"effect"`   // "allow" or "deny"
-	Priority        int             `json:"priority"` // Higher = evaluated first
+	Condition       *string         `json:"condition,omitempty"`     // expression over the request; NULL = always applies
+	Effect          string          `json:"effect"`                  // "allow", "deny" or "require_approval"
+	ApproverRole    *string         `json:"approver_role,omitempty"` // require_approval: role that may decide; NULL = "admin"
+	Priority        int             `json:"priority"`                // Higher = evaluated first
 	Enabled         bool            `json:"enabled"`
 	Subjects        []PolicySubject `json:"subjects,omitempty"`
 	CreatedAt       time.Time       `json:"created_at"`
 	UpdatedAt       time.Time       `json:"updated_at"`
 }
 
+// Authorization policy effects
+const (
+	EffectAllow           = "allow"
+	EffectDeny            = "deny"
+	EffectRequireApproval = "require_approval" // tools/call waits for an approver
+)
+
+// DefaultApproverRole decides approvals for policies without an approver_role
+const DefaultApproverRole = "admin"
+
 // PolicySubject represents who a policy applies to
 type PolicySubject struct {
 	ID           uuid.UUID `json:"id"`
@@ -262,7 +319,9 @@ type CreatePolicyRequest struct {
 	TargetID        *uuid.UUID              `json:"target_id,omitempty"`
 	ResourceType    string                  `json:"resource_type"`
 	ResourcePattern *string                 `json:"resource_pattern,omitempty"`
+	Condition       *string                 `json:"condition,omitempty"`
 	Effect          string                  `json:"effect"`
+	ApproverRole    *string                 `json:"approver_role,omitempty"`
 	Priority        int                     `json:"priority"`
 	Enabled         *bool                   `json:"enabled,omitempty"`
 	Subjects        []CreateSubjectRequest  `json:"subjects,omitempty"`
@@ -275,7 +334,9 @@ type UpdatePolicyRequest struct {
 	TargetID        *uuid.UUID `json:"target_id,omitempty"`
 	ResourceType    *string    `json:"resource_type,omitempty"`
 	ResourcePattern *string    `json:"resource_pattern,omitempty"`
+	Condition       *string    `json:"condition,omitempty"` // "" removes the condition
 	Effect          *string    `json:"effect,omitempty"`
+	ApproverRole    *string    `json:"approver_role,omitempty"` // "" resets to "admin"
 	Priority        *int       `json:"priority,omitempty"`
 	Enabled         *bool      `json:"enabled,omitempty"`
 }
@@ -301,6 +362,20 @@ type AuthorizationCheckResult struct {
 	Reason       string  `json:"reason"`
 }
 
+// ValidateConditionRequest is used for checking a policy condition expression,
+// optionally evaluating it over sample variables
+type ValidateConditionRequest struct {
+	Condition string                 `json:"condition"`
+	Input     map[string]interface{} `json:"input,omitempty"` // args, user, target, resource, request
+}
+
+// ValidateConditionResult is the result of a condition check
+type ValidateConditionResult struct {
+	Valid  bool   `json:"valid"`
+	Error  string `json:"error,omitempty"`
+	Result *bool  `json:"result,omitempty"` // set when input was given
+}
+
 // ============================================================================
 // ENVIRONMENT CONFIGURATIONS
 // ============================================================================
@@ -358,3 +433,378 @@ type ResolvedEnvConfig struct {
 	TargetName string          `json:"target_name"`
 	Configs    []EnvConfigInfo `json:"configs"`
 }
+
+// ============================================================================
+// TOOL OVERRIDES
+// ============================================================================
+
+// ToolOverride is an admin-defined overlay on one upstream tool of a target
+type ToolOverride struct {
+	ID              uuid.UUID              `json:"id"`
+	TargetID        uuid.UUID              `json:"target_id"`
+	ToolName        string                 `json:"tool_name"`                   // upstream (unprefixed) tool name
+	Name            *string                `json:"name,omitempty"`              // listed instead of tool_name, still namespace-prefixed
+	Description     *string                `json:"description,omitempty"`       // replaces the upstream description
+	InputSchema     json.RawMessage        `json:"input_schema,omitempty"`      // replaces the upstream inputSchema
+	FixedArguments  map[string]interface{} `json:"fixed_arguments,omitempty"`   // hidden from clients and set on every call
+	CacheTTLSeconds *int                   `json:"cache_ttl_seconds,omitempty"` // replaces the target's cache TTL for this tool; 0 = never cache
+	CreatedAt       time.Time              `json:"created_at"`
+	UpdatedAt       time.Time              `json:"updated_at"`
+}
+
+// SetToolOverrideRequest is used for creating or replacing a tool override
+type SetToolOverrideRequest struct {
+	Name            *string                `json:"name,omitempty"`
+	Description     *string                `json:"description,omitempty"`
+	InputSchema     json.RawMessage        `json:"input_schema,omitempty"`
+	FixedArguments  map[string]interface{} `json:"fixed_arguments,omitempty"`
+	CacheTTLSeconds *int                   `json:"cache_ttl_seconds,omitempty"`
+}
+
+// ============================================================================
+// VIRTUAL TOOLS
+// ============================================================================
+
+// VirtualTool is a gateway-level tool that runs a sequence of upstream tool calls
+type VirtualTool struct {
+	ID          uuid.UUID       `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"input_schema"`
+	Steps       []WorkflowStep  `json:"steps"`
+	Output      json.RawMessage `json:"output,omitempty"` // result template; default: the last step's result
+	Enabled     bool            `json:"enabled"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// WorkflowStep is one upstream tool call of a virtual tool
+type WorkflowStep struct {
+	ID        string                 `json:"id"`
+	Tool      string                 `json:"tool"`                // client-visible tool name, e.g. "jira_create_issue"
+	Arguments map[string]interface{} `json:"arguments,omitempty"` // may reference $.input and earlier steps
+	OnError   string                 `json:"on_error,omitempty"`  // "abort" (default) or "continue"
+}
+
+// CreateVirtualToolRequest is used for creating a new virtual tool
+type CreateVirtualToolRequest struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"input_schema,omitempty"` // default: {"type": "object"}
+	Steps       []WorkflowStep  `json:"steps"`
+	Output      json.RawMessage `json:"output,omitempty"`
+	Enabled     *bool           `json:"enabled,omitempty"`
+}
+
+// UpdateVirtualToolRequest is used for updating an existing virtual tool
+type UpdateVirtualToolRequest struct {
+	Name        *string          `json:"name,omitempty"`
+	Description *string          `json:"description,omitempty"`
+	InputSchema *json.RawMessage `json:"input_schema,omitempty"`
+	Steps       *[]WorkflowStep  `json:"steps,omitempty"`
+	Output      *json.RawMessage `json:"output,omitempty"`
+	Enabled     *bool            `json:"enabled,omitempty"`
+}
+
+// ============================================================================
+// REDACTION RULES
+// ============================================================================
+
+// RedactionRule masks, drops or blocks upstream content matching a detector
+type RedactionRule struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Detector    string     `json:"detector"`            // a built-in detector or "regex"
+	Pattern     *string    `json:"pattern,omitempty"`   // required for detector "regex"
+	Action      string     `json:"action"`              // "mask", "drop" or "block"
+	TargetID    *uuid.UUID `json:"target_id,omitempty"` // NULL = applies to all targets
+	Role        *string    `json:"role,omitempty"`      // NULL = applies to all roles
+	Enabled     bool       `json:"enabled"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// CreateRedactionRuleRequest is used for creating a new redaction rule
+type CreateRedactionRuleRequest struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Detector    string     `json:"detector"`
+	Pattern     *string    `json:"pattern,omitempty"`
+	Action      string     `json:"action,omitempty"` // default: "mask"
+	TargetID    *uuid.UUID `json:"target_id,omitempty"`
+	Role        *string    `json:"role,omitempty"`
+	Enabled     *bool      `json:"enabled,omitempty"`
+}
+
+// UpdateRedactionRuleRequest is used for updating an existing redaction rule.
+// An empty target_id or role clears it, applying the rule to all targets or roles.
+type UpdateRedactionRuleRequest struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Detector    *string `json:"detector,omitempty"`
+	Pattern     *string `json:"pattern,omitempty"`
+	Action      *string `json:"action,omitempty"`
+	TargetID    *string `json:"target_id,omitempty"`
+	Role        *string `json:"role,omitempty"`
+	Enabled     *bool   `json:"enabled,omitempty"`
+}
+
+// ============================================================================
+// RATE LIMITS
+// ============================================================================
+
+// RateLimit is a token bucket on tools/call: burst tokens, refilled at
+// requests per period_seconds
+type RateLimit struct {
+	ID            uuid.UUID  `json:"id"`
+	Name          string     `json:"name"`
+	Description   string     `json:"description,omitempty"`
+	SubjectType   string     `json:"subject_type"`            // "user", "role", "group", "everyone"
+	SubjectValue  *string    `json:"subject_value,omitempty"` // user_id, role name, group name (NULL for "everyone")
+	TargetID      *uuid.UUID `json:"target_id,omitempty"`     // NULL = applies to all targets
+	ToolPattern   *string    `json:"tool_pattern,omitempty"`  // regex on the upstream tool name; NULL = all tools
+	Requests      int        `json:"requests"`
+	PeriodSeconds int        `json:"period_seconds"`
+	Burst         int        `json:"burst"`    // bucket capacity
+	PerUser       bool       `json:"per_user"` // one bucket per user, or one shared by all matching calls
+	Enabled       bool       `json:"enabled"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// CreateRateLimitRequest is used for creating a new rate limit
+type CreateRateLimitRequest struct {
+	Name          string     `json:"name"`
+	Description   string     `json:"description,omitempty"`
+	SubjectType   string     `json:"subject_type,omitempty"` // default: "everyone"
+	SubjectValue  *string    `json:"subject_value,omitempty"`
+	TargetID      *uuid.UUID `json:"target_id,omitempty"`
+	ToolPattern   *string    `json:"tool_pattern,omitempty"`
+	Requests      int        `json:"requests"`
+	PeriodSeconds int        `json:"period_seconds"`
+	Burst         int        `json:"burst,omitempty"`    // default: requests
+	PerUser       *bool      `json:"per_user,omitempty"` // default: true
+	Enabled       *bool      `json:"enabled,omitempty"`
+}
+
+// UpdateRateLimitRequest is used for updating an existing rate limit.
+// An empty target_id or tool_pattern clears it.
+type UpdateRateLimitRequest struct {
+	Name          *string `json:"name,omitempty"`
+	Description   *string `json:"description,omitempty"`
+	SubjectType   *string `json:"subject_type,omitempty"`
+	SubjectValue  *string `json:"subject_value,omitempty"`
+	TargetID      *string `json:"target_id,omitempty"`
+	ToolPattern   *string `json:"tool_pattern,omitempty"`
+	Requests      *int    `json:"requests,omitempty"`
+	PeriodSeconds *int    `json:"period_seconds,omitempty"`
+	Burst         *int    `json:"burst,omitempty"`
+	PerUser       *bool   `json:"per_user,omitempty"`
+	Enabled       *bool   `json:"enabled,omitempty"`
+}
+
+// ============================================================================
+// APPROVALS
+// ============================================================================
+
+// Approval is a tools/call parked by a require_approval policy
+type Approval struct {
+	ID             uuid.UUID       `json:"id"`
+	PolicyID       *uuid.UUID      `json:"policy_id,omitempty"`
+	PolicyName     string          `json:"policy_name"`
+	ApproverRole   string          `json:"approver_role"`
+	SessionID      string          `json:"session_id"`
+	UserID         uuid.UUID       `json:"user_id"`
+	UserEmail      string          `json:"user_email,omitempty"`
+	TargetName     string          `json:"target_name,omitempty"`
+	ToolName       string          `json:"tool_name"` // as the client named it
+	Arguments      json.RawMessage `json:"arguments"` // as they will be forwarded
+	Status         string          `json:"status"`
+	DecidedBy      *uuid.UUID      `json:"decided_by,omitempty"`
+	DecidedByEmail *string         `json:"decided_by_email,omitempty"`
+	Reason         *string         `json:"reason,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	ExpiresAt      time.Time       `json:"expires_at"`
+	DecidedAt      *time.Time      `json:"decided_at,omitempty"`
+}
+
+// Approval statuses
+const (
+	ApprovalPending   = "pending"
+	ApprovalApproved  = "approved"
+	ApprovalRejected  = "rejected"
+	ApprovalExpired   = "expired"   // nobody decided before the timeout
+	ApprovalCancelled = "cancelled" // the client went away while waiting
+)
+
+// DecideApprovalRequest is used for approving or rejecting a pending approval
+type DecideApprovalRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// ============================================================================
+// USAGE
+// ============================================================================
+
+// UsageRecord is one tools/call, resources/read or prompts/get forwarded to an
+// upstream target, or answered from the response cache
+type UsageRecord struct {
+	ID            int64      `json:"id"`
+	UserID        *uuid.UUID `json:"user_id,omitempty"`
+	UserEmail     string     `json:"user_email,omitempty"`
+	Role          string     `json:"role,omitempty"`
+	Groups        []string   `json:"groups,omitempty"`
+	TargetID      *uuid.UUID `json:"target_id,omitempty"`
+	TargetName    string     `json:"target_name"`
+	Method        string     `json:"method"`
+	Name          string     `json:"name"` // upstream tool, resource URI or prompt
+	RequestBytes  int        `json:"request_bytes"`
+	ResponseBytes int        `json:"response_bytes"`
+	DurationMS    int        `json:"duration_ms"`
+	Status        string     `json:"status"` // "ok" or "error"
+	Cost          float64    `json:"cost"`
+	Cached        bool       `json:"cached"` // served from the response cache, at no cost
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// CostWeight is what a call to a target, or to one of its tools, costs
+type CostWeight struct {
+	ID        uuid.UUID `json:"id"`
+	TargetID  uuid.UUID `json:"target_id"`
+	ToolName  *string   `json:"tool_name,omitempty"` // NULL = every call to the target
+	Weight    float64   `json:"weight"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DefaultCostWeight is the cost of a call no cost weight covers
+const DefaultCostWeight = 1.0
+
+// CreateCostWeightRequest is used for creating a new cost weight
+type CreateCostWeightRequest struct {
+	TargetID uuid.UUID `json:"target_id"`
+	ToolName *string   `json:"tool_name,omitempty"`
+	Weight   float64   `json:"weight"`
+}
+
+// UpdateCostWeightRequest is used for updating an existing cost weight
+type UpdateCostWeightRequest struct {
+	Weight *float64 `json:"weight,omitempty"`
+}
+
+// UsageQuota caps the cost of calls per day or calendar month (UTC)
+type UsageQuota struct {
+	ID             uuid.UUID  `json:"id"`
+	Name           string     `json:"name"`
+	Description    string     `json:"description,omitempty"`
+	SubjectType    string     `json:"subject_type"`            // "user", "role", "group", "everyone"
+	SubjectValue   *string    `json:"subject_value,omitempty"` // user_id, role name, group name (NULL for "everyone")
+	TargetID       *uuid.UUID `json:"target_id,omitempty"`     // NULL = usage on all targets counts
+	Period         string     `json:"period"`                  // "day" or "month"
+	MaxCost        float64    `json:"max_cost"`
+	WarnThresholds []int      `json:"warn_thresholds"` // percentages of max_cost
+	PerUser        bool       `json:"per_user"`        // each user's usage, or all matching usage together
+	Enabled        bool       `json:"enabled"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Usage quota periods
+const (
+	QuotaPeriodDay   = "day"
+	QuotaPeriodMonth = "month"
+)
+
+// CreateUsageQuotaRequest is used for creating a new usage quota
+type CreateUsageQuotaRequest struct {
+	Name           string     `json:"name"`
+	Description    string     `json:"description,omitempty"`
+	SubjectType    string     `json:"subject_type,omitempty"` // default: "everyone"
+	SubjectValue   *string    `json:"subject_value,omitempty"`
+	TargetID       *uuid.UUID `json:"target_id,omitempty"`
+	Period         string     `json:"period"`
+	MaxCost        float64    `json:"max_cost"`
+	WarnThresholds []int      `json:"warn_thresholds,omitempty"` // default: [80]
+	PerUser        *bool      `json:"per_user,omitempty"`        // default: true
+	Enabled        *bool      `json:"enabled,omitempty"`
+}
+
+// UpdateUsageQuotaRequest is used for updating an existing usage quota.
+// An empty target_id clears it.
+type UpdateUsageQuotaRequest struct {
+	Name           *string  `json:"name,omitempty"`
+	Description    *string  `json:"description,omitempty"`
+	SubjectType    *string  `json:"subject_type,omitempty"`
+	SubjectValue   *string  `json:"subject_value,omitempty"`
+	TargetID       *string  `json:"target_id,omitempty"`
+	Period         *string  `json:"period,omitempty"`
+	MaxCost        *float64 `json:"max_cost,omitempty"`
+	WarnThresholds *[]int   `json:"warn_thresholds,omitempty"`
+	PerUser        *bool    `json:"per_user,omitempty"`
+	Enabled        *bool    `json:"enabled,omitempty"`
+}
+
+// UsageFilter selects usage records; nil fields match everything
+type UsageFilter struct {
+	From     time.Time
+	To       time.Time // zero = now
+	UserID   *uuid.UUID
+	Role     *string
+	Group    *string
+	TargetID *uuid.UUID
+}
+
+// UsageReportRow totals usage records for one key of a report
+type UsageReportRow struct {
+	Key           string  `json:"key"`
+	Calls         int64   `json:"calls"`
+	Errors        int64   `json:"errors"`
+	CachedCalls   int64   `json:"cached_calls"`
+	RequestBytes  int64   `json:"request_bytes"`
+	ResponseBytes int64   `json:"response_bytes"`
+	DurationMS    int64   `json:"duration_ms"`
+	Cost          float64 `json:"cost"`
+}
+
+// Target health statuses
+const (
+	TargetHealthy   = "healthy"
+	TargetUnhealthy = "unhealthy"
+	TargetUnknown   = "unknown" // never checked, or nothing to check
+)
+
+// TargetHealth is the latest health check result of a target
+type TargetHealth struct {
+	TargetID            uuid.UUID        `json:"target_id"`
+	TargetName          string           `json:"target_name"`
+	TransportType       string           `json:"transport_type"`
+	Enabled             bool             `json:"enabled"`
+	Status              string           `json:"status"`
+	LatencyMS           *int             `json:"latency_ms,omitempty"`
+	ToolCount           *int             `json:"tool_count,omitempty"`
+	LastError           *string          `json:"last_error,omitempty"` // most recent failure, kept after recovery
+	ConsecutiveFailures int              `json:"consecutive_failures"`
+	LastCheckedAt       *time.Time       `json:"last_checked_at,omitempty"`
+	LastSuccessAt       *time.Time       `json:"last_success_at,omitempty"`
+	LastFailureAt       *time.Time       `json:"last_failure_at,omitempty"`
+	Endpoints           []EndpointHealth `json:"endpoints,omitempty"` // per endpoint, for targets with several
+}
+
+// EndpointHealth is the latest health check result of one endpoint of a target
+type EndpointHealth struct {
+	URL       string  `json:"url"`
+	Status    string  `json:"status"`
+	LatencyMS *int    `json:"latency_ms,omitempty"`
+	Error     *string `json:"error,omitempty"`
+}
+
+// TargetHealthCheck is the outcome of one health check
+type TargetHealthCheck struct {
+	TargetID  uuid.UUID
+	Status    string
+	LatencyMS *int
+	ToolCount *int
+	Error     *string
+	Endpoints []EndpointHealth
+}
@@ -0,0 +1,41 @@
+package database
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MaxNamespaceLength bounds a target namespace so prefixed names stay short
+const MaxNamespaceLength = 64
+
+// namespacePattern keeps namespaces to lowercase letters, digits and hyphens. None
+// of the allowed tool delimiters can occur in a namespace, so the first delimiter
+// in a prefixed name always ends the namespace.
+var namespacePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+var namespaceInvalidChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// ValidateNamespace checks that a target namespace is well formed
+func ValidateNamespace(namespace string) error {
+	if len(namespace) > MaxNamespaceLength {
+		return fmt.Errorf("namespace must be at most %d characters", MaxNamespaceLength)
+	}
+	if !namespacePattern.MatchString(namespace) {
+		return fmt.Errorf("namespace must consist of lowercase letters, digits and single hyphens")
+	}
+	return nil
+}
+
+// DefaultNamespace derives a namespace from a target name, e.g. "My_GitHub" → "my-github".
+// It must stay in sync with the backfill in 002_target_namespaces.sql.
+func DefaultNamespace(name string) string {
+	namespace := strings.Trim(namespaceInvalidChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if len(namespace) > MaxNamespaceLength {
+		namespace = strings.TrimRight(namespace[:MaxNamespaceLength], "-")
+	}
+	if namespace == "" {
+		return "target"
+	}
+	return namespace
+}
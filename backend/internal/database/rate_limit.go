@@ -0,0 +1,61 @@
+package database
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+// ValidateRateLimit checks a rate limit before it is stored
+func ValidateRateLimit(limit *RateLimit) error {
+	if limit.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	switch limit.SubjectType {
+	case "everyone":
+		if limit.SubjectValue != nil && *limit.SubjectValue != "" {
+			return fmt.Errorf("subject_value is not used with subject_type %q", limit.SubjectType)
+		}
+	case "user", "role", "group":
+		if limit.SubjectValue == nil || *limit.SubjectValue == "" {
+			return fmt.Errorf("subject_value is required for subject_type %q", limit.SubjectType)
+		}
+		if limit.SubjectType == "user" {
+			if _, err := uuid.Parse(*limit.SubjectValue); err != nil {
+				return fmt.Errorf("subject_value must be a user ID for subject_type \"user\"")
+			}
+		}
+	default:
+		return fmt.Errorf("subject_type must be 'user', 'role', 'group' or 'everyone'")
+	}
+	if limit.Requests <= 0 {
+		return fmt.Errorf("requests must be positive")
+	}
+	if limit.PeriodSeconds <= 0 {
+		return fmt.Errorf("period_seconds must be positive")
+	}
+	if limit.Burst <= 0 {
+		return fmt.Errorf("burst must be positive")
+	}
+	if limit.ToolPattern != nil {
+		if _, err := regexp.Compile(*limit.ToolPattern); err != nil {
+			return fmt.Errorf("invalid tool_pattern: %w", err)
+		}
+	}
+	return nil
+}
+
+// RefillRate is how many tokens a rate limit's bucket regains per second
+func (l *RateLimit) RefillRate() float64 {
+	return float64(l.Requests) / float64(l.PeriodSeconds)
+}
+
+// RateLimitBucket is the token bucket of a rate limit for one key: a user ID,
+// or "*" for a bucket shared by all matching calls
+type RateLimitBucket struct {
+	LimitID    uuid.UUID
+	Key        string
+	Capacity   float64
+	RefillRate float64 // tokens regained per second
+}
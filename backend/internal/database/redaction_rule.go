@@ -0,0 +1,28 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/reflow/gateway/internal/redaction"
+)
+
+// ValidateRedactionRule checks a redaction rule before it is stored
+func ValidateRedactionRule(rule *RedactionRule) error {
+	if rule.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	_, err := CompileRedactionRule(rule)
+	return err
+}
+
+// CompileRedactionRule builds the matcher for a stored rule
+func CompileRedactionRule(rule *RedactionRule) (*redaction.Rule, error) {
+	pattern := ""
+	if rule.Pattern != nil {
+		pattern = *rule.Pattern
+	}
+	if rule.Detector != redaction.DetectorRegex && pattern != "" {
+		return nil, fmt.Errorf("pattern is only used with detector %q", redaction.DetectorRegex)
+	}
+	return redaction.Compile(rule.Name, rule.Detector, pattern, rule.Action)
+}
@@ -3,6 +3,8 @@ package database
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,8 +12,9 @@ import (
 )
 
 var (
-	ErrNotFound      = errors.New("not found")
-	ErrAlreadyExists = errors.New("already exists")
+	ErrNotFound       = errors.New("not found")
+	ErrAlreadyExists  = errors.New("already exists")
+	ErrAlreadyDecided = errors.New("already decided")
 )
 
 // Repository provides CRUD operations for all models
@@ -265,35 +268,61 @@ func (r *Repository) CreateTarget(ctx context.Context, req *CreateTargetRequest)
 		port = 8080
 	}
 	healthPath := req.HealthPath
+	argumentValidation := req.ArgumentValidation
+	if argumentValidation == "" {
+		argumentValidation = ArgumentValidationOff
+	}
+	namespace := req.Namespace
+	if namespace == "" {
+		namespace = DefaultNamespace(req.Name)
+	}
+	endpoints := req.Endpoints
+	if endpoints == nil {
+		endpoints = []TargetEndpoint{}
+	}
+	loadBalancing := req.LoadBalancing
+	if loadBalancing == "" {
+		loadBalancing = LoadBalancingRoundRobin
+		if statefulness == "stateful" {
+			loadBalancing = LoadBalancingConsistentHash
+		}
+	}
 
 	target := &Target{
-		ID:                uuid.New(),
-		Name:              req.Name,
-		URL:               req.URL,
-		TransportType:     transportType,
-		Command:           req.Command,
-		Args:              args,
-		Image:             req.Image,
-		Port:              port,
-		HealthPath:        healthPath,
-		Statefulness:      statefulness,
-		IsolationBoundary: isolationBoundary,
-		AuthType:          req.AuthType,
-		AuthHeaderName:    req.AuthHeaderName,
-		Enabled:           true,
-		CreatedAt:         time.Now(),
-		UpdatedAt:         time.Now(),
+		ID:                 uuid.New(),
+		Name:               req.Name,
+		Namespace:          namespace,
+		URL:                req.URL,
+		TransportType:      transportType,
+		Command:            req.Command,
+		Args:               args,
+		Image:              req.Image,
+		Port:               port,
+		HealthPath:         healthPath,
+		Statefulness:       statefulness,
+		IsolationBoundary:  isolationBoundary,
+		ArgumentValidation: argumentValidation,
+		CacheTTLSeconds:    req.CacheTTLSeconds,
+		Endpoints:          endpoints,
+		LoadBalancing:      loadBalancing,
+		AuthType:           req.AuthType,
+		AuthHeaderName:     req.AuthHeaderName,
+		Enabled:            true,
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
 	}
 
 	_, err := r.db.Pool.Exec(ctx, `
 		INSERT INTO targets (id, name, url, transport_type, command, args, image, port, health_path, statefulness, isolation_boundary,
-			auth_type, auth_header_name, enabled, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+			auth_type, auth_header_name, enabled, created_at, updated_at, namespace, argument_validation, cache_ttl_seconds,
+			endpoints, load_balancing)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
 	`, target.ID, target.Name, target.URL, target.TransportType, target.Command, target.Args,
 		target.Image, target.Port, target.HealthPath,
 		target.Statefulness, target.IsolationBoundary,
 		target.AuthType, target.AuthHeaderName,
-		target.Enabled, target.CreatedAt, target.UpdatedAt)
+		target.Enabled, target.CreatedAt, target.UpdatedAt, target.Namespace, target.ArgumentValidation, target.CacheTTLSeconds,
+		target.Endpoints, target.LoadBalancing)
 	if err != nil {
 		if isDuplicateKeyError(err) {
 			return nil, ErrAlreadyExists
@@ -308,10 +337,10 @@ func (r *Repository) CreateTarget(ctx context.Context, req *CreateTargetRequest)
 func (r *Repository) GetTargetByID(ctx context.Context, id uuid.UUID) (*Target, error) {
 	target := &Target{}
 	err := r.db.Pool.QueryRow(ctx, `
-		SELECT id, name, url, transport_type, command, args, image, port, health_path, statefulness, isolation_boundary,
+		SELECT id, name, namespace, argument_validation, cache_ttl_seconds, endpoints, load_balancing, url, transport_type, command, args, image, port, health_path, statefulness, isolation_boundary,
 			auth_type, auth_header_name, enabled, default_encrypted_token, created_at, updated_at
 		FROM targets WHERE id = $1
-	`, id).Scan(&target.ID, &target.Name, &target.URL, &target.TransportType,
+	`, id).Scan(&target.ID, &target.Name, &target.Namespace, &target.ArgumentValidation, &target.CacheTTLSeconds, &target.Endpoints, &target.LoadBalancing, &target.URL, &target.TransportType,
 		&target.Command, &target.Args, &target.Image, &target.Port, &target.HealthPath, &target.Statefulness, &target.IsolationBoundary,
 		&target.AuthType, &target.AuthHeaderName, &target.Enabled, &target.DefaultEncryptedToken,
 		&target.CreatedAt, &target.UpdatedAt)
@@ -328,10 +357,10 @@ func (r *Repository) GetTargetByID(ctx context.Context, id uuid.UUID) (*Target,
 func (r *Repository) GetTargetByName(ctx context.Context, name string) (*Target, error) {
 	target := &Target{}
 	err := r.db.Pool.QueryRow(ctx, `
-		SELECT id, name, url, transport_type, command, args, image, port, health_path, statefulness, isolation_boundary,
+		SELECT id, name, namespace, argument_validation, cache_ttl_seconds, endpoints, load_balancing, url, transport_type, command, args, image, port, health_path, statefulness, isolation_boundary,
 			auth_type, auth_header_name, enabled, default_encrypted_token, created_at, updated_at
 		FROM targets WHERE name = $1
-	`, name).Scan(&target.ID, &target.Name, &target.URL, &target.TransportType,
+	`, name).Scan(&target.ID, &target.Name, &target.Namespace, &target.ArgumentValidation, &target.CacheTTLSeconds, &target.Endpoints, &target.LoadBalancing, &target.URL, &target.TransportType,
 		&target.Command, &target.Args, &target.Image, &target.Port, &target.HealthPath, &target.Statefulness, &target.IsolationBoundary,
 		&target.AuthType, &target.AuthHeaderName, &target.Enabled, &target.DefaultEncryptedToken,
 		&target.CreatedAt, &target.UpdatedAt)
@@ -347,7 +376,7 @@ func (r *Repository) GetTargetByName(ctx context.Context, name string) (*Target,
 // GetAllTargets retrieves all targets
 func (r *Repository) GetAllTargets(ctx context.Context) ([]*Target, error) {
 	rows, err := r.db.Pool.Query(ctx, `
-		SELECT id, name, url, transport_type, command, args, image, port, health_path, statefulness, isolation_boundary,
+		SELECT id, name, namespace, argument_validation, cache_ttl_seconds, endpoints, load_balancing, url, transport_type, command, args, image, port, health_path, statefulness, isolation_boundary,
 			auth_type, auth_header_name, enabled, default_encrypted_token, created_at, updated_at
 		FROM targets ORDER BY name
 	`)
@@ -359,7 +388,7 @@ func (r *Repository) GetAllTargets(ctx context.Context) ([]*Target, error) {
 	var targets []*Target
 	for rows.Next() {
 		target := &Target{}
-		err := rows.Scan(&target.ID, &target.Name, &target.URL, &target.TransportType,
+		err := rows.Scan(&target.ID, &target.Name, &target.Namespace, &target.ArgumentValidation, &target.CacheTTLSeconds, &target.Endpoints, &target.LoadBalancing, &target.URL, &target.TransportType,
 			&target.Command, &target.Args, &target.Image, &target.Port, &target.HealthPath, &target.Statefulness, &target.IsolationBoundary,
 			&target.AuthType, &target.AuthHeaderName, &target.Enabled, &target.DefaultEncryptedToken,
 			&target.CreatedAt, &target.UpdatedAt)
@@ -375,7 +404,7 @@ func (r *Repository) GetAllTargets(ctx context.Context) ([]*Target, error) {
 // GetEnabledTargets retrieves all enabled targets
 func (r *Repository) GetEnabledTargets(ctx context.Context) ([]*Target, error) {
 	rows, err := r.db.Pool.Query(ctx, `
-		SELECT id, name, url, transport_type, command, args, image, port, health_path, statefulness, isolation_boundary,
+		SELECT id, name, namespace, argument_validation, cache_ttl_seconds, endpoints, load_balancing, url, transport_type, command, args, image, port, health_path, statefulness, isolation_boundary,
 			auth_type, auth_header_name, enabled, default_encrypted_token, created_at, updated_at
 		FROM targets WHERE enabled = true ORDER BY name
 	`)
@@ -387,7 +416,7 @@ func (r *Repository) GetEnabledTargets(ctx context.Context) ([]*Target, error) {
 	var targets []*Target
 	for rows.Next() {
 		target := &Target{}
-		err := rows.Scan(&target.ID, &target.Name, &target.URL, &target.TransportType,
+		err := rows.Scan(&target.ID, &target.Name, &target.Namespace, &target.ArgumentValidation, &target.CacheTTLSeconds, &target.Endpoints, &target.LoadBalancing, &target.URL, &target.TransportType,
 			&target.Command, &target.Args, &target.Image, &target.Port, &target.HealthPath, &target.Statefulness, &target.IsolationBoundary,
 			&target.AuthType, &target.AuthHeaderName, &target.Enabled, &target.DefaultEncryptedToken,
 			&target.CreatedAt, &target.UpdatedAt)
@@ -410,6 +439,9 @@ func (r *Repository) UpdateTarget(ctx context.Context, id uuid.UUID, req *Update
 	if req.Name != nil {
 		target.Name = *req.Name
 	}
+	if req.Namespace != nil {
+		target.Namespace = *req.Namespace
+	}
 	if req.URL != nil {
 		target.URL = *req.URL
 	}
@@ -437,6 +469,21 @@ func (r *Repository) UpdateTarget(ctx context.Context, id uuid.UUID, req *Update
 	if req.IsolationBoundary != nil {
 		target.IsolationBoundary = *req.IsolationBoundary
 	}
+	if req.ArgumentValidation != nil {
+		target.ArgumentValidation = *req.ArgumentValidation
+	}
+	if req.CacheTTLSeconds != nil {
+		target.CacheTTLSeconds = *req.CacheTTLSeconds
+	}
+	if req.Endpoints != nil {
+		target.Endpoints = *req.Endpoints
+		if target.Endpoints == nil {
+			target.Endpoints = []TargetEndpoint{}
+		}
+	}
+	if req.LoadBalancing != nil {
+		target.LoadBalancing = *req.LoadBalancing
+	}
 	if req.AuthType != nil {
 		target.AuthType = *req.AuthType
 	}
@@ -451,12 +498,14 @@ func (r *Repository) UpdateTarget(ctx context.Context, id uuid.UUID, req *Update
 	_, err = r.db.Pool.Exec(ctx, `
 		UPDATE targets SET name = $2, url = $3, transport_type = $4, command = $5, args = $6,
 		image = $7, port = $8, health_path = $9, statefulness = $10, isolation_boundary = $11,
-		auth_type = $12, auth_header_name = $13, enabled = $14, updated_at = $15
+		auth_type = $12, auth_header_name = $13, enabled = $14, updated_at = $15, namespace = $16,
+		argument_validation = $17, cache_ttl_seconds = $18, endpoints = $19, load_balancing = $20
 		WHERE id = $1
 	`, id, target.Name, target.URL, target.TransportType, target.Command, target.Args,
 		target.Image, target.Port, target.HealthPath,
 		target.Statefulness, target.IsolationBoundary,
-		target.AuthType, target.AuthHeaderName, target.Enabled, target.UpdatedAt)
+		target.AuthType, target.AuthHeaderName, target.Enabled, target.UpdatedAt, target.Namespace,
+		target.ArgumentValidation, target.CacheTTLSeconds, target.Endpoints, target.LoadBalancing)
 	if err != nil {
 		if isDuplicateKeyError(err) {
 			return nil, ErrAlreadyExists
@@ -929,7 +978,9 @@ func (r *Repository) CreatePolicy(ctx context.Context, req *CreatePolicyRequest)
 		TargetID:        req.TargetID,
 		ResourceType:    req.ResourceType,
 		ResourcePattern: req.ResourcePattern,
+		Condition:       req.Condition,
 		Effect:          req.Effect,
+		ApproverRole:    req.ApproverRole,
 		Priority:        req.Priority,
 		Enabled:         true,
 		CreatedAt:       time.Now(),
@@ -941,10 +992,10 @@ func (r *Repository) CreatePolicy(ctx context.Context, req *CreatePolicyRequest)
 	}
 
 	_, err := r.db.Pool.Exec(ctx, `
-		INSERT INTO authorization_policies (id, name, description, target_id, resource_type, resource_pattern, effect, priority, enabled, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		INSERT INTO authorization_policies (id, name, description, target_id, resource_type, resource_pattern, condition, effect, approver_role, priority, enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 	`, policy.ID, policy.Name, policy.Description, policy.TargetID, policy.ResourceType,
-		policy.ResourcePattern, policy.Effect, policy.Priority, policy.Enabled, policy.CreatedAt, policy.UpdatedAt)
+		policy.ResourcePattern, policy.Condition, policy.Effect, policy.ApproverRole, policy.Priority, policy.Enabled, policy.CreatedAt, policy.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -964,10 +1015,10 @@ func (r *Repository) CreatePolicy(ctx context.Context, req *CreatePolicyRequest)
 func (r *Repository) GetPolicyByID(ctx context.Context, id uuid.UUID) (*AuthorizationPolicy, error) {
 	policy := &AuthorizationPolicy{}
 	err := r.db.Pool.QueryRow(ctx, `
-		SELECT id, name, description, target_id, resource_type, resource_pattern, effect, priority, enabled, created_at, updated_at
+		SELECT id, name, description, target_id, resource_type, resource_pattern, condition, effect, approver_role, priority, enabled, created_at, updated_at
 		FROM authorization_policies WHERE id = $1
 	`, id).Scan(&policy.ID, &policy.Name, &policy.Description, &policy.TargetID, &policy.ResourceType,
-		&policy.ResourcePattern, &policy.Effect, &policy.Priority, &policy.Enabled, &policy.CreatedAt, &policy.UpdatedAt)
+		&policy.ResourcePattern, &policy.Condition, &policy.Effect, &policy.ApproverRole, &policy.Priority, &policy.Enabled, &policy.CreatedAt, &policy.UpdatedAt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrNotFound
@@ -988,7 +1039,7 @@ func (r *Repository) GetPolicyByID(ctx context.Context, id uuid.UUID) (*Authoriz
 // GetAllPolicies retrieves all policies with their subjects
 func (r *Repository) GetAllPolicies(ctx context.Context) ([]*AuthorizationPolicy, error) {
 	rows, err := r.db.Pool.Query(ctx, `
-		SELECT id, name, description, target_id, resource_type, resource_pattern, effect, priority, enabled, created_at, updated_at
+		SELECT id, name, description, target_id, resource_type, resource_pattern, condition, effect, approver_role, priority, enabled, created_at, updated_at
 		FROM authorization_policies ORDER BY priority DESC, name
 	`)
 	if err != nil {
@@ -1000,7 +1051,7 @@ func (r *Repository) GetAllPolicies(ctx context.Context) ([]*AuthorizationPolicy
 	for rows.Next() {
 		policy := &AuthorizationPolicy{}
 		err := rows.Scan(&policy.ID, &policy.Name, &policy.Description, &policy.TargetID, &policy.ResourceType,
-			&policy.ResourcePattern, &policy.Effect, &policy.Priority, &policy.Enabled, &policy.CreatedAt, &policy.UpdatedAt)
+			&policy.ResourcePattern, &policy.Condition, &policy.Effect, &policy.ApproverRole, &policy.Priority, &policy.Enabled, &policy.CreatedAt, &policy.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -1026,14 +1077,14 @@ func (r *Repository) GetPoliciesForTarget(ctx context.Context, targetID *uuid.UU
 
 	if targetID != nil {
 		rows, err = r.db.Pool.Query(ctx, `
-			SELECT id, name, description, target_id, resource_type, resource_pattern, effect, priority, enabled, created_at, updated_at
+			SELECT id, name, description, target_id, resource_type, resource_pattern, condition, effect, approver_role, priority, enabled, created_at, updated_at
 			FROM authorization_policies
 			WHERE enabled = true AND (target_id IS NULL OR target_id = $1)
 			ORDER BY priority DESC
 		`, targetID)
 	} else {
 		rows, err = r.db.Pool.Query(ctx, `
-			SELECT id, name, description, target_id, resource_type, resource_pattern, effect, priority, enabled, created_at, updated_at
+			SELECT id, name, description, target_id, resource_type, resource_pattern, condition, effect, approver_role, priority, enabled, created_at, updated_at
 			FROM authorization_policies
 			WHERE enabled = true AND target_id IS NULL
 			ORDER BY priority DESC
@@ -1048,7 +1099,7 @@ func (r *Repository) GetPoliciesForTarget(ctx context.Context, targetID *uuid.UU
 	for rows.Next() {
 		policy := &AuthorizationPolicy{}
 		err := rows.Scan(&policy.ID, &policy.Name, &policy.Description, &policy.TargetID, &policy.ResourceType,
-			&policy.ResourcePattern, &policy.Effect, &policy.Priority, &policy.Enabled, &policy.CreatedAt, &policy.UpdatedAt)
+			&policy.ResourcePattern, &policy.Condition, &policy.Effect, &policy.ApproverRole, &policy.Priority, &policy.Enabled, &policy.CreatedAt, &policy.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -1089,9 +1140,21 @@ func (r *Repository) UpdatePolicy(ctx context.Context, id uuid.UUID, req *Update
 	if req.ResourcePattern != nil {
 		policy.ResourcePattern = req.ResourcePattern
 	}
+	if req.Condition != nil {
+		policy.Condition = req.Condition
+		if *req.Condition == "" {
+			policy.Condition = nil
+		}
+	}
 	if req.Effect != nil {
 		policy.Effect = *req.Effect
 	}
+	if req.ApproverRole != nil {
+		policy.ApproverRole = req.ApproverRole
+		if *req.ApproverRole == "" {
+			policy.ApproverRole = nil
+		}
+	}
 	if req.Priority != nil {
 		policy.Priority = *req.Priority
 	}
@@ -1103,10 +1166,10 @@ func (r *Repository) UpdatePolicy(ctx context.Context, id uuid.UUID, req *Update
 	_, err = r.db.Pool.Exec(ctx, `
 		UPDATE authorization_policies
 		SET name = $2, description = $3, target_id = $4, resource_type = $5, resource_pattern = $6,
-		    effect = $7, priority = $8, enabled = $9, updated_at = $10
+		    condition = $7, effect = $8, approver_role = $9, priority = $10, enabled = $11, updated_at = $12
 		WHERE id = $1
 	`, id, policy.Name, policy.Description, policy.TargetID, policy.ResourceType,
-		policy.ResourcePattern, policy.Effect, policy.Priority, policy.Enabled, policy.UpdatedAt)
+		policy.ResourcePattern, policy.Condition, policy.Effect, policy.ApproverRole, policy.Priority, policy.Enabled, policy.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -1431,3 +1494,1123 @@ func (r *Repository) ResolveEnvConfigsForTarget(ctx context.Context, targetID uu
 
 	return configs, nil
 }
+
+// ==================== Tool Override Operations ====================
+
+// SetToolOverride creates or replaces the override for one upstream tool of a target
+func (r *Repository) SetToolOverride(ctx context.Context, targetID uuid.UUID, toolName string, req *SetToolOverrideRequest) (*ToolOverride, error) {
+	override := &ToolOverride{
+		TargetID:        targetID,
+		ToolName:        toolName,
+		Name:            req.Name,
+		Description:     req.Description,
+		InputSchema:     req.InputSchema,
+		FixedArguments:  req.FixedArguments,
+		CacheTTLSeconds: req.CacheTTLSeconds,
+	}
+	if override.FixedArguments == nil {
+		override.FixedArguments = map[string]interface{}{}
+	}
+
+	err := r.db.Pool.QueryRow(ctx, `
+		INSERT INTO tool_overrides (id, target_id, tool_name, name, description, input_schema, fixed_arguments, cache_ttl_seconds, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+		ON CONFLICT (target_id, tool_name) DO UPDATE SET
+			name = EXCLUDED.name,
+			description = EXCLUDED.description,
+			input_schema = EXCLUDED.input_schema,
+			fixed_arguments = EXCLUDED.fixed_arguments,
+			cache_ttl_seconds = EXCLUDED.cache_ttl_seconds,
+			updated_at = NOW()
+		RETURNING id, created_at, updated_at
+	`, uuid.New(), targetID, toolName, req.Name, req.Description, []byte(req.InputSchema), override.FixedArguments,
+		req.CacheTTLSeconds,
+	).Scan(&override.ID, &override.CreatedAt, &override.UpdatedAt)
+	if err != nil {
+		if isDuplicateKeyError(err) {
+			return nil, ErrAlreadyExists
+		}
+		return nil, err
+	}
+
+	return override, nil
+}
+
+// GetToolOverride retrieves the override for one upstream tool of a target
+func (r *Repository) GetToolOverride(ctx context.Context, targetID uuid.UUID, toolName string) (*ToolOverride, error) {
+	override := &ToolOverride{}
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT id, target_id, tool_name, name, description, input_schema, fixed_arguments, cache_ttl_seconds, created_at, updated_at
+		FROM tool_overrides
+		WHERE target_id = $1 AND tool_name = $2
+	`, targetID, toolName).Scan(&override.ID, &override.TargetID, &override.ToolName, &override.Name,
+		&override.Description, (*[]byte)(&override.InputSchema), &override.FixedArguments,
+		&override.CacheTTLSeconds, &override.CreatedAt, &override.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return override, nil
+}
+
+// GetToolOverridesForTarget retrieves all tool overrides of a target
+func (r *Repository) GetToolOverridesForTarget(ctx context.Context, targetID uuid.UUID) ([]*ToolOverride, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT id, target_id, tool_name, name, description, input_schema, fixed_arguments, cache_ttl_seconds, created_at, updated_at
+		FROM tool_overrides
+		WHERE target_id = $1
+		ORDER BY tool_name
+	`, targetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var overrides []*ToolOverride
+	for rows.Next() {
+		override := &ToolOverride{}
+		err := rows.Scan(&override.ID, &override.TargetID, &override.ToolName, &override.Name,
+			&override.Description, (*[]byte)(&override.InputSchema), &override.FixedArguments,
+			&override.CacheTTLSeconds, &override.CreatedAt, &override.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, override)
+	}
+
+	return overrides, nil
+}
+
+// DeleteToolOverride removes the override for one upstream tool of a target
+func (r *Repository) DeleteToolOverride(ctx context.Context, targetID uuid.UUID, toolName string) error {
+	result, err := r.db.Pool.Exec(ctx, `
+		DELETE FROM tool_overrides WHERE target_id = $1 AND tool_name = $2
+	`, targetID, toolName)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ==================== Virtual Tool Operations ====================
+
+// CreateVirtualTool creates a new virtual tool
+func (r *Repository) CreateVirtualTool(ctx context.Context, vt *VirtualTool) error {
+	vt.ID = uuid.New()
+	vt.CreatedAt = time.Now()
+	vt.UpdatedAt = vt.CreatedAt
+
+	_, err := r.db.Pool.Exec(ctx, `
+		INSERT INTO virtual_tools (id, name, description, input_schema, steps, output, enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, vt.ID, vt.Name, vt.Description, []byte(vt.InputSchema), vt.Steps, []byte(vt.Output),
+		vt.Enabled, vt.CreatedAt, vt.UpdatedAt)
+	if err != nil {
+		if isDuplicateKeyError(err) {
+			return ErrAlreadyExists
+		}
+		return err
+	}
+	return nil
+}
+
+// GetVirtualToolByID retrieves a virtual tool by ID
+func (r *Repository) GetVirtualToolByID(ctx context.Context, id uuid.UUID) (*VirtualTool, error) {
+	return r.getVirtualTool(ctx, `WHERE id = $1`, id)
+}
+
+// GetVirtualToolByName retrieves a virtual tool by name
+func (r *Repository) GetVirtualToolByName(ctx context.Context, name string) (*VirtualTool, error) {
+	return r.getVirtualTool(ctx, `WHERE name = $1`, name)
+}
+
+func (r *Repository) getVirtualTool(ctx context.Context, where string, arg interface{}) (*VirtualTool, error) {
+	vt := &VirtualTool{}
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT id, name, description, input_schema, steps, output, enabled, created_at, updated_at
+		FROM virtual_tools `+where, arg).Scan(&vt.ID, &vt.Name, &vt.Description, (*[]byte)(&vt.InputSchema),
+		&vt.Steps, (*[]byte)(&vt.Output), &vt.Enabled, &vt.CreatedAt, &vt.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return vt, nil
+}
+
+// ListVirtualTools retrieves all virtual tools, or only enabled ones
+func (r *Repository) ListVirtualTools(ctx context.Context, enabledOnly bool) ([]*VirtualTool, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT id, name, description, input_schema, steps, output, enabled, created_at, updated_at
+		FROM virtual_tools
+		WHERE enabled = true OR NOT $1
+		ORDER BY name
+	`, enabledOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tools []*VirtualTool
+	for rows.Next() {
+		vt := &VirtualTool{}
+		err := rows.Scan(&vt.ID, &vt.Name, &vt.Description, (*[]byte)(&vt.InputSchema),
+			&vt.Steps, (*[]byte)(&vt.Output), &vt.Enabled, &vt.CreatedAt, &vt.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		tools = append(tools, vt)
+	}
+
+	return tools, nil
+}
+
+// UpdateVirtualTool saves every field of an existing virtual tool
+func (r *Repository) UpdateVirtualTool(ctx context.Context, vt *VirtualTool) error {
+	vt.UpdatedAt = time.Now()
+
+	result, err := r.db.Pool.Exec(ctx, `
+		UPDATE virtual_tools
+		SET name = $2, description = $3, input_schema = $4, steps = $5, output = $6, enabled = $7, updated_at = $8
+		WHERE id = $1
+	`, vt.ID, vt.Name, vt.Description, []byte(vt.InputSchema), vt.Steps, []byte(vt.Output),
+		vt.Enabled, vt.UpdatedAt)
+	if err != nil {
+		if isDuplicateKeyError(err) {
+			return ErrAlreadyExists
+		}
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteVirtualTool deletes a virtual tool
+func (r *Repository) DeleteVirtualTool(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.Pool.Exec(ctx, `DELETE FROM virtual_tools WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ============================================================================
+// REDACTION RULES
+// ============================================================================
+
+// CreateRedactionRule creates a new redaction rule
+func (r *Repository) CreateRedactionRule(ctx context.Context, rule *RedactionRule) error {
+	rule.ID = uuid.New()
+	rule.CreatedAt = time.Now()
+	rule.UpdatedAt = rule.CreatedAt
+
+	_, err := r.db.Pool.Exec(ctx, `
+		INSERT INTO redaction_rules (id, name, description, detector, pattern, action, target_id, role, enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`, rule.ID, rule.Name, rule.Description, rule.Detector, rule.Pattern, rule.Action,
+		rule.TargetID, rule.Role, rule.Enabled, rule.CreatedAt, rule.UpdatedAt)
+	if err != nil {
+		if isDuplicateKeyError(err) {
+			return ErrAlreadyExists
+		}
+		return err
+	}
+	return nil
+}
+
+// GetRedactionRuleByID retrieves a redaction rule by ID
+func (r *Repository) GetRedactionRuleByID(ctx context.Context, id uuid.UUID) (*RedactionRule, error) {
+	rule := &RedactionRule{}
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT id, name, description, detector, pattern, action, target_id, role, enabled, created_at, updated_at
+		FROM redaction_rules WHERE id = $1
+	`, id).Scan(&rule.ID, &rule.Name, &rule.Description, &rule.Detector, &rule.Pattern, &rule.Action,
+		&rule.TargetID, &rule.Role, &rule.Enabled, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return rule, nil
+}
+
+// ListRedactionRules retrieves all redaction rules, or only enabled ones
+func (r *Repository) ListRedactionRules(ctx context.Context, enabledOnly bool) ([]*RedactionRule, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT id, name, description, detector, pattern, action, target_id, role, enabled, created_at, updated_at
+		FROM redaction_rules
+		WHERE enabled = true OR NOT $1
+		ORDER BY name
+	`, enabledOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []*RedactionRule
+	for rows.Next() {
+		rule := &RedactionRule{}
+		err := rows.Scan(&rule.ID, &rule.Name, &rule.Description, &rule.Detector, &rule.Pattern, &rule.Action,
+			&rule.TargetID, &rule.Role, &rule.Enabled, &rule.CreatedAt, &rule.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// UpdateRedactionRule saves every field of an existing redaction rule
+func (r *Repository) UpdateRedactionRule(ctx context.Context, rule *RedactionRule) error {
+	rule.UpdatedAt = time.Now()
+
+	result, err := r.db.Pool.Exec(ctx, `
+		UPDATE redaction_rules
+		SET name = $2, description = $3, detector = $4, pattern = $5, action = $6,
+			target_id = $7, role = $8, enabled = $9, updated_at = $10
+		WHERE id = $1
+	`, rule.ID, rule.Name, rule.Description, rule.Detector, rule.Pattern, rule.Action,
+		rule.TargetID, rule.Role, rule.Enabled, rule.UpdatedAt)
+	if err != nil {
+		if isDuplicateKeyError(err) {
+			return ErrAlreadyExists
+		}
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteRedactionRule deletes a redaction rule
+func (r *Repository) DeleteRedactionRule(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.Pool.Exec(ctx, `DELETE FROM redaction_rules WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ============================================================================
+// APPROVALS
+// ============================================================================
+
+const approvalColumns = `id, policy_id, policy_name, approver_role, session_id, user_id, user_email, target_name, tool_name,
+		arguments, status, decided_by, decided_by_email, reason, created_at, expires_at, decided_at`
+
+func scanApproval(row pgx.Row) (*Approval, error) {
+	a := &Approval{}
+	err := row.Scan(&a.ID, &a.PolicyID, &a.PolicyName, &a.ApproverRole, &a.SessionID, &a.UserID, &a.UserEmail, &a.TargetName, &a.ToolName,
+		(*[]byte)(&a.Arguments), &a.Status, &a.DecidedBy, &a.DecidedByEmail, &a.Reason, &a.CreatedAt, &a.ExpiresAt, &a.DecidedAt)
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// CreateApproval records a pending approval
+func (r *Repository) CreateApproval(ctx context.Context, a *Approval) error {
+	a.ID = uuid.New()
+	a.Status = ApprovalPending
+	a.CreatedAt = time.Now()
+	if a.Arguments == nil {
+		a.Arguments = []byte("{}")
+	}
+
+	_, err := r.db.Pool.Exec(ctx, `
+		INSERT INTO approvals (id, policy_id, policy_name, approver_role, session_id, user_id, user_email, target_name, tool_name,
+			arguments, status, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`, a.ID, a.PolicyID, a.PolicyName, a.ApproverRole, a.SessionID, a.UserID, a.UserEmail, a.TargetName, a.ToolName,
+		[]byte(a.Arguments), a.Status, a.CreatedAt, a.ExpiresAt)
+	return err
+}
+
+// GetApprovalByID retrieves an approval by ID
+func (r *Repository) GetApprovalByID(ctx context.Context, id uuid.UUID) (*Approval, error) {
+	a, err := scanApproval(r.db.Pool.QueryRow(ctx, `SELECT `+approvalColumns+` FROM approvals WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return a, nil
+}
+
+// ListApprovals retrieves the most recent approvals, optionally only those with
+// a given status. Unless all is set, only approvals the role may decide or the
+// user requested are returned.
+func (r *Repository) ListApprovals(ctx context.Context, status string, all bool, role string, userID uuid.UUID, limit int) ([]*Approval, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT `+approvalColumns+`
+		FROM approvals
+		WHERE ($1 = '' OR status = $1) AND ($2 OR approver_role = $3 OR user_id = $4)
+		ORDER BY created_at DESC
+		LIMIT $5
+	`, status, all, role, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var approvals []*Approval
+	for rows.Next() {
+		a, err := scanApproval(rows)
+		if err != nil {
+			return nil, err
+		}
+		approvals = append(approvals, a)
+	}
+
+	return approvals, nil
+}
+
+// DecideApproval moves a pending approval to its final status. decidedBy is nil
+// when the gateway expires or cancels it. Returns ErrAlreadyDecided if the
+// approval is no longer pending.
+func (r *Repository) DecideApproval(ctx context.Context, id uuid.UUID, status string, decidedBy *uuid.UUID, decidedByEmail, reason *string) (*Approval, error) {
+	a, err := scanApproval(r.db.Pool.QueryRow(ctx, `
+		UPDATE approvals
+		SET status = $2, decided_by = $3, decided_by_email = $4, reason = $5, decided_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING `+approvalColumns, id, status, decidedBy, decidedByEmail, reason))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			if _, err := r.GetApprovalByID(ctx, id); err != nil {
+				return nil, err
+			}
+			return nil, ErrAlreadyDecided
+		}
+		return nil, err
+	}
+	return a, nil
+}
+
+// ============================================================================
+// RATE LIMITS
+// ============================================================================
+
+// CreateRateLimit creates a new rate limit
+func (r *Repository) CreateRateLimit(ctx context.Context, limit *RateLimit) error {
+	limit.ID = uuid.New()
+	limit.CreatedAt = time.Now()
+	limit.UpdatedAt = limit.CreatedAt
+
+	_, err := r.db.Pool.Exec(ctx, `
+		INSERT INTO rate_limits (id, name, description, subject_type, subject_value, target_id, tool_pattern,
+			requests, period_seconds, burst, per_user, enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`, limit.ID, limit.Name, limit.Description, limit.SubjectType, limit.SubjectValue, limit.TargetID, limit.ToolPattern,
+		limit.Requests, limit.PeriodSeconds, limit.Burst, limit.PerUser, limit.Enabled, limit.CreatedAt, limit.UpdatedAt)
+	if err != nil {
+		if isDuplicateKeyError(err) {
+			return ErrAlreadyExists
+		}
+		return err
+	}
+	return nil
+}
+
+// GetRateLimitByID retrieves a rate limit by ID
+func (r *Repository) GetRateLimitByID(ctx context.Context, id uuid.UUID) (*RateLimit, error) {
+	limit := &RateLimit{}
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT id, name, description, subject_type, subject_value, target_id, tool_pattern,
+			requests, period_seconds, burst, per_user, enabled, created_at, updated_at
+		FROM rate_limits WHERE id = $1
+	`, id).Scan(&limit.ID, &limit.Name, &limit.Description, &limit.SubjectType, &limit.SubjectValue, &limit.TargetID, &limit.ToolPattern,
+		&limit.Requests, &limit.PeriodSeconds, &limit.Burst, &limit.PerUser, &limit.Enabled, &limit.CreatedAt, &limit.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return limit, nil
+}
+
+// ListRateLimits retrieves all rate limits, or only enabled ones
+func (r *Repository) ListRateLimits(ctx context.Context, enabledOnly bool) ([]*RateLimit, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT id, name, description, subject_type, subject_value, target_id, tool_pattern,
+			requests, period_seconds, burst, per_user, enabled, created_at, updated_at
+		FROM rate_limits
+		WHERE enabled = true OR NOT $1
+		ORDER BY name
+	`, enabledOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var limits []*RateLimit
+	for rows.Next() {
+		limit := &RateLimit{}
+		err := rows.Scan(&limit.ID, &limit.Name, &limit.Description, &limit.SubjectType, &limit.SubjectValue, &limit.TargetID, &limit.ToolPattern,
+			&limit.Requests, &limit.PeriodSeconds, &limit.Burst, &limit.PerUser, &limit.Enabled, &limit.CreatedAt, &limit.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		limits = append(limits, limit)
+	}
+
+	return limits, nil
+}
+
+// UpdateRateLimit saves every field of an existing rate limit
+func (r *Repository) UpdateRateLimit(ctx context.Context, limit *RateLimit) error {
+	limit.UpdatedAt = time.Now()
+
+	result, err := r.db.Pool.Exec(ctx, `
+		UPDATE rate_limits
+		SET name = $2, description = $3, subject_type = $4, subject_value = $5, target_id = $6, tool_pattern = $7,
+			requests = $8, period_seconds = $9, burst = $10, per_user = $11, enabled = $12, updated_at = $13
+		WHERE id = $1
+	`, limit.ID, limit.Name, limit.Description, limit.SubjectType, limit.SubjectValue, limit.TargetID, limit.ToolPattern,
+		limit.Requests, limit.PeriodSeconds, limit.Burst, limit.PerUser, limit.Enabled, limit.UpdatedAt)
+	if err != nil {
+		if isDuplicateKeyError(err) {
+			return ErrAlreadyExists
+		}
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteRateLimit deletes a rate limit and its buckets
+func (r *Repository) DeleteRateLimit(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.Pool.Exec(ctx, `DELETE FROM rate_limits WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// TakeRateLimitTokens refills token buckets for the time since they were last
+// used, up to capacity, and takes one token from each if every bucket has one.
+// Otherwise no bucket loses a token. It returns the index of the first bucket
+// without a token, or -1 if tokens were taken, and the tokens that bucket has
+// left. Buckets start full.
+func (r *Repository) TakeRateLimitTokens(ctx context.Context, buckets []RateLimitBucket) (int, float64, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the buckets in a fixed order so concurrent calls cannot deadlock
+	order := make([]int, len(buckets))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		a, b := buckets[order[i]], buckets[order[j]]
+		if a.LimitID != b.LimitID {
+			return a.LimitID.String() < b.LimitID.String()
+		}
+		return a.Key < b.Key
+	})
+
+	tokens := make([]float64, len(buckets))
+	for _, i := range order {
+		b := buckets[i]
+		err := tx.QueryRow(ctx, `
+			INSERT INTO rate_limit_buckets AS b (limit_id, bucket_key, tokens, updated_at)
+			VALUES ($1, $2, $3::float8, NOW())
+			ON CONFLICT (limit_id, bucket_key) DO UPDATE SET
+				tokens = LEAST($3::float8, b.tokens + EXTRACT(EPOCH FROM NOW() - b.updated_at)::float8 * $4::float8),
+				updated_at = NOW()
+			RETURNING tokens
+		`, b.LimitID, b.Key, b.Capacity, b.RefillRate).Scan(&tokens[i])
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	denied := -1
+	for i := range buckets {
+		if tokens[i] < 1 {
+			denied = i
+			break
+		}
+	}
+
+	// Take a token from every bucket only if none is empty
+	for _, i := range order {
+		b := buckets[i]
+		_, err := tx.Exec(ctx, `
+			UPDATE rate_limit_buckets
+			SET tokens = CASE WHEN $3 THEN tokens - 1 ELSE tokens END, allowed = $3
+			WHERE limit_id = $1 AND bucket_key = $2
+		`, b.LimitID, b.Key, denied < 0)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, 0, err
+	}
+	if denied >= 0 {
+		return denied, tokens[denied], nil
+	}
+	return -1, 0, nil
+}
+
+// ============================================================================
+// USAGE
+// ============================================================================
+
+// CreateUsageRecord records a call forwarded to an upstream target or answered
+// from the response cache, and adds its cost to the running totals of the
+// quotas it counts against in the same transaction. A total is locked before
+// the record is written, so seeding it in GetQuotaTotal counts the call either
+// from its record or from the increment, never both.
+func (r *Repository) CreateUsageRecord(ctx context.Context, rec *UsageRecord, totals []QuotaTotal) error {
+	rec.CreatedAt = time.Now()
+	if rec.Groups == nil {
+		rec.Groups = []string{}
+	}
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if rec.Cost > 0 {
+		// Lock the totals in a fixed order so concurrent calls cannot deadlock
+		sorted := append([]QuotaTotal(nil), totals...)
+		sort.Slice(sorted, func(i, j int) bool {
+			a, b := sorted[i], sorted[j]
+			if a.QuotaID != b.QuotaID {
+				return a.QuotaID.String() < b.QuotaID.String()
+			}
+			if a.Key != b.Key {
+				return a.Key < b.Key
+			}
+			return a.PeriodStart.Before(b.PeriodStart)
+		})
+		for _, total := range sorted {
+			// A total that does not exist yet is created unseeded
+			_, err := tx.Exec(ctx, `
+				INSERT INTO usage_quota_totals AS t (quota_id, bucket_key, period_start, cost, seeded)
+				VALUES ($1, $2, $3, $4, FALSE)
+				ON CONFLICT (quota_id, bucket_key, period_start) DO UPDATE SET cost = t.cost + $4
+			`, total.QuotaID, total.Key, total.PeriodStart, rec.Cost)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO usage_records (user_id, user_email, role, groups, target_id, target_name, method, name,
+			request_bytes, response_bytes, duration_ms, status, cost, cached, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING id
+	`, rec.UserID, rec.UserEmail, rec.Role, rec.Groups, rec.TargetID, rec.TargetName, rec.Method, rec.Name,
+		rec.RequestBytes, rec.ResponseBytes, rec.DurationMS, rec.Status, rec.Cost, rec.Cached, rec.CreatedAt).Scan(&rec.ID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// usageFilterSQL renders a usage filter as a WHERE clause with its arguments
+func usageFilterSQL(filter UsageFilter) (string, []interface{}) {
+	to := filter.To
+	if to.IsZero() {
+		to = time.Now()
+	}
+	return `
+		WHERE created_at >= $1 AND created_at < $2
+			AND ($3::uuid IS NULL OR user_id = $3)
+			AND ($4::text IS NULL OR role = $4)
+			AND ($5::text IS NULL OR $5 = ANY(groups))
+			AND ($6::uuid IS NULL OR target_id = $6)
+	`, []interface{}{filter.From, to, filter.UserID, filter.Role, filter.Group, filter.TargetID}
+}
+
+// GetQuotaTotal returns the cost counted against a quota for one bucket key in
+// the period starting at periodStart. The first read of a period seeds the
+// running total from the usage records the filter selects, and drops the
+// totals of earlier periods. Seeding holds the total's row lock, which
+// CreateUsageRecord takes before writing a record, so every call committed
+// before the seed is summed and every later one is added to the seeded total.
+func (r *Repository) GetQuotaTotal(ctx context.Context, quotaID uuid.UUID, key string, periodStart time.Time, filter UsageFilter) (float64, error) {
+	var cost float64
+	var seeded bool
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT cost, seeded FROM usage_quota_totals WHERE quota_id = $1 AND bucket_key = $2 AND period_start = $3
+	`, quotaID, key, periodStart).Scan(&cost, &seeded)
+	if err == nil && seeded {
+		return cost, nil
+	}
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return 0, err
+	}
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO usage_quota_totals (quota_id, bucket_key, period_start, cost, seeded)
+		VALUES ($1, $2, $3, 0, FALSE)
+		ON CONFLICT (quota_id, bucket_key, period_start) DO NOTHING
+	`, quotaID, key, periodStart)
+	if err != nil {
+		return 0, err
+	}
+	err = tx.QueryRow(ctx, `
+		SELECT cost, seeded FROM usage_quota_totals
+		WHERE quota_id = $1 AND bucket_key = $2 AND period_start = $3
+		FOR UPDATE
+	`, quotaID, key, periodStart).Scan(&cost, &seeded)
+	if err != nil {
+		return 0, err
+	}
+	// Another replica may have seeded the total while this one waited for the lock
+	if seeded {
+		return cost, tx.Commit(ctx)
+	}
+
+	where, args := usageFilterSQL(filter)
+	if err := tx.QueryRow(ctx, `SELECT COALESCE(SUM(cost), 0) FROM usage_records`+where, args...).Scan(&cost); err != nil {
+		return 0, err
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE usage_quota_totals SET cost = $4, seeded = TRUE
+		WHERE quota_id = $1 AND bucket_key = $2 AND period_start = $3
+	`, quotaID, key, periodStart, cost)
+	if err != nil {
+		return 0, err
+	}
+	_, err = tx.Exec(ctx, `
+		DELETE FROM usage_quota_totals WHERE quota_id = $1 AND bucket_key = $2 AND period_start < $3
+	`, quotaID, key, periodStart)
+	if err != nil {
+		return 0, err
+	}
+	return cost, tx.Commit(ctx)
+}
+
+// usageReportKeys are the columns a usage report can be grouped by
+var usageReportKeys = map[string]string{
+	"user":   "COALESCE(NULLIF(user_email, ''), user_id::text, '')",
+	"group":  "g",
+	"role":   "role",
+	"target": "target_name",
+	"tool":   "target_name || '/' || name",
+	"method": "method",
+	"day":    "to_char(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD')",
+	"month":  "to_char(created_at AT TIME ZONE 'UTC', 'YYYY-MM')",
+}
+
+// ValidUsageGroupBy reports whether a usage report can be grouped by key
+func ValidUsageGroupBy(key string) bool {
+	_, ok := usageReportKeys[key]
+	return ok
+}
+
+// GetUsageReport totals the usage records a filter selects by groupBy (see
+// ValidUsageGroupBy), most costly first. Grouped by group, a record counts
+// towards each of its user's groups.
+func (r *Repository) GetUsageReport(ctx context.Context, filter UsageFilter, groupBy string) ([]*UsageReportRow, error) {
+	key, ok := usageReportKeys[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("invalid group by: %s", groupBy)
+	}
+	from := "usage_records"
+	if groupBy == "group" {
+		from = "usage_records CROSS JOIN LATERAL unnest(groups) AS g"
+	}
+	where, args := usageFilterSQL(filter)
+
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT `+key+` AS key, COUNT(*), COUNT(*) FILTER (WHERE status <> 'ok'), COUNT(*) FILTER (WHERE cached),
+			COALESCE(SUM(request_bytes), 0), COALESCE(SUM(response_bytes), 0),
+			COALESCE(SUM(duration_ms), 0), COALESCE(SUM(cost), 0)
+		FROM `+from+where+`
+		GROUP BY 1
+		ORDER BY 8 DESC, 1
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var report []*UsageReportRow
+	for rows.Next() {
+		row := &UsageReportRow{}
+		err := rows.Scan(&row.Key, &row.Calls, &row.Errors, &row.CachedCalls, &row.RequestBytes, &row.ResponseBytes,
+			&row.DurationMS, &row.Cost)
+		if err != nil {
+			return nil, err
+		}
+		report = append(report, row)
+	}
+
+	return report, nil
+}
+
+// CreateCostWeight creates a new cost weight
+func (r *Repository) CreateCostWeight(ctx context.Context, weight *CostWeight) error {
+	weight.ID = uuid.New()
+	weight.CreatedAt = time.Now()
+	weight.UpdatedAt = weight.CreatedAt
+
+	_, err := r.db.Pool.Exec(ctx, `
+		INSERT INTO cost_weights (id, target_id, tool_name, weight, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, weight.ID, weight.TargetID, weight.ToolName, weight.Weight, weight.CreatedAt, weight.UpdatedAt)
+	if err != nil {
+		if isDuplicateKeyError(err) {
+			return ErrAlreadyExists
+		}
+		return err
+	}
+	return nil
+}
+
+// GetCostWeightByID retrieves a cost weight by ID
+func (r *Repository) GetCostWeightByID(ctx context.Context, id uuid.UUID) (*CostWeight, error) {
+	weight := &CostWeight{}
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT id, target_id, tool_name, weight, created_at, updated_at
+		FROM cost_weights WHERE id = $1
+	`, id).Scan(&weight.ID, &weight.TargetID, &weight.ToolName, &weight.Weight, &weight.CreatedAt, &weight.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return weight, nil
+}
+
+// ListCostWeights retrieves all cost weights
+func (r *Repository) ListCostWeights(ctx context.Context) ([]*CostWeight, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT id, target_id, tool_name, weight, created_at, updated_at
+		FROM cost_weights
+		ORDER BY target_id, tool_name NULLS FIRST
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var weights []*CostWeight
+	for rows.Next() {
+		weight := &CostWeight{}
+		err := rows.Scan(&weight.ID, &weight.TargetID, &weight.ToolName, &weight.Weight, &weight.CreatedAt, &weight.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		weights = append(weights, weight)
+	}
+
+	return weights, nil
+}
+
+// UpdateCostWeight saves the weight of an existing cost weight
+func (r *Repository) UpdateCostWeight(ctx context.Context, weight *CostWeight) error {
+	weight.UpdatedAt = time.Now()
+
+	result, err := r.db.Pool.Exec(ctx, `
+		UPDATE cost_weights SET weight = $2, updated_at = $3 WHERE id = $1
+	`, weight.ID, weight.Weight, weight.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteCostWeight deletes a cost weight
+func (r *Repository) DeleteCostWeight(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.Pool.Exec(ctx, `DELETE FROM cost_weights WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// CreateUsageQuota creates a new usage quota
+func (r *Repository) CreateUsageQuota(ctx context.Context, quota *UsageQuota) error {
+	quota.ID = uuid.New()
+	quota.CreatedAt = time.Now()
+	quota.UpdatedAt = quota.CreatedAt
+	if quota.WarnThresholds == nil {
+		quota.WarnThresholds = []int{}
+	}
+
+	_, err := r.db.Pool.Exec(ctx, `
+		INSERT INTO usage_quotas (id, name, description, subject_type, subject_value, target_id, period,
+			max_cost, warn_thresholds, per_user, enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`, quota.ID, quota.Name, quota.Description, quota.SubjectType, quota.SubjectValue, quota.TargetID, quota.Period,
+		quota.MaxCost, quota.WarnThresholds, quota.PerUser, quota.Enabled, quota.CreatedAt, quota.UpdatedAt)
+	if err != nil {
+		if isDuplicateKeyError(err) {
+			return ErrAlreadyExists
+		}
+		return err
+	}
+	return nil
+}
+
+// GetUsageQuotaByID retrieves a usage quota by ID
+func (r *Repository) GetUsageQuotaByID(ctx context.Context, id uuid.UUID) (*UsageQuota, error) {
+	quota := &UsageQuota{}
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT id, name, description, subject_type, subject_value, target_id, period,
+			max_cost, warn_thresholds, per_user, enabled, created_at, updated_at
+		FROM usage_quotas WHERE id = $1
+	`, id).Scan(&quota.ID, &quota.Name, &quota.Description, &quota.SubjectType, &quota.SubjectValue, &quota.TargetID, &quota.Period,
+		&quota.MaxCost, &quota.WarnThresholds, &quota.PerUser, &quota.Enabled, &quota.CreatedAt, &quota.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return quota, nil
+}
+
+// ListUsageQuotas retrieves all usage quotas, or only enabled ones
+func (r *Repository) ListUsageQuotas(ctx context.Context, enabledOnly bool) ([]*UsageQuota, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT id, name, description, subject_type, subject_value, target_id, period,
+			max_cost, warn_thresholds, per_user, enabled, created_at, updated_at
+		FROM usage_quotas
+		WHERE enabled = true OR NOT $1
+		ORDER BY name
+	`, enabledOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var quotas []*UsageQuota
+	for rows.Next() {
+		quota := &UsageQuota{}
+		err := rows.Scan(&quota.ID, &quota.Name, &quota.Description, &quota.SubjectType, &quota.SubjectValue, &quota.TargetID, &quota.Period,
+			&quota.MaxCost, &quota.WarnThresholds, &quota.PerUser, &quota.Enabled, &quota.CreatedAt, &quota.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		quotas = append(quotas, quota)
+	}
+
+	return quotas, nil
+}
+
+// UpdateUsageQuota saves every field of an existing usage quota
+func (r *Repository) UpdateUsageQuota(ctx context.Context, quota *UsageQuota) error {
+	quota.UpdatedAt = time.Now()
+	if quota.WarnThresholds == nil {
+		quota.WarnThresholds = []int{}
+	}
+
+	result, err := r.db.Pool.Exec(ctx, `
+		UPDATE usage_quotas
+		SET name = $2, description = $3, subject_type = $4, subject_value = $5, target_id = $6, period = $7,
+			max_cost = $8, warn_thresholds = $9, per_user = $10, enabled = $11, updated_at = $12
+		WHERE id = $1
+	`, quota.ID, quota.Name, quota.Description, quota.SubjectType, quota.SubjectValue, quota.TargetID, quota.Period,
+		quota.MaxCost, quota.WarnThresholds, quota.PerUser, quota.Enabled, quota.UpdatedAt)
+	if err != nil {
+		if isDuplicateKeyError(err) {
+			return ErrAlreadyExists
+		}
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	// The quota may now cover different usage; totals are seeded again
+	_, err = r.db.Pool.Exec(ctx, `DELETE FROM usage_quota_totals WHERE quota_id = $1`, quota.ID)
+	return err
+}
+
+// DeleteUsageQuota deletes a usage quota
+func (r *Repository) DeleteUsageQuota(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.Pool.Exec(ctx, `DELETE FROM usage_quotas WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ============================================================================
+// RESPONSE CACHE
+// ============================================================================
+
+// GetCachedResponse returns an unexpired cached response
+func (r *Repository) GetCachedResponse(ctx context.Context, key string) ([]byte, error) {
+	var value []byte
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT value FROM response_cache WHERE cache_key = $1 AND expires_at > NOW()
+	`, key).Scan(&value)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return value, nil
+}
+
+// SetCachedResponse stores a response until it expires, replacing any previous one
+func (r *Repository) SetCachedResponse(ctx context.Context, key, tag string, value []byte, expiresAt time.Time) error {
+	_, err := r.db.Pool.Exec(ctx, `
+		INSERT INTO response_cache (cache_key, tag, value, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (cache_key) DO UPDATE SET tag = EXCLUDED.tag, value = EXCLUDED.value, expires_at = EXCLUDED.expires_at
+	`, key, tag, value, expiresAt)
+	return err
+}
+
+// DeleteCachedResponses removes every cached response with a tag
+func (r *Repository) DeleteCachedResponses(ctx context.Context, tag string) error {
+	_, err := r.db.Pool.Exec(ctx, `DELETE FROM response_cache WHERE tag = $1`, tag)
+	return err
+}
+
+// DeleteExpiredCachedResponses removes expired cached responses
+func (r *Repository) DeleteExpiredCachedResponses(ctx context.Context) (int64, error) {
+	result, err := r.db.Pool.Exec(ctx, `DELETE FROM response_cache WHERE expires_at <= NOW()`)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+// ============================================================================
+// TARGET HEALTH
+// ============================================================================
+
+const targetHealthColumns = `
+	t.id, t.name, t.transport_type, t.enabled, COALESCE(h.status, 'unknown'), h.latency_ms, h.tool_count,
+	h.last_error, COALESCE(h.consecutive_failures, 0), h.last_checked_at, h.last_success_at, h.last_failure_at,
+	COALESCE(h.endpoints, '[]')`
+
+func scanTargetHealth(row pgx.Row) (*TargetHealth, error) {
+	var health TargetHealth
+	err := row.Scan(&health.TargetID, &health.TargetName, &health.TransportType, &health.Enabled, &health.Status,
+		&health.LatencyMS, &health.ToolCount, &health.LastError, &health.ConsecutiveFailures,
+		&health.LastCheckedAt, &health.LastSuccessAt, &health.LastFailureAt, &health.Endpoints)
+	if err != nil {
+		return nil, err
+	}
+	return &health, nil
+}
+
+// GetTargetHealth returns the health of a target; targets that were never
+// checked are "unknown"
+func (r *Repository) GetTargetHealth(ctx context.Context, targetID uuid.UUID) (*TargetHealth, error) {
+	health, err := scanTargetHealth(r.db.Pool.QueryRow(ctx, `
+		SELECT `+targetHealthColumns+`
+		FROM targets t LEFT JOIN target_health h ON h.target_id = t.id
+		WHERE t.id = $1
+	`, targetID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return health, nil
+}
+
+// ListTargetHealth returns the health of every target
+func (r *Repository) ListTargetHealth(ctx context.Context) ([]*TargetHealth, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT `+targetHealthColumns+`
+		FROM targets t LEFT JOIN target_health h ON h.target_id = t.id
+		ORDER BY t.name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*TargetHealth
+	for rows.Next() {
+		health, err := scanTargetHealth(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, health)
+	}
+	return results, rows.Err()
+}
+
+// RecordTargetHealth stores the outcome of a health check. The last error and
+// tool count of earlier checks are kept when a check has none.
+func (r *Repository) RecordTargetHealth(ctx context.Context, check *TargetHealthCheck) error {
+	failures := 0
+	if check.Status == TargetUnhealthy {
+		failures = 1
+	}
+	endpoints := check.Endpoints
+	if endpoints == nil {
+		endpoints = []EndpointHealth{}
+	}
+	_, err := r.db.Pool.Exec(ctx, `
+		INSERT INTO target_health (target_id, status, latency_ms, tool_count, last_error, consecutive_failures,
+			last_checked_at, last_success_at, last_failure_at, endpoints)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(),
+			CASE WHEN $6 = 0 AND $7 THEN NOW() END,
+			CASE WHEN $6 > 0 THEN NOW() END, $8)
+		ON CONFLICT (target_id) DO UPDATE SET
+			status = EXCLUDED.status,
+			latency_ms = EXCLUDED.latency_ms,
+			tool_count = COALESCE(EXCLUDED.tool_count, target_health.tool_count),
+			last_error = COALESCE(EXCLUDED.last_error, target_health.last_error),
+			consecutive_failures = CASE WHEN EXCLUDED.consecutive_failures > 0 THEN target_health.consecutive_failures + 1 ELSE 0 END,
+			last_checked_at = EXCLUDED.last_checked_at,
+			last_success_at = COALESCE(EXCLUDED.last_success_at, target_health.last_success_at),
+			last_failure_at = COALESCE(EXCLUDED.last_failure_at, target_health.last_failure_at),
+			endpoints = EXCLUDED.endpoints
+	`, check.TargetID, check.Status, check.LatencyMS, check.ToolCount, check.Error, failures, check.Status == TargetHealthy, endpoints)
+	return err
+}
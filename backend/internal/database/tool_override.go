@@ -0,0 +1,67 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+)
+
+// toolNamePattern follows the MCP guidance for tool names
+var toolNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,128}$`)
+
+// ArgumentTemplatePattern matches ${...} placeholders in fixed argument strings
+var ArgumentTemplatePattern = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// ArgumentTemplateVariables are the identity fields fixed arguments may reference
+var ArgumentTemplateVariables = map[string]bool{
+	"user.id":    true,
+	"user.email": true,
+	"user.role":  true,
+}
+
+// ValidateToolName checks that a tool name is usable as an MCP tool name
+func ValidateToolName(name string) error {
+	if !toolNamePattern.MatchString(name) {
+		return fmt.Errorf("tool name must be 1-128 letters, digits, '_', '-' or '.'")
+	}
+	return nil
+}
+
+// ValidateToolOverride checks a tool override before it is stored
+func ValidateToolOverride(req *SetToolOverrideRequest) error {
+	if req.Name != nil {
+		if err := ValidateToolName(*req.Name); err != nil {
+			return err
+		}
+	}
+
+	if len(req.InputSchema) > 0 {
+		var schema map[string]interface{}
+		if err := json.Unmarshal(req.InputSchema, &schema); err != nil || schema == nil {
+			return fmt.Errorf("input_schema must be a JSON object")
+		}
+		if t, ok := schema["type"]; ok && t != "object" {
+			return fmt.Errorf("input_schema must have type \"object\"")
+		}
+	}
+
+	if req.CacheTTLSeconds != nil && *req.CacheTTLSeconds < 0 {
+		return fmt.Errorf("cache_ttl_seconds must not be negative")
+	}
+
+	for name, value := range req.FixedArguments {
+		if name == "" {
+			return fmt.Errorf("fixed argument names must not be empty")
+		}
+		s, ok := value.(string)
+		if !ok {
+			continue
+		}
+		for _, match := range ArgumentTemplatePattern.FindAllStringSubmatch(s, -1) {
+			if !ArgumentTemplateVariables[match[1]] {
+				return fmt.Errorf("fixed argument %q references unknown variable ${%s}", name, match[1])
+			}
+		}
+	}
+	return nil
+}
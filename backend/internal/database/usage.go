@@ -0,0 +1,81 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ValidateUsageQuota checks a usage quota before it is stored
+func ValidateUsageQuota(quota *UsageQuota) error {
+	if quota.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	switch quota.SubjectType {
+	case "everyone":
+		if quota.SubjectValue != nil && *quota.SubjectValue != "" {
+			return fmt.Errorf("subject_value is not used with subject_type %q", quota.SubjectType)
+		}
+	case "user", "role", "group":
+		if quota.SubjectValue == nil || *quota.SubjectValue == "" {
+			return fmt.Errorf("subject_value is required for subject_type %q", quota.SubjectType)
+		}
+		if quota.SubjectType == "user" {
+			if _, err := uuid.Parse(*quota.SubjectValue); err != nil {
+				return fmt.Errorf("subject_value must be a user ID for subject_type \"user\"")
+			}
+		}
+	default:
+		return fmt.Errorf("subject_type must be 'user', 'role', 'group' or 'everyone'")
+	}
+	if quota.Period != QuotaPeriodDay && quota.Period != QuotaPeriodMonth {
+		return fmt.Errorf("period must be 'day' or 'month'")
+	}
+	if quota.MaxCost <= 0 {
+		return fmt.Errorf("max_cost must be positive")
+	}
+	for _, threshold := range quota.WarnThresholds {
+		if threshold <= 0 || threshold > 100 {
+			return fmt.Errorf("warn_thresholds must be percentages between 1 and 100")
+		}
+	}
+	return nil
+}
+
+// QuotaTotal identifies the running total of a usage quota for one key, a user
+// ID or "*" for usage shared by the quota's subject, in the period starting at
+// PeriodStart
+type QuotaTotal struct {
+	QuotaID     uuid.UUID
+	Key         string
+	PeriodStart time.Time
+}
+
+// ValidateCostWeight checks a cost weight before it is stored
+func ValidateCostWeight(weight *CostWeight) error {
+	if weight.TargetID == uuid.Nil {
+		return fmt.Errorf("target_id is required")
+	}
+	if weight.Weight < 0 {
+		return fmt.Errorf("weight must not be negative")
+	}
+	return nil
+}
+
+// PeriodStart returns when the quota period containing t began
+func (q *UsageQuota) PeriodStart(t time.Time) time.Time {
+	t = t.UTC()
+	if q.Period == QuotaPeriodMonth {
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// PeriodEnd returns when the quota period containing t ends and usage resets
+func (q *UsageQuota) PeriodEnd(t time.Time) time.Time {
+	if q.Period == QuotaPeriodMonth {
+		return q.PeriodStart(t).AddDate(0, 1, 0)
+	}
+	return q.PeriodStart(t).AddDate(0, 0, 1)
+}
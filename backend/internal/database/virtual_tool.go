@@ -0,0 +1,94 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/reflow/gateway/internal/workflow"
+)
+
+// MaxWorkflowSteps bounds the number of upstream calls one virtual tool makes
+const MaxWorkflowSteps = 20
+
+var stepIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// ValidateVirtualTool checks a virtual tool definition before it is stored.
+// Step arguments and the output template may only reference the tool's input
+// ($.input...) and the results of earlier steps ($.steps.<id>...).
+func ValidateVirtualTool(vt *VirtualTool) error {
+	if err := ValidateToolName(vt.Name); err != nil {
+		return err
+	}
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal(vt.InputSchema, &schema); err != nil || schema == nil {
+		return fmt.Errorf("input_schema must be a JSON object")
+	}
+	if t, ok := schema["type"]; ok && t != "object" {
+		return fmt.Errorf("input_schema must have type \"object\"")
+	}
+
+	if len(vt.Steps) == 0 {
+		return fmt.Errorf("at least one step is required")
+	}
+	if len(vt.Steps) > MaxWorkflowSteps {
+		return fmt.Errorf("at most %d steps are allowed", MaxWorkflowSteps)
+	}
+
+	earlier := make(map[string]bool, len(vt.Steps))
+	for i, step := range vt.Steps {
+		if !stepIDPattern.MatchString(step.ID) {
+			return fmt.Errorf("step %d: id must be 1-64 letters, digits, '_' or '-'", i+1)
+		}
+		if earlier[step.ID] {
+			return fmt.Errorf("step %q: duplicate id", step.ID)
+		}
+		if err := ValidateToolName(step.Tool); err != nil {
+			return fmt.Errorf("step %q: %w", step.ID, err)
+		}
+		if step.OnError != "" && step.OnError != "abort" && step.OnError != "continue" {
+			return fmt.Errorf("step %q: on_error must be 'abort' or 'continue'", step.ID)
+		}
+		if err := validateReferences(map[string]interface{}(step.Arguments), earlier); err != nil {
+			return fmt.Errorf("step %q: %w", step.ID, err)
+		}
+		earlier[step.ID] = true
+	}
+
+	if len(vt.Output) > 0 {
+		var output interface{}
+		if err := json.Unmarshal(vt.Output, &output); err != nil {
+			return fmt.Errorf("output must be valid JSON")
+		}
+		if err := validateReferences(output, earlier); err != nil {
+			return fmt.Errorf("output: %w", err)
+		}
+	}
+	return nil
+}
+
+// validateReferences checks that every reference in a template is rooted at
+// $.input or at one of the given steps
+func validateReferences(template interface{}, steps map[string]bool) error {
+	paths, err := workflow.References(template)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		switch path[0] {
+		case "input":
+		case "steps":
+			stepID, ok := "", len(path) > 1
+			if ok {
+				stepID, ok = path[1].(string)
+			}
+			if !ok || !steps[stepID] {
+				return fmt.Errorf("%s does not refer to an earlier step", path)
+			}
+		default:
+			return fmt.Errorf("%s must start with $.input or $.steps", path)
+		}
+	}
+	return nil
+}
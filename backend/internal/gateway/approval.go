@@ -0,0 +1,228 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/reflow/gateway/internal/auth"
+	"github.com/reflow/gateway/internal/database"
+	"github.com/reflow/gateway/internal/mcp"
+	"github.com/reflow/gateway/internal/observability"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultApprovalTimeout = 5 * time.Minute
+
+	// approvalPollInterval is how often a parked call checks for a decision.
+	// Decisions are read from the database, so any gateway replica's API can
+	// approve a call parked on another.
+	approvalPollInterval = time.Second
+)
+
+// authorizeToolCall decides a tools/call. It returns the tool error to send if
+// the call is denied, and the require_approval policy that matched, if any.
+func (p *Proxy) authorizeToolCall(ctx context.Context, session *Session, mapping ToolMapping, name string, args map[string]interface{}) (*mcp.ToolCallResult, *database.AuthorizationPolicy) {
+	if p.authorizer == nil {
+		return nil, nil
+	}
+
+	targetID := &mapping.TargetID
+	if mapping.VirtualTool != nil {
+		targetID = nil
+	}
+	input := requestInput(ctx, mapping.TargetName, args)
+	policy, err := p.authorizer.Decide(ctx, session.UserID, session.Role, session.Groups, targetID, "tool", mapping.ToolName, input)
+	if err != nil || policy == nil || policy.Effect == database.EffectDeny {
+		return mcp.NewToolCallError(fmt.Sprintf("Not authorized to call tool: %s", name)), nil
+	}
+	if policy.Effect == database.EffectRequireApproval {
+		return nil, policy
+	}
+	return nil, nil
+}
+
+// awaitApproval parks a tools/call matched by a require_approval policy until an
+// approver decides, the approval expires or the client goes away. It returns nil
+// if the call was approved, or the tool error to send instead.
+func (p *Proxy) awaitApproval(ctx context.Context, session *Session, policy *database.AuthorizationPolicy, mapping ToolMapping, name string, args map[string]interface{}) *mcp.ToolCallResult {
+	ctx, span := tracer.Start(ctx, "Proxy.awaitApproval")
+	defer span.End()
+
+	if p.repo == nil {
+		return mcp.NewToolCallError(fmt.Sprintf("Tool %s requires approval, which is not available", name))
+	}
+
+	rawArgs, err := json.Marshal(args)
+	if err != nil {
+		return mcp.NewToolCallError(fmt.Sprintf("Failed to request approval for tool %s", name))
+	}
+	approverRole := database.DefaultApproverRole
+	if policy.ApproverRole != nil && *policy.ApproverRole != "" {
+		approverRole = *policy.ApproverRole
+	}
+	userEmail, _ := auth.GetUserEmail(ctx)
+	policyID := policy.ID
+
+	approval := &database.Approval{
+		PolicyID:     &policyID,
+		PolicyName:   policy.Name,
+		ApproverRole: approverRole,
+		SessionID:    session.ID,
+		UserID:       session.UserID,
+		UserEmail:    userEmail,
+		TargetName:   mapping.TargetName,
+		ToolName:     name,
+		Arguments:    rawArgs,
+		ExpiresAt:    time.Now().Add(p.config.ApprovalTimeout),
+	}
+	if err := p.repo.CreateApproval(ctx, approval); err != nil {
+		log.Error().Err(err).Str("tool", name).Msg("Failed to create approval")
+		return mcp.NewToolCallError(fmt.Sprintf("Failed to request approval for tool %s", name))
+	}
+
+	log.Info().
+		Str("approval_id", approval.ID.String()).
+		Str("tool", name).
+		Str("policy", policy.Name).
+		Str("approver_role", approverRole).
+		Msg("Tool call waiting for approval")
+	p.emitApproval(approval)
+
+	approval = p.waitForDecision(ctx, approval)
+	p.emitApproval(approval)
+	p.auditApproval(ctx, session, approval, mapping.ToolName)
+
+	switch approval.Status {
+	case database.ApprovalApproved:
+		return nil
+	case database.ApprovalRejected:
+		msg := fmt.Sprintf("Call to tool %s was rejected", name)
+		if approval.DecidedByEmail != nil {
+			msg += " by " + *approval.DecidedByEmail
+		}
+		if approval.Reason != nil && *approval.Reason != "" {
+			msg += ": " + *approval.Reason
+		}
+		return mcp.NewToolCallError(msg)
+	case database.ApprovalExpired:
+		return mcp.NewToolCallError(fmt.Sprintf("Call to tool %s was not approved within %s", name, p.config.ApprovalTimeout))
+	}
+	return mcp.NewToolCallError(fmt.Sprintf("Call to tool %s was cancelled while waiting for approval", name))
+}
+
+// waitForDecision polls a pending approval until it is decided. On timeout or
+// cancellation it marks the approval expired or cancelled itself, unless an
+// approver got there first.
+func (p *Proxy) waitForDecision(ctx context.Context, approval *database.Approval) *database.Approval {
+	ticker := time.NewTicker(approvalPollInterval)
+	defer ticker.Stop()
+	timer := time.NewTimer(time.Until(approval.ExpiresAt))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			current, err := p.repo.GetApprovalByID(ctx, approval.ID)
+			if err != nil {
+				log.Warn().Err(err).Str("approval_id", approval.ID.String()).Msg("Failed to check approval")
+				continue
+			}
+			if current.Status != database.ApprovalPending {
+				return current
+			}
+		case <-timer.C:
+			return p.closeApproval(ctx, approval, database.ApprovalExpired)
+		case <-ctx.Done():
+			return p.closeApproval(context.WithoutCancel(ctx), approval, database.ApprovalCancelled)
+		}
+	}
+}
+
+// closeApproval ends a pending approval with nobody's decision, returning the
+// approver's decision instead if one was recorded in the meantime
+func (p *Proxy) closeApproval(ctx context.Context, approval *database.Approval, status string) *database.Approval {
+	closed, err := p.repo.DecideApproval(ctx, approval.ID, status, nil, nil, nil)
+	if errors.Is(err, database.ErrAlreadyDecided) {
+		closed, err = p.repo.GetApprovalByID(ctx, approval.ID)
+	}
+	if err != nil {
+		log.Error().Err(err).Str("approval_id", approval.ID.String()).Msg("Failed to close approval")
+		approval.Status = status
+		return approval
+	}
+	return closed
+}
+
+func (p *Proxy) emitApproval(approval *database.Approval) {
+	if p.obsHub == nil {
+		return
+	}
+	event := observability.ApprovalEvent{
+		Timestamp:    time.Now(),
+		ApprovalID:   approval.ID.String(),
+		UserID:       approval.UserID.String(),
+		UserEmail:    approval.UserEmail,
+		Target:       approval.TargetName,
+		Tool:         approval.ToolName,
+		Policy:       approval.PolicyName,
+		ApproverRole: approval.ApproverRole,
+		Status:       approval.Status,
+		ExpiresAt:    approval.ExpiresAt,
+	}
+	if approval.DecidedByEmail != nil {
+		event.DecidedBy = *approval.DecidedByEmail
+	}
+	p.obsHub.EmitApproval(event)
+}
+
+// auditApproval records the outcome of an approval, with the approver, in the
+// request audit log
+func (p *Proxy) auditApproval(ctx context.Context, session *Session, approval *database.Approval, toolName string) {
+	entry := map[string]interface{}{
+		"name":            approval.ToolName,
+		"tool":            toolName,
+		"arguments":       approval.Arguments,
+		"approval_id":     approval.ID,
+		"approval_status": approval.Status,
+		"policy":          approval.PolicyName,
+	}
+	if approval.DecidedBy != nil {
+		entry["decided_by"] = approval.DecidedBy
+	}
+	if approval.DecidedByEmail != nil {
+		entry["decided_by_email"] = *approval.DecidedByEmail
+	}
+	if approval.Reason != nil {
+		entry["reason"] = *approval.Reason
+	}
+	body, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	status := http.StatusOK
+	switch approval.Status {
+	case database.ApprovalRejected:
+		status = http.StatusForbidden
+	case database.ApprovalExpired, database.ApprovalCancelled:
+		status = http.StatusRequestTimeout
+	}
+
+	userID := session.UserID
+	reqLog := &database.RequestLog{
+		SessionID:      session.ID,
+		UserID:         &userID,
+		Method:         mcp.MethodToolsCall,
+		TargetName:     approval.TargetName,
+		RequestBody:    body,
+		ResponseStatus: status,
+	}
+	if err := p.repo.CreateRequestLog(context.WithoutCancel(ctx), reqLog); err != nil {
+		log.Error().Err(err).Str("session_id", session.ID).Msg("Failed to record approval outcome")
+	}
+}
@@ -0,0 +1,149 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/reflow/gateway/internal/database"
+	"github.com/reflow/gateway/internal/jsonschema"
+	"github.com/reflow/gateway/internal/mcp"
+	"github.com/rs/zerolog/log"
+)
+
+// maxSchemaLookupPages bounds the tools/list pages read to find an unlisted tool's schema
+const maxSchemaLookupPages = 10
+
+// needsToolSchemas reports whether calls to a target's tools are checked
+// against their input or output schema
+func (p *Proxy) needsToolSchemas(session *Session, targetName string) bool {
+	return p.config.ValidateOutputSchema || session.GetArgumentValidation(targetName) != database.ArgumentValidationOff
+}
+
+// lookupToolSchemas fills in the schemas of a tool that was never listed in the
+// session, as the listing would have, by listing its target's tools. The mapping
+// is kept in the session so later calls skip the lookup. Returns an error if the
+// target cannot be listed or does not list the tool.
+func (p *Proxy) lookupToolSchemas(ctx context.Context, session *Session, name string, mapping *ToolMapping, overrides toolOverrides) error {
+	client := session.GetClient(mapping.TargetName)
+	if client == nil {
+		return fmt.Errorf("target not connected: %s", mapping.TargetName)
+	}
+
+	var cursor *string
+	for page := 0; page < maxSchemaLookupPages; page++ {
+		result, err := client.ListTools(ctx, cursor)
+		if err != nil {
+			return fmt.Errorf("failed to list tools: %w", err)
+		}
+		for _, tool := range result.Tools {
+			if tool.Name != mapping.ToolName {
+				continue
+			}
+			listed, fixedArgs, err := overrides.apply(tool)
+			if err != nil {
+				return err
+			}
+			mapping.InputSchema = listed.InputSchema
+			mapping.SchemaOverride = overrides.overridesSchema(tool.Name)
+			mapping.OutputSchema = tool.OutputSchema
+			mapping.FixedArguments = fixedArgs
+			mapping.CacheTTL = toolCacheTTL(session, mapping.TargetName, overrides[tool.Name], tool.Annotations)
+			session.StoreToolMappings(map[string]ToolMapping{name: *mapping}, false)
+			return nil
+		}
+		if result.NextCursor == nil || *result.NextCursor == "" {
+			break
+		}
+		cursor = result.NextCursor
+	}
+	return fmt.Errorf("target %s does not list tool %s", mapping.TargetName, mapping.ToolName)
+}
+
+// checkArguments validates a tool call's arguments against the inputSchema the
+// client was shown, as configured by the target's argument validation mode. In
+// enforce mode it returns the error result to send instead of forwarding; in
+// warn mode violations are logged and recorded in the audit log. Tools that were
+// never listed in the session are checked against the schema lookupToolSchemas
+// found; CallTool rejects them in enforce mode when there is none. An inputSchema
+// set by a tool override is always enforced, whatever the target's mode.
+func (p *Proxy) checkArguments(ctx context.Context, session *Session, mapping ToolMapping, name string, args map[string]interface{}) *mcp.ToolCallResult {
+	mode := session.GetArgumentValidation(mapping.TargetName)
+	if mapping.SchemaOverride {
+		mode = database.ArgumentValidationEnforce
+	}
+	if mode == database.ArgumentValidationOff || len(mapping.InputSchema) == 0 {
+		return nil
+	}
+
+	raw, err := json.Marshal(args)
+	if err != nil {
+		return nil
+	}
+	err = jsonschema.Validate(mapping.InputSchema, raw)
+	if err == nil {
+		return nil
+	}
+	var verr *jsonschema.ValidationError
+	if !errors.As(err, &verr) {
+		// A schema the validator cannot read is the upstream's problem, not the caller's
+		log.Debug().Err(err).Str("target", mapping.TargetName).Str("tool", mapping.ToolName).Msg("Skipping argument validation")
+		return nil
+	}
+
+	log.Warn().
+		Str("target", mapping.TargetName).
+		Str("tool", mapping.ToolName).
+		Str("mode", mode).
+		Strs("violations", verr.Violations).
+		Msg("Tool arguments do not match inputSchema")
+
+	if mode == database.ArgumentValidationWarn {
+		p.auditArgumentViolations(ctx, session, mapping, name, args, verr.Violations)
+		return nil
+	}
+	return invalidArgumentsResult(name, verr.Violations)
+}
+
+// invalidArgumentsResult lists every offending field, one per line
+func invalidArgumentsResult(name string, violations []string) *mcp.ToolCallResult {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Invalid arguments for tool %s:", name)
+	for _, violation := range violations {
+		b.WriteString("\n- " + violation)
+	}
+	return mcp.NewToolCallError(b.String())
+}
+
+// auditArgumentViolations records a forwarded call whose arguments violated the
+// inputSchema (warn mode) in the request audit log
+func (p *Proxy) auditArgumentViolations(ctx context.Context, session *Session, mapping ToolMapping, name string, args map[string]interface{}, violations []string) {
+	if p.repo == nil {
+		return
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"name":                name,
+		"tool":                mapping.ToolName,
+		"arguments":           args,
+		"argument_violations": violations,
+	})
+	if err != nil {
+		return
+	}
+
+	userID := session.UserID
+	reqLog := &database.RequestLog{
+		SessionID:      session.ID,
+		UserID:         &userID,
+		Method:         mcp.MethodToolsCall,
+		TargetName:     mapping.TargetName,
+		RequestBody:    body,
+		ResponseStatus: http.StatusOK,
+	}
+	if err := p.repo.CreateRequestLog(ctx, reqLog); err != nil {
+		log.Error().Err(err).Str("session_id", session.ID).Msg("Failed to record argument violations")
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"regexp"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/reflow/gateway/internal/condition"
 	"github.com/reflow/gateway/internal/database"
 	"github.com/reflow/gateway/internal/telemetry"
 	"github.com/rs/zerolog/log"
@@ -19,6 +21,8 @@ type Authorizer struct {
 	repo        *database.Repository
 	policyCache map[string][]*database.AuthorizationPolicy
 	cacheMu     sync.RWMutex
+	conditions  map[string]*condition.Expr // compiled policy conditions by source
+	condMu      sync.RWMutex
 }
 
 // NewAuthorizer creates a new authorizer
@@ -26,9 +30,21 @@ func NewAuthorizer(repo *database.Repository) *Authorizer {
 	return &Authorizer{
 		repo:        repo,
 		policyCache: make(map[string][]*database.AuthorizationPolicy),
+		conditions:  make(map[string]*condition.Expr),
 	}
 }
 
+// ConditionInput is what policy conditions are evaluated over when a request is
+// made. Checks without one (such as filtering list results) cannot evaluate
+// conditions: conditional allow policies then match and conditional deny
+// policies do not, so the request itself is checked again with its input.
+type ConditionInput struct {
+	Arguments  map[string]interface{}
+	UserEmail  string
+	TargetName string
+	Time       time.Time
+}
+
 // CanAccessTarget checks if a user can access a specific target
 func (a *Authorizer) CanAccessTarget(ctx context.Context, userID uuid.UUID, role string, groups []string, targetID uuid.UUID) (bool, string, error) {
 	return a.CanAccess(ctx, userID, role, groups, &targetID, "all", "")
@@ -36,6 +52,23 @@ func (a *Authorizer) CanAccessTarget(ctx context.Context, userID uuid.UUID, role
 
 // CanAccess checks if a user can access a specific resource
 func (a *Authorizer) CanAccess(ctx context.Context, userID uuid.UUID, role string, groups []string, targetID *uuid.UUID, resourceType, resourceName string) (bool, string, error) {
+	return a.CanAccessWith(ctx, userID, role, groups, targetID, resourceType, resourceName, nil)
+}
+
+// CanAccessWith checks if a user can make a specific request, evaluating policy
+// conditions over input. A require_approval policy grants access here; callers
+// that park calls for approval use Decide instead.
+func (a *Authorizer) CanAccessWith(ctx context.Context, userID uuid.UUID, role string, groups []string, targetID *uuid.UUID, resourceType, resourceName string, input *ConditionInput) (bool, string, error) {
+	policy, err := a.Decide(ctx, userID, role, groups, targetID, resourceType, resourceName, input)
+	if err != nil || policy == nil {
+		return false, "", err
+	}
+	return policy.Effect != database.EffectDeny, policy.Name, nil
+}
+
+// Decide returns the policy that determines whether a user can make a specific
+// request, or nil if none matches (default deny)
+func (a *Authorizer) Decide(ctx context.Context, userID uuid.UUID, role string, groups []string, targetID *uuid.UUID, resourceType, resourceName string, input *ConditionInput) (*database.AuthorizationPolicy, error) {
 	ctx, span := tracer.Start(ctx, "Authorizer.CanAccess",
 		trace.WithAttributes(
 			attribute.String("authz.resource_type", resourceType),
@@ -47,7 +80,7 @@ func (a *Authorizer) CanAccess(ctx context.Context, userID uuid.UUID, role strin
 	// Load policies for target (and global policies)
 	policies, err := a.loadPolicies(ctx, targetID)
 	if err != nil {
-		return false, "", err
+		return nil, err
 	}
 
 	// Sort by priority (already sorted in query)
@@ -70,13 +103,17 @@ func (a *Authorizer) CanAccess(ctx context.Context, userID uuid.UUID, role strin
 			}
 		}
 
-		// First matching policy determines outcome
-		allowed := policy.Effect == "allow"
-		decision := "deny"
-		if allowed {
-			decision = "allow"
+		// Check condition
+		if policy.Condition != nil && *policy.Condition != "" {
+			vars := conditionVars(userID, role, groups, targetID, resourceType, resourceName, input)
+			if !a.conditionHolds(policy, vars) {
+				continue
+			}
 		}
 
+		// First matching policy determines outcome
+		decision := policy.Effect
+
 		span.SetAttributes(
 			attribute.String("authz.decision", decision),
 			attribute.String("authz.policy_name", policy.Name),
@@ -90,12 +127,12 @@ func (a *Authorizer) CanAccess(ctx context.Context, userID uuid.UUID, role strin
 
 		log.Debug().
 			Str("policy", policy.Name).
-			Bool("allowed", allowed).
+			Str("effect", policy.Effect).
 			Str("resource_type", resourceType).
 			Str("resource_name", resourceName).
 			Msg("Authorization policy matched")
 
-		return allowed, policy.Name, nil
+		return policy, nil
 	}
 
 	// Default deny if no policy matches
@@ -113,7 +150,7 @@ func (a *Authorizer) CanAccess(ctx context.Context, userID uuid.UUID, role strin
 		Str("resource_name", resourceName).
 		Msg("No authorization policy matched, defaulting to deny")
 
-	return false, "", nil
+	return nil, nil
 }
 
 // loadPolicies loads policies for a target (and global policies)
@@ -145,6 +182,88 @@ func (a *Authorizer) loadPolicies(ctx context.Context, targetID *uuid.UUID) ([]*
 	return policies, nil
 }
 
+// conditionHolds evaluates a policy's condition. Without input, allow and
+// require_approval policies match and deny policies do not. A condition that
+// fails to compile or evaluate fails closed: deny and require_approval policies
+// match and allow policies do not.
+func (a *Authorizer) conditionHolds(policy *database.AuthorizationPolicy, vars map[string]interface{}) bool {
+	if vars == nil {
+		return policy.Effect != database.EffectDeny
+	}
+
+	expr, err := a.compileCondition(*policy.Condition)
+	if err == nil {
+		var holds bool
+		if holds, err = expr.Eval(vars); err == nil {
+			return holds
+		}
+	}
+	log.Warn().
+		Err(err).
+		Str("policy", policy.Name).
+		Msg("Policy condition failed to evaluate")
+	return policy.Effect != database.EffectAllow
+}
+
+// compileCondition returns the compiled expression for a condition source
+func (a *Authorizer) compileCondition(source string) (*condition.Expr, error) {
+	a.condMu.RLock()
+	expr, ok := a.conditions[source]
+	a.condMu.RUnlock()
+	if ok {
+		return expr, nil
+	}
+
+	expr, err := condition.Compile(source)
+	if err != nil {
+		return nil, err
+	}
+
+	a.condMu.Lock()
+	a.conditions[source] = expr
+	a.condMu.Unlock()
+	return expr, nil
+}
+
+// conditionVars builds the variables conditions are evaluated over, or nil
+// without input
+func conditionVars(userID uuid.UUID, role string, groups []string, targetID *uuid.UUID, resourceType, resourceName string, input *ConditionInput) map[string]interface{} {
+	if input == nil {
+		return nil
+	}
+
+	args := input.Arguments
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+	groupList := make([]interface{}, len(groups))
+	for i, group := range groups {
+		groupList[i] = group
+	}
+	target := map[string]interface{}{"name": input.TargetName}
+	if targetID != nil {
+		target["id"] = targetID.String()
+	}
+	now := input.Time.UTC()
+
+	return map[string]interface{}{
+		"args": args,
+		"user": map[string]interface{}{
+			"id":     userID.String(),
+			"email":  input.UserEmail,
+			"role":   role,
+			"groups": groupList,
+		},
+		"target":   target,
+		"resource": map[string]interface{}{"type": resourceType, "name": resourceName},
+		"request": map[string]interface{}{
+			"time":    now.Format(time.RFC3339),
+			"hour":    float64(now.Hour()),
+			"weekday": now.Weekday().String(),
+		},
+	}
+}
+
 // matchesSubject checks if a user matches any of the policy's subjects
 func (a *Authorizer) matchesSubject(policy *database.AuthorizationPolicy, userID uuid.UUID, role string, groups []string) bool {
 	for _, subject := range policy.Subjects {
@@ -0,0 +1,71 @@
+package gateway
+
+import (
+	"testing"
+
+	"github.com/reflow/gateway/internal/database"
+)
+
+func TestConditionHolds(t *testing.T) {
+	vars := map[string]interface{}{
+		"args": map[string]interface{}{"database": "staging", "limit": float64(50)},
+	}
+
+	tests := []struct {
+		name      string
+		condition string
+		vars      map[string]interface{}
+		want      map[string]bool // by effect
+	}{
+		{
+			name:      "holds",
+			condition: `args.database == "staging"`,
+			vars:      vars,
+			want:      map[string]bool{database.EffectAllow: true, database.EffectDeny: true, database.EffectRequireApproval: true},
+		},
+		{
+			name:      "does not hold",
+			condition: `args.database == "production"`,
+			vars:      vars,
+			want:      map[string]bool{database.EffectAllow: false, database.EffectDeny: false, database.EffectRequireApproval: false},
+		},
+		{
+			// Filtering list results has no request to evaluate
+			name:      "without input",
+			condition: `args.database == "production"`,
+			vars:      nil,
+			want:      map[string]bool{database.EffectAllow: true, database.EffectDeny: false, database.EffectRequireApproval: true},
+		},
+		{
+			name:      "fails to evaluate",
+			condition: `args.limit > "10"`,
+			vars:      vars,
+			want:      map[string]bool{database.EffectAllow: false, database.EffectDeny: true, database.EffectRequireApproval: true},
+		},
+		{
+			name:      "not a boolean",
+			condition: `args.limit`,
+			vars:      vars,
+			want:      map[string]bool{database.EffectAllow: false, database.EffectDeny: true, database.EffectRequireApproval: true},
+		},
+		{
+			name:      "fails to compile",
+			condition: `env.HOME == "/root"`,
+			vars:      vars,
+			want:      map[string]bool{database.EffectAllow: false, database.EffectDeny: true, database.EffectRequireApproval: true},
+		},
+	}
+
+	a := NewAuthorizer(nil)
+	for _, tt := range tests {
+		for effect, want := range tt.want {
+			t.Run(tt.name+"/"+effect, func(t *testing.T) {
+				condition := tt.condition
+				policy := &database.AuthorizationPolicy{Name: "test", Effect: effect, Condition: &condition}
+				if got := a.conditionHolds(policy, tt.vars); got != want {
+					t.Errorf("conditionHolds(%s policy, %q) = %v, want %v", effect, tt.condition, got, want)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,319 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reflow/gateway/internal/database"
+	"github.com/reflow/gateway/internal/mcp"
+	"github.com/reflow/gateway/internal/observability"
+	"github.com/reflow/gateway/internal/stdio"
+	"github.com/reflow/gateway/internal/telemetry"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	otelmetric "go.opentelemetry.io/otel/metric"
+)
+
+// upstreamEndpoint is one replica of an HTTP, SSE or WebSocket target
+type upstreamEndpoint struct {
+	url    string
+	weight int
+
+	outstanding atomic.Int64 // requests in flight, for least_outstanding
+	probeFailed atomic.Bool  // the latest health check of this endpoint failed
+
+	current int // smooth weighted round-robin state, guarded by the pool's mu
+}
+
+// endpointPool holds a target's endpoints. Entries outlive target updates that
+// keep their URL, so in-flight counts and round-robin state carry over.
+type endpointPool struct {
+	mu        sync.Mutex
+	endpoints []*upstreamEndpoint
+}
+
+// sync replaces the pool's endpoints with a target's current ones
+func (pool *endpointPool) sync(endpoints []database.TargetEndpoint) {
+	existing := make(map[string]*upstreamEndpoint, len(pool.endpoints))
+	for _, e := range pool.endpoints {
+		existing[e.url] = e
+	}
+
+	pool.endpoints = pool.endpoints[:0:0]
+	for _, te := range endpoints {
+		e, ok := existing[te.URL]
+		if !ok {
+			e = &upstreamEndpoint{url: te.URL}
+		}
+		e.weight = max(te.Weight, 1)
+		pool.endpoints = append(pool.endpoints, e)
+	}
+}
+
+// find returns the endpoint with a URL, or nil
+func (pool *endpointPool) find(url string) *upstreamEndpoint {
+	for _, e := range pool.endpoints {
+		if e.url == url {
+			return e
+		}
+	}
+	return nil
+}
+
+// order returns the pool's endpoints in the order a new connection tries them.
+// Caller must hold pool.mu.
+func (pool *endpointPool) order(strategy, key string) []*upstreamEndpoint {
+	ordered := append([]*upstreamEndpoint(nil), pool.endpoints...)
+	if len(ordered) < 2 {
+		return ordered
+	}
+
+	switch strategy {
+	case database.LoadBalancingLeastOutstanding:
+		// Shuffle first so endpoints with equal load share new sessions
+		rand.Shuffle(len(ordered), func(i, j int) { ordered[i], ordered[j] = ordered[j], ordered[i] })
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return float64(ordered[i].outstanding.Load())/float64(ordered[i].weight) <
+				float64(ordered[j].outstanding.Load())/float64(ordered[j].weight)
+		})
+	case database.LoadBalancingConsistentHash:
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return rendezvousScore(key, ordered[i]) > rendezvousScore(key, ordered[j])
+		})
+	default:
+		// Smooth weighted round-robin: the pick goes first, the rest follow in
+		// configuration order
+		var picked int
+		total := 0
+		for i, e := range ordered {
+			e.current += e.weight
+			total += e.weight
+			if e.current > ordered[picked].current {
+				picked = i
+			}
+		}
+		ordered[picked].current -= total
+		ordered = append(ordered[picked:], ordered[:picked]...)
+	}
+	return ordered
+}
+
+// rendezvousScore ranks an endpoint for a key by weighted rendezvous hashing:
+// each key prefers the endpoint with the highest score, and adding or removing
+// an endpoint only moves the keys that preferred it
+func rendezvousScore(key string, e *upstreamEndpoint) float64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	h.Write([]byte{0})
+	h.Write([]byte(e.url))
+	// FNV-1a barely mixes the last bytes into the high bits, and endpoint URLs
+	// often differ only there, so finish with MurmurHash3's fmix64
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	// Map the hash into (0, 1)
+	u := (float64(x>>11) + 0.5) / (1 << 53)
+	return -float64(e.weight) / math.Log(u)
+}
+
+// endpointPools holds the endpoint pool of every target
+type endpointPools struct {
+	mu    sync.Mutex
+	pools map[uuid.UUID]*endpointPool
+}
+
+// get returns a target's endpoint pool, synced with its endpoints
+func (s *endpointPools) get(target *database.Target) *endpointPool {
+	s.mu.Lock()
+	if s.pools == nil {
+		s.pools = make(map[uuid.UUID]*endpointPool)
+	}
+	pool, ok := s.pools[target.ID]
+	if !ok {
+		pool = &endpointPool{}
+		s.pools[target.ID] = pool
+	}
+	s.mu.Unlock()
+
+	pool.mu.Lock()
+	pool.sync(target.UpstreamEndpoints())
+	pool.mu.Unlock()
+	return pool
+}
+
+// endpointBreakerKey keys the circuit breaker of one endpoint of a target
+func endpointBreakerKey(targetID uuid.UUID, url string) string {
+	return targetID.String() + "@" + url
+}
+
+// endpointCandidates returns a target's endpoints in the order a session tries
+// them: the one the load balancing strategy picks first, then the others as
+// fallbacks. Endpoints whose circuit breaker is open or whose latest health
+// check failed go last. Stateful targets keep a session on the endpoint it was
+// pinned to, as long as that endpoint is available.
+func (p *Proxy) endpointCandidates(session *Session, target *database.Target) []*upstreamEndpoint {
+	key := ""
+	if target.LoadBalancing == database.LoadBalancingConsistentHash {
+		key = stdio.ComputeSubjectKey(target, session.UserID.String(), session.Role, session.Groups)
+	}
+
+	pool := p.pools.get(target)
+	pool.mu.Lock()
+	ordered := pool.order(target.LoadBalancing, key)
+	pool.mu.Unlock()
+
+	if target.Statefulness == "stateful" {
+		if pinned, ok := session.GetEndpoint(target.Name); ok {
+			for i, e := range ordered {
+				if e.url == pinned {
+					ordered = append(append([]*upstreamEndpoint{e}, ordered[:i]...), ordered[i+1:]...)
+					break
+				}
+			}
+		}
+	}
+
+	candidates := make([]*upstreamEndpoint, 0, len(ordered))
+	var unavailable []*upstreamEndpoint
+	for _, e := range ordered {
+		if e.probeFailed.Load() || !p.breakers.get(endpointBreakerKey(target.ID, e.url)).available(p.config.BreakerCooldown) {
+			unavailable = append(unavailable, e)
+			continue
+		}
+		candidates = append(candidates, e)
+	}
+	return append(candidates, unavailable...)
+}
+
+// markEndpointProbe records the health check result of a target's endpoint
+func (p *Proxy) markEndpointProbe(target *database.Target, url string, failed bool) {
+	pool := p.pools.get(target)
+	pool.mu.Lock()
+	e := pool.find(url)
+	pool.mu.Unlock()
+	if e != nil {
+		e.probeFailed.Store(failed)
+	}
+}
+
+// connectEndpoint connects a session to a target's first endpoint, in the
+// order of endpointCandidates, that initializes. Endpoints that cannot be
+// reached are failed over; an answer from the upstream, such as rejected
+// credentials, would be the same from every endpoint and is returned. The
+// endpoint with URL skip, if any, is not tried.
+func (p *Proxy) connectEndpoint(ctx context.Context, session *Session, target *database.Target, params *mcp.InitializeParams, skip string) (*resilientClient, *mcp.InitializeResult, error) {
+	var lastErr error
+	var failed *upstreamEndpoint
+	for _, endpoint := range p.endpointCandidates(session, target) {
+		if endpoint.url == skip {
+			continue
+		}
+		if failed != nil {
+			p.reportFailover(ctx, target.Name, failed.url, endpoint.url, lastErr)
+		}
+
+		upstream, err := p.createHTTPClient(ctx, session, target, endpoint.url)
+		if err != nil {
+			return nil, nil, err
+		}
+		client := p.withResilience(target.ID, target.Name, endpoint, upstream)
+		result, err := client.Initialize(ctx, params)
+		if err == nil {
+			session.SetEndpoint(target.Name, endpoint.url)
+			return client, result, nil
+		}
+		client.Close()
+
+		lastErr = err
+		if ctx.Err() != nil || isUpstreamAnswer(err) {
+			break
+		}
+		failed = endpoint
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no other endpoint of target %s to fail over to", target.Name)
+	}
+	return nil, nil, lastErr
+}
+
+// endpointFailover returns the failover of a session's connection to a
+// stateless target: it connects to another endpoint, moves the session's
+// notification handlers and resource subscriptions over, and closes the
+// failed connection. Requests failing together fail over once.
+func (p *Proxy) endpointFailover(session *Session, target *database.Target, params *mcp.InitializeParams) func(ctx context.Context, failed *resilientClient, cause error) (*resilientClient, error) {
+	var mu sync.Mutex
+	var failover func(ctx context.Context, failed *resilientClient, cause error) (*resilientClient, error)
+	failover = func(ctx context.Context, failed *resilientClient, cause error) (*resilientClient, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		current, ok := session.GetClient(target.Name).(*resilientClient)
+		if !ok {
+			return nil, fmt.Errorf("session is no longer connected to target %s", target.Name)
+		}
+		if current != failed {
+			// Another request failed over already, or the session reconnected
+			return current, nil
+		}
+
+		next, _, err := p.connectEndpoint(ctx, session, target, params, failed.endpoint.url)
+		if err != nil {
+			return nil, err
+		}
+		p.subscribeNotifications(session, target.Name, next)
+		p.subscribeRequests(session, target.Name, next)
+		for _, uri := range session.SubscribedURIs(target.Name) {
+			if err := next.SubscribeResource(ctx, session.ID, uri); err != nil {
+				log.Warn().Err(err).Str("target", target.Name).Str("uri", uri).Msg("Failed to resubscribe resource after failover")
+			}
+		}
+		next.failover = failover
+		session.SetClient(target.Name, next)
+
+		failed.RemoveNotificationHandler(session.ID)
+		failed.RemoveRequestHandler(session.ID)
+		go failed.Close()
+
+		p.reportFailover(ctx, target.Name, failed.endpoint.url, next.endpoint.url, cause)
+		return next, nil
+	}
+	return failover
+}
+
+// reportFailover logs a session moving from one endpoint of a target to
+// another and surfaces it as an error event on the observability hub and as a
+// metric
+func (p *Proxy) reportFailover(ctx context.Context, target, from, to string, cause error) {
+	telemetry.MCPEndpointFailovers.Add(ctx, 1,
+		otelmetric.WithAttributes(
+			attribute.String("target", target),
+			attribute.String("endpoint", from),
+		),
+	)
+
+	msg := fmt.Sprintf("Target %s failed over from %s to %s", target, from, to)
+	if cause != nil {
+		msg += ": " + cause.Error()
+	}
+	log.Warn().Str("target", target).Str("from", from).Str("to", to).Msg(msg)
+
+	if p.obsHub != nil {
+		p.obsHub.EmitError(observability.ErrorEvent{
+			Timestamp: time.Now(),
+			Target:    target,
+			ErrorType: "endpoint_failover",
+			Message:   msg,
+		})
+	}
+}
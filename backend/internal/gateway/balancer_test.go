@@ -0,0 +1,142 @@
+package gateway
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/reflow/gateway/internal/database"
+)
+
+func testPool(weights ...int) *endpointPool {
+	pool := &endpointPool{}
+	endpoints := make([]database.TargetEndpoint, len(weights))
+	for i, weight := range weights {
+		endpoints[i] = database.TargetEndpoint{URL: string(rune('a' + i)), Weight: weight}
+	}
+	pool.sync(endpoints)
+	return pool
+}
+
+func TestRoundRobinOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		weights []int
+		want    string // first endpoint of each successive order
+	}{
+		{"equal weights", []int{1, 1, 1}, "abcabc"},
+		{"weighted", []int{2, 1}, "abaaba"},
+		{"smooth", []int{5, 1, 1}, "aabacaaaabacaa"},
+		{"zero weight counts as one", []int{0, 1}, "abab"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := testPool(tt.weights...)
+			var got strings.Builder
+			for range tt.want {
+				ordered := pool.order(database.LoadBalancingRoundRobin, "")
+				if len(ordered) != len(tt.weights) {
+					t.Fatalf("order() returned %d endpoints, want %d", len(ordered), len(tt.weights))
+				}
+				got.WriteString(ordered[0].url)
+			}
+			if got.String() != tt.want {
+				t.Errorf("picks = %s, want %s", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRoundRobinDistribution(t *testing.T) {
+	weights := []int{3, 2, 1}
+	pool := testPool(weights...)
+
+	picks := make(map[string]int)
+	for i := 0; i < 600; i++ {
+		picks[pool.order(database.LoadBalancingRoundRobin, "")[0].url]++
+	}
+	for i, weight := range weights {
+		url := string(rune('a' + i))
+		if want := 100 * weight; picks[url] != want {
+			t.Errorf("endpoint %s picked %d times, want %d", url, picks[url], want)
+		}
+	}
+}
+
+// preferred maps each key to the endpoint consistent hashing picks for it
+func preferred(pool *endpointPool, keys []string) map[string]string {
+	picks := make(map[string]string, len(keys))
+	for _, key := range keys {
+		picks[key] = pool.order(database.LoadBalancingConsistentHash, key)[0].url
+	}
+	return picks
+}
+
+func TestConsistentHashStability(t *testing.T) {
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("user-%d", i)
+	}
+
+	three := preferred(testPool(1, 1, 1), keys)
+	if again := preferred(testPool(1, 1, 1), keys); fmt.Sprint(again) != fmt.Sprint(three) {
+		t.Fatal("the same key picked different endpoints")
+	}
+
+	// Removing c only moves the keys that preferred c
+	two := preferred(testPool(1, 1), keys)
+	// Adding d only moves keys to d
+	four := preferred(testPool(1, 1, 1, 1), keys)
+
+	moved := 0
+	for _, key := range keys {
+		if three[key] != "c" && two[key] != three[key] {
+			t.Errorf("removing c moved %s from %s to %s", key, three[key], two[key])
+		}
+		if four[key] != three[key] {
+			moved++
+			if four[key] != "d" {
+				t.Errorf("adding d moved %s from %s to %s", key, three[key], four[key])
+			}
+		}
+	}
+	if moved == 0 {
+		t.Error("adding d moved no keys")
+	}
+}
+
+func TestConsistentHashWeights(t *testing.T) {
+	tests := []struct {
+		name    string
+		weights []int
+	}{
+		{"equal", []int{1, 1, 1}},
+		{"weighted", []int{1, 3}},
+		{"uneven", []int{5, 1, 2}},
+	}
+
+	const keys = 10000
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := testPool(tt.weights...)
+			picks := make(map[string]int)
+			for i := 0; i < keys; i++ {
+				picks[pool.order(database.LoadBalancingConsistentHash, fmt.Sprintf("user-%d", i))[0].url]++
+			}
+
+			total := 0
+			for _, weight := range tt.weights {
+				total += weight
+			}
+			for i, weight := range tt.weights {
+				url := string(rune('a' + i))
+				share, want := float64(picks[url])/keys, float64(weight)/float64(total)
+				if math.Abs(share-want) > 0.03 {
+					t.Errorf("endpoint %s got %.3f of the keys, want about %.3f", url, share, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reflow/gateway/internal/mcp"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxBatchConcurrency bounds how many elements of one batch are dispatched at once
+const maxBatchConcurrency = 8
+
+// isBatch reports whether a POST body is a JSON-RPC batch (a JSON array)
+func isBatch(body []byte) bool {
+	trimmed := bytes.TrimLeft(body, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
+
+// batchingSupported reports whether a protocol revision allows JSON-RPC batches.
+// Batching was added in 2025-03-26 and removed again in 2025-06-18.
+func batchingSupported(version string) bool {
+	return version == mcp.ProtocolVersion20250326
+}
+
+// handleBatch handles a JSON-RPC batch POSTed to an existing session.
+//
+// Elements are dispatched concurrently, at most maxBatchConcurrency at a time,
+// and each is recorded in metrics and the audit log on its own. Responses are
+// returned in element order; notifications and client responses produce none,
+// so a batch made only of those is answered with 202 Accepted. initialize
+// cannot be batched because it creates the session the batch runs in, and
+// sessions on a revision without batching get Invalid Request.
+func (h *Handler) handleBatch(w http.ResponseWriter, r *http.Request, ctx context.Context, userID uuid.UUID, body []byte, startTime time.Time) {
+	var elements []json.RawMessage
+	if err := json.Unmarshal(body, &elements); err != nil {
+		h.writeJSONRPCError(w, nil, mcp.ParseError, "Invalid JSON")
+		return
+	}
+	if len(elements) == 0 {
+		h.writeJSONRPCError(w, nil, mcp.InvalidRequest, "Empty batch")
+		return
+	}
+
+	ctx, span := tracer.Start(ctx, "handleBatch",
+		trace.WithAttributes(
+			attribute.Int("mcp.batch_size", len(elements)),
+			attribute.String("mcp.user_id", userID.String()),
+		),
+	)
+	defer span.End()
+
+	injectTraceID(ctx)
+
+	log.Debug().
+		Int("batch_size", len(elements)).
+		Str("user_id", userID.String()).
+		Msg("Received MCP batch")
+
+	sessionID := r.Header.Get("Mcp-Session-Id")
+	if sessionID == "" {
+		sessionID = r.URL.Query().Get("session_id")
+	}
+
+	session := h.sessionForRequest(w, r, ctx, userID, sessionID, nil)
+	if session == nil {
+		return
+	}
+	if !batchingSupported(session.ProtocolVersion()) {
+		h.writeJSONRPCError(w, nil, mcp.InvalidRequest, "Batches are not supported in protocol version "+session.ProtocolVersion())
+		return
+	}
+
+	batch := h.dispatchBatch(ctx, session, userID, elements)
+
+	log.Debug().
+		Str("session_id", session.ID).
+		Int("batch_size", len(elements)).
+		Int("responses", len(batch)).
+		Dur("duration", time.Since(startTime)).
+		Msg("Handled MCP batch")
+
+	if len(batch) == 0 {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	w.Header().Set("Mcp-Session-Id", session.ID)
+	h.writeJSON(w, batch)
+}
+
+// dispatchBatch dispatches the elements of a batch concurrently and returns the
+// responses in element order, leaving out elements that produce none.
+func (h *Handler) dispatchBatch(ctx context.Context, session *Session, userID uuid.UUID, elements []json.RawMessage) []*mcp.JSONRPCResponse {
+	responses := make([]*mcp.JSONRPCResponse, len(elements))
+	sem := make(chan struct{}, maxBatchConcurrency)
+	var wg sync.WaitGroup
+
+	for i, element := range elements {
+		var req mcp.JSONRPCRequest
+		if err := json.Unmarshal(element, &req); err != nil {
+			responses[i] = mcp.NewErrorResponse(nil, mcp.InvalidRequest, "Invalid request")
+			continue
+		}
+		if req.Method == mcp.MethodInitialize {
+			responses[i] = mcp.NewErrorResponse(req.ID, mcp.InvalidRequest, "initialize must not be part of a batch")
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, req mcp.JSONRPCRequest, element []byte) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			elementStart := time.Now()
+			resp, ok := h.dispatchMessage(ctx, session, &req, element)
+			responses[i] = resp
+			if ok {
+				h.recordRequest(ctx, session.ID, userID, req.Method, element, elementStart)
+			}
+		}(i, req, element)
+	}
+	wg.Wait()
+
+	batch := make([]*mcp.JSONRPCResponse, 0, len(responses))
+	for _, resp := range responses {
+		if resp != nil {
+			batch = append(batch, resp)
+		}
+	}
+	return batch
+}
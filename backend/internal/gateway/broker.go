@@ -0,0 +1,235 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reflow/gateway/internal/mcp"
+	"github.com/rs/zerolog/log"
+)
+
+// serverRequestTimeout bounds how long an upstream server waits for the client
+// to answer. Elicitation puts a human in the loop, so this is generous.
+const serverRequestTimeout = 5 * time.Minute
+
+// serverRequestKinds maps the server-initiated requests the gateway relays to the
+// policy resource type that governs them.
+var serverRequestKinds = map[string]string{
+	mcp.MethodSamplingCreateMessage: "sampling",
+	mcp.MethodElicitationCreate:     "elicitation",
+	mcp.MethodRootsList:             "roots",
+}
+
+// RequestBroker carries requests initiated by upstream servers (sampling,
+// elicitation, roots) to the client session over its notification stream and
+// correlates the client's answers back to the originating target.
+type RequestBroker struct {
+	authorizer *Authorizer
+
+	mu      sync.Mutex
+	pending map[string]*pendingServerRequest // gateway request ID -> waiter
+}
+
+// pendingServerRequest is a relayed request waiting for the client's answer
+type pendingServerRequest struct {
+	sessionID string
+	response  chan *mcp.JSONRPCResponse
+}
+
+// NewRequestBroker creates a new request broker
+func NewRequestBroker(authorizer *Authorizer) *RequestBroker {
+	return &RequestBroker{
+		authorizer: authorizer,
+		pending:    make(map[string]*pendingServerRequest),
+	}
+}
+
+// Forward relays a request from an upstream target to the session's client and
+// waits for the answer. The returned response carries the upstream request ID.
+func (b *RequestBroker) Forward(ctx context.Context, session *Session, targetName string, req *mcp.JSONRPCRequest) *mcp.JSONRPCResponse {
+	kind, ok := serverRequestKinds[req.Method]
+	if !ok {
+		return mcp.NewErrorResponse(req.ID, mcp.MethodNotFound, fmt.Sprintf("Method not supported: %s", req.Method))
+	}
+	if !clientSupports(session.ProtocolVersion(), session.ClientCapabilities(), kind) {
+		return mcp.NewErrorResponse(req.ID, mcp.MethodNotFound, fmt.Sprintf("Client does not support %s", kind))
+	}
+
+	if b.authorizer != nil {
+		targetID, _ := session.GetTargetID(targetName)
+		input := requestInput(ctx, targetName, nil)
+		canAccess, _, err := b.authorizer.CanAccessWith(ctx, session.UserID, session.Role, session.Groups, &targetID, kind, req.Method, input)
+		if err != nil || !canAccess {
+			log.Debug().
+				Str("session_id", session.ID).
+				Str("target", targetName).
+				Str("method", req.Method).
+				Msg("Server request denied by policy")
+			return mcp.NewErrorResponse(req.ID, mcp.InvalidRequest, fmt.Sprintf("Not authorized: %s", req.Method))
+		}
+	}
+
+	if session.SSEHub() == nil || session.SSEHub().ConnectionCount() == 0 {
+		return mcp.NewErrorResponse(req.ID, mcp.InternalError, "Client has no open stream to receive server requests")
+	}
+
+	// Upstream IDs are only unique per target, so the client sees a gateway-issued ID
+	gatewayID := "srv-" + uuid.New().String()
+	rawID, _ := json.Marshal(gatewayID)
+
+	waiter := &pendingServerRequest{
+		sessionID: session.ID,
+		response:  make(chan *mcp.JSONRPCResponse, 1),
+	}
+	b.mu.Lock()
+	b.pending[gatewayID] = waiter
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		delete(b.pending, gatewayID)
+		b.mu.Unlock()
+	}()
+
+	log.Debug().
+		Str("session_id", session.ID).
+		Str("target", targetName).
+		Str("method", req.Method).
+		Str("request_id", gatewayID).
+		Msg("Relaying server request to client")
+
+	session.SendRequest(&mcp.JSONRPCRequest{
+		JSONRPC: mcp.JSONRPCVersion,
+		ID:      rawID,
+		Method:  req.Method,
+		Params:  req.Params,
+	})
+
+	timer := time.NewTimer(serverRequestTimeout)
+	defer timer.Stop()
+
+	var reason string
+	select {
+	case resp := <-waiter.response:
+		return &mcp.JSONRPCResponse{
+			JSONRPC: mcp.JSONRPCVersion,
+			ID:      req.ID,
+			Result:  resp.Result,
+			Error:   resp.Error,
+		}
+	case <-ctx.Done():
+		reason = mcp.CancelReason(ctx)
+	case <-timer.C:
+		reason = "Timed out waiting for client response"
+	}
+
+	// Let the client stop working on a request nobody is waiting for anymore
+	session.SendNotification(mcp.NewCancelledNotification(rawID, reason))
+
+	return mcp.NewErrorResponse(req.ID, mcp.InternalError, reason)
+}
+
+// Resolve delivers a client's answer to the relayed request it belongs to.
+// Returns false if no such request is pending for the session.
+func (b *RequestBroker) Resolve(session *Session, resp *mcp.JSONRPCResponse) bool {
+	var gatewayID string
+	if err := json.Unmarshal(resp.ID, &gatewayID); err != nil {
+		return false
+	}
+
+	b.mu.Lock()
+	waiter, ok := b.pending[gatewayID]
+	if ok && waiter.sessionID == session.ID {
+		delete(b.pending, gatewayID)
+	}
+	b.mu.Unlock()
+
+	if !ok || waiter.sessionID != session.ID {
+		return false
+	}
+	waiter.response <- resp
+	return true
+}
+
+// clientSupports reports whether the client declared the capability a request kind
+// needs. Elicitation also requires a client on 2025-06-18 or later, which is the
+// first revision that defines it.
+func clientSupports(version string, caps *mcp.ClientCapabilities, kind string) bool {
+	if caps == nil {
+		return false
+	}
+	switch kind {
+	case "sampling":
+		return caps.Sampling != nil
+	case "elicitation":
+		return caps.Elicitation != nil && mcp.ProtocolAtLeast(version, mcp.ProtocolVersion20250618)
+	case "roots":
+		return caps.Roots != nil
+	}
+	return false
+}
+
+// subscribeRequests routes server-initiated requests from an upstream client to
+// the session's client through the broker.
+func (p *Proxy) subscribeRequests(session *Session, targetName string, client mcp.MCPClient) {
+	client.OnRequest(session.ID, func(ctx context.Context, req *mcp.JSONRPCRequest) *mcp.JSONRPCResponse {
+		return p.broker.Forward(ctx, session, targetName, req)
+	})
+}
+
+// ResolveServerRequest hands a client's answer to a relayed server request to the broker
+func (p *Proxy) ResolveServerRequest(session *Session, resp *mcp.JSONRPCResponse) bool {
+	return p.broker.Resolve(session, resp)
+}
+
+// upstreamInitializeParams returns the initialize params to send to a target.
+// Targets are always offered the gateway's newest protocol revision; results are
+// downgraded for older clients (see translate.go). Client capabilities for
+// server-initiated requests the client cannot receive, or that the user's
+// policies deny on that target, are withheld so the server does not attempt them.
+func (p *Proxy) upstreamInitializeParams(ctx context.Context, session *Session, targetID uuid.UUID, params *mcp.InitializeParams) *mcp.InitializeParams {
+	upstream := *params
+	upstream.ProtocolVersion = mcp.MCPProtocolVersion
+	if !clientSupports(session.ProtocolVersion(), &upstream.Capabilities, "elicitation") {
+		upstream.Capabilities.Elicitation = nil
+	}
+
+	if p.authorizer == nil {
+		return &upstream
+	}
+
+	allowed := func(kind, method string) bool {
+		canAccess, _, err := p.authorizer.CanAccess(ctx, session.UserID, session.Role, session.Groups, &targetID, kind, method)
+		return err == nil && canAccess
+	}
+
+	if upstream.Capabilities.Sampling != nil && !allowed("sampling", mcp.MethodSamplingCreateMessage) {
+		upstream.Capabilities.Sampling = nil
+	}
+	if upstream.Capabilities.Elicitation != nil && !allowed("elicitation", mcp.MethodElicitationCreate) {
+		upstream.Capabilities.Elicitation = nil
+	}
+	if upstream.Capabilities.Roots != nil && !allowed("roots", mcp.MethodRootsList) {
+		upstream.Capabilities.Roots = nil
+	}
+	return &upstream
+}
+
+// ForwardRootsListChanged tells every target allowed to list roots that the client's roots changed
+func (p *Proxy) ForwardRootsListChanged(ctx context.Context, session *Session, notification *mcp.JSONRPCNotification) {
+	for targetName, client := range session.GetAllClients() {
+		if p.authorizer != nil {
+			targetID, _ := session.GetTargetID(targetName)
+			canAccess, _, err := p.authorizer.CanAccess(ctx, session.UserID, session.Role, session.Groups, &targetID, "roots", mcp.MethodRootsList)
+			if err != nil || !canAccess {
+				continue
+			}
+		}
+		if err := client.SendNotification(ctx, notification); err != nil {
+			log.Warn().Err(err).Str("target", targetName).Msg("Failed to forward roots/list_changed")
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package gateway
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reflow/gateway/internal/database"
+	"github.com/reflow/gateway/internal/mcp"
+	"github.com/reflow/gateway/internal/telemetry"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	otelmetric "go.opentelemetry.io/otel/metric"
+)
+
+// Response cache stores
+const (
+	ResponseCacheStoreMemory   = "memory"   // entries local to this gateway process
+	ResponseCacheStorePostgres = "postgres" // entries shared by all gateway replicas
+)
+
+const (
+	// maxMemoryCacheEntries bounds the in-memory response cache
+	maxMemoryCacheEntries = 10000
+
+	// cacheSweepInterval is how often the postgres store drops expired entries
+	cacheSweepInterval = time.Minute
+
+	// cacheInvalidateTimeout bounds invalidations made for upstream notifications
+	cacheInvalidateTimeout = 5 * time.Second
+)
+
+// responseStore keeps cached upstream responses
+type responseStore interface {
+	// get returns an unexpired response, reporting whether there was one
+	get(ctx context.Context, key string) ([]byte, bool, error)
+	// set stores a response for ttl, tagged so it can be invalidated
+	set(ctx context.Context, key, tag string, value []byte, ttl time.Duration) error
+	// invalidate drops every response with a tag
+	invalidate(ctx context.Context, tag string) error
+}
+
+type memoryResponse struct {
+	tag       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// memoryResponses keeps cached responses in this process
+type memoryResponses struct {
+	mu      sync.Mutex
+	entries map[string]*memoryResponse
+}
+
+func (s *memoryResponses) get(_ context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(s.entries, key)
+		return nil, false, nil
+	}
+	return entry.value, true, nil
+}
+
+func (s *memoryResponses) set(_ context.Context, key, tag string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if _, exists := s.entries[key]; !exists && len(s.entries) >= maxMemoryCacheEntries {
+		for k, entry := range s.entries {
+			if now.After(entry.expiresAt) {
+				delete(s.entries, k)
+			}
+		}
+		// Still full: make room by dropping arbitrary entries
+		for k := range s.entries {
+			if len(s.entries) < maxMemoryCacheEntries {
+				break
+			}
+			delete(s.entries, k)
+		}
+	}
+	s.entries[key] = &memoryResponse{tag: tag, value: value, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+func (s *memoryResponses) invalidate(_ context.Context, tag string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, entry := range s.entries {
+		if entry.tag == tag {
+			delete(s.entries, key)
+		}
+	}
+	return nil
+}
+
+// postgresResponses keeps cached responses in the database
+type postgresResponses struct {
+	repo    *database.Repository
+	mu      sync.Mutex
+	sweptAt time.Time
+}
+
+func (s *postgresResponses) get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := s.repo.GetCachedResponse(ctx, key)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (s *postgresResponses) set(ctx context.Context, key, tag string, value []byte, ttl time.Duration) error {
+	if err := s.repo.SetCachedResponse(ctx, key, tag, value, time.Now().Add(ttl)); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	sweep := time.Since(s.sweptAt) > cacheSweepInterval
+	if sweep {
+		s.sweptAt = time.Now()
+	}
+	s.mu.Unlock()
+	if sweep {
+		if _, err := s.repo.DeleteExpiredCachedResponses(ctx); err != nil {
+			log.Warn().Err(err).Msg("Failed to drop expired cached responses")
+		}
+	}
+	return nil
+}
+
+func (s *postgresResponses) invalidate(ctx context.Context, tag string) error {
+	return s.repo.DeleteCachedResponses(ctx, tag)
+}
+
+// newResponseStore returns the response store for the configured kind
+func newResponseStore(kind string, repo *database.Repository) responseStore {
+	if kind == ResponseCacheStorePostgres && repo != nil {
+		return &postgresResponses{repo: repo}
+	}
+	return &memoryResponses{entries: make(map[string]*memoryResponse)}
+}
+
+// cacheScope identifies whose view of a target a session's connection gives.
+// Sessions only share cached responses within the same isolation subject and,
+// for HTTP targets, when they send the same URL, credentials and headers.
+func cacheScope(subjectKey string, cfg *mcp.ClientConfig) string {
+	if cfg == nil {
+		return subjectKey
+	}
+
+	h := sha256.New()
+	for _, field := range []string{cfg.URL, cfg.AuthHeader, cfg.AuthToken} {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+	names := make([]string, 0, len(cfg.CustomHeaders))
+	for name := range cfg.CustomHeaders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		h.Write([]byte(name + "=" + cfg.CustomHeaders[name]))
+		h.Write([]byte{0})
+	}
+	return subjectKey + "/" + hex.EncodeToString(h.Sum(nil))
+}
+
+// toolCacheTTL returns how long a tool's results are cached: the TTL set by its
+// tool override, if any, otherwise the target's TTL for tools annotated
+// readOnlyHint
+func toolCacheTTL(session *Session, targetName string, override *database.ToolOverride, annotations json.RawMessage) time.Duration {
+	if override != nil && override.CacheTTLSeconds != nil {
+		return time.Duration(*override.CacheTTLSeconds) * time.Second
+	}
+	var hints struct {
+		ReadOnlyHint bool `json:"readOnlyHint"`
+	}
+	if len(annotations) == 0 || json.Unmarshal(annotations, &hints) != nil || !hints.ReadOnlyHint {
+		return 0
+	}
+	return session.GetCacheTTL(targetName)
+}
+
+// cacheTag groups the cached responses to one request across sessions
+func cacheTag(targetID uuid.UUID, method, name string) string {
+	return method + " " + targetID.String() + " " + name
+}
+
+// cachedRequest is an upstream request whose response may be cached
+type cachedRequest struct {
+	key    string
+	tag    string
+	ttl    time.Duration
+	method string
+	target string
+}
+
+// cacheableRequest returns the cache entry of a request, or nil if its response
+// is not cached. The key covers the target, the session's cache scope, the
+// session's role, whose redaction rules the cached response went through, the
+// method, the upstream name and the arguments, which encoding/json writes with
+// sorted object keys.
+func (p *Proxy) cacheableRequest(session *Session, ttl time.Duration, targetID uuid.UUID, targetName, method, name string, args interface{}) *cachedRequest {
+	if ttl <= 0 {
+		return nil
+	}
+	scope, ok := session.GetCacheScope(targetName)
+	if !ok {
+		return nil
+	}
+	canonical, err := json.Marshal(args)
+	if err != nil {
+		return nil
+	}
+
+	tag := cacheTag(targetID, method, name)
+	h := sha256.New()
+	h.Write([]byte(tag))
+	h.Write([]byte{0})
+	h.Write([]byte(scope))
+	h.Write([]byte{0})
+	h.Write([]byte(session.Role))
+	h.Write([]byte{0})
+	h.Write(canonical)
+	return &cachedRequest{
+		key:    hex.EncodeToString(h.Sum(nil)),
+		tag:    tag,
+		ttl:    ttl,
+		method: method,
+		target: targetName,
+	}
+}
+
+// loadCached decodes a cached response into out, reporting whether there was
+// one. The cache fails open: errors count as misses.
+func (p *Proxy) loadCached(ctx context.Context, req *cachedRequest, out interface{}) bool {
+	if req == nil {
+		return false
+	}
+
+	value, hit, err := p.responses.get(ctx, req.key)
+	if err != nil {
+		log.Warn().Err(err).Str("target", req.target).Msg("Failed to read response cache")
+	}
+	if hit && json.Unmarshal(value, out) != nil {
+		hit = false
+	}
+
+	counter := telemetry.MCPCacheMisses
+	if hit {
+		counter = telemetry.MCPCacheHits
+	}
+	counter.Add(ctx, 1,
+		otelmetric.WithAttributes(
+			attribute.String("target", req.target),
+			attribute.String("method", req.method),
+		),
+	)
+	return hit
+}
+
+// storeCached caches an upstream response
+func (p *Proxy) storeCached(ctx context.Context, req *cachedRequest, value interface{}) {
+	if req == nil {
+		return
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	if err := p.responses.set(ctx, req.key, req.tag, raw, req.ttl); err != nil {
+		log.Warn().Err(err).Str("target", req.target).Msg("Failed to write response cache")
+	}
+}
+
+// invalidateResource drops every cached read of a resource, whichever session
+// read it
+func (p *Proxy) invalidateResource(targetID uuid.UUID, uri string) {
+	ctx, cancel := context.WithTimeout(context.Background(), cacheInvalidateTimeout)
+	defer cancel()
+
+	if err := p.responses.invalidate(ctx, cacheTag(targetID, mcp.MethodResourcesRead, uri)); err != nil {
+		log.Warn().Err(err).Str("uri", uri).Msg("Failed to invalidate cached resource")
+	}
+}
@@ -0,0 +1,73 @@
+package gateway
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/reflow/gateway/internal/mcp"
+)
+
+// listCursor is the gateway's composite pagination cursor. It records, for each
+// target that still has pages left, the opaque cursor that target returned.
+// Targets absent from the cursor are exhausted and are not queried again.
+type listCursor struct {
+	Targets map[string]string `json:"t"`
+}
+
+// encodeListCursor encodes per-target cursors into an opaque gateway cursor.
+// Returns nil when no target has more pages.
+func encodeListCursor(targets map[string]string) *string {
+	if len(targets) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(listCursor{Targets: targets})
+	if err != nil {
+		return nil
+	}
+	cursor := base64.RawURLEncoding.EncodeToString(data)
+	return &cursor
+}
+
+// decodeListCursor decodes an opaque gateway cursor into per-target cursors.
+func decodeListCursor(cursor string) (map[string]string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	var c listCursor
+	if err := json.Unmarshal(data, &c); err != nil || len(c.Targets) == 0 {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return c.Targets, nil
+}
+
+// pageTargets returns the clients to query for a list page and the upstream cursor
+// to pass to each. A nil cursor means the first page: every client, no cursor.
+func pageTargets(clients map[string]mcp.MCPClient, cursor *string) (map[string]mcp.MCPClient, map[string]*string, error) {
+	upstreamCursors := make(map[string]*string)
+	if cursor == nil || *cursor == "" {
+		for name := range clients {
+			upstreamCursors[name] = nil
+		}
+		return clients, upstreamCursors, nil
+	}
+
+	targets, err := decodeListCursor(*cursor)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	selected := make(map[string]mcp.MCPClient)
+	for name, targetCursor := range targets {
+		client, ok := clients[name]
+		if !ok {
+			// Target disconnected since the previous page; nothing left to fetch
+			continue
+		}
+		c := targetCursor
+		selected[name] = client
+		upstreamCursors[name] = &c
+	}
+	return selected, upstreamCursors, nil
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -28,7 +29,7 @@ var tracer = otel.Tracer("reflow-gateway/gateway")
 //
 // Streamable HTTP transport (MCP spec):
 //   - POST /mcp  → JSON-RPC requests (initialize, tools/list, tools/call, etc.)
-//   - GET  /mcp  → SSE stream for server-to-client notifications
+//   - GET  /mcp  → SSE stream for server-to-client notifications (relayed from upstream targets)
 //   - DELETE /mcp → Session termination
 type Handler struct {
 	sessionManager *SessionManager
@@ -66,6 +67,7 @@ func (h *Handler) HandleMCP(w http.ResponseWriter, r *http.Request) {
 // Flow:
 //  1. POST with method=initialize → creates session, connects to upstream targets, returns session ID
 //  2. POST with Mcp-Session-Id header → routes request to appropriate upstream target
+//  3. POST with a JSON array → batch of messages on an existing session (see handleBatch)
 func (h *Handler) handlePost(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	startTime := time.Now()
@@ -84,6 +86,11 @@ func (h *Handler) handlePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if isBatch(body) {
+		h.handleBatch(w, r, ctx, userID, body, startTime)
+		return
+	}
+
 	var req mcp.JSONRPCRequest
 	if err := json.Unmarshal(body, &req); err != nil {
 		h.writeJSONRPCError(w, nil, mcp.ParseError, "Invalid JSON")
@@ -120,22 +127,115 @@ func (h *Handler) handlePost(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// --- All other methods require an existing session ---
-	if sessionID == "" {
-		h.writeJSONRPCError(w, req.ID, mcp.InvalidRequest, "Session required. Send initialize first.")
+	session := h.sessionForRequest(w, r, ctx, userID, sessionID, req.ID)
+	if session == nil {
+		return
+	}
+
+	if wantsResponseStream(r, &req) {
+		h.streamResponse(w, ctx, session, userID, &req, body, startTime)
 		return
 	}
 
+	resp, ok := h.dispatchMessage(ctx, session, &req, body)
+	if resp == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	w.Header().Set("Mcp-Session-Id", session.ID)
+	h.writeJSON(w, resp)
+
+	if ok {
+		h.recordRequest(ctx, session.ID, userID, req.Method, body, startTime)
+	}
+}
+
+// wantsResponseStream reports whether a request should be answered with an SSE
+// stream: the client accepts one and the request may send progress before its
+// response (a tool call, or any request carrying a progressToken).
+func wantsResponseStream(r *http.Request, req *mcp.JSONRPCRequest) bool {
+	if req.Method == "" || req.IsNotification() {
+		return false
+	}
+	if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		return false
+	}
+	if req.Method == mcp.MethodToolsCall {
+		return true
+	}
+	var params struct {
+		Meta struct {
+			ProgressToken json.RawMessage `json:"progressToken"`
+		} `json:"_meta"`
+	}
+	return json.Unmarshal(req.Params, &params) == nil && len(params.Meta.ProgressToken) > 0
+}
+
+// streamResponse answers a request with an SSE stream carrying the request's
+// progress notifications and then its response. A dropped connection does not
+// cancel the request: the client can reconnect with GET /mcp and Last-Event-ID
+// to pick the stream up where it left off.
+func (h *Handler) streamResponse(w http.ResponseWriter, ctx context.Context, session *Session, userID uuid.UUID, req *mcp.JSONRPCRequest, body []byte, startTime time.Time) {
+	w.Header().Set("Mcp-Session-Id", session.ID)
+	sseWriter, err := mcp.NewSSEWriter(w)
+	if err != nil {
+		// Streaming not supported by the connection; answer with plain JSON
+		resp, ok := h.dispatchMessage(ctx, session, req, body)
+		h.writeJSON(w, resp)
+		if ok {
+			h.recordRequest(ctx, session.ID, userID, req.Method, body, startTime)
+		}
+		return
+	}
+
+	stream := session.SSEHub().OpenResponseStream(sseWriter)
+	log.Debug().
+		Str("session_id", session.ID).
+		Str("stream_id", stream.ID()).
+		Str("method", req.Method).
+		Msg("Answering request on SSE response stream")
+
+	reqCtx := withResponseStream(context.WithoutCancel(ctx), stream)
+	resp, ok := h.dispatchMessage(reqCtx, session, req, body)
+	if err := stream.Send(resp); err != nil {
+		log.Error().Err(err).Str("session_id", session.ID).Msg("Failed to send response on SSE stream")
+	}
+	stream.Close()
+
+	if ok {
+		h.recordRequest(ctx, session.ID, userID, req.Method, body, startTime)
+	}
+}
+
+// sessionForRequest loads the session a POST addresses and checks that it belongs to
+// the caller, is on a supported protocol revision and still matches the caller's
+// identity. It writes the error response itself and returns nil when the request
+// cannot proceed.
+func (h *Handler) sessionForRequest(w http.ResponseWriter, r *http.Request, ctx context.Context, userID uuid.UUID, sessionID string, id json.RawMessage) *Session {
+	if sessionID == "" {
+		h.writeJSONRPCError(w, id, mcp.InvalidRequest, "Session required. Send initialize first.")
+		return nil
+	}
+
 	session, err := h.sessionManager.GetSession(ctx, sessionID)
 	if err != nil {
 		// Session not found or expired → client must re-initialize
 		http.Error(w, "Session not found or expired", http.StatusNotFound)
-		return
+		return nil
 	}
 
 	// Verify the session belongs to the authenticated user
 	if session.UserID != userID {
 		http.Error(w, "Forbidden", http.StatusForbidden)
-		return
+		return nil
+	}
+
+	// Clients on 2025-06-18 and later repeat the negotiated revision on every request;
+	// older clients omit the header and the session's negotiated revision applies
+	if version := r.Header.Get("MCP-Protocol-Version"); version != "" && !mcp.IsSupportedProtocolVersion(version) {
+		http.Error(w, "Unsupported MCP-Protocol-Version: "+version, http.StatusBadRequest)
+		return nil
 	}
 
 	// Auto-recycle if JWT claims changed (e.g., IdP updated groups/role)
@@ -149,43 +249,108 @@ func (h *Handler) handlePost(w http.ResponseWriter, r *http.Request) {
 			Msg("JWT claims changed, recycling session")
 		session.Recycle(role, groups)
 		// Session is now uninitialized — client must re-initialize
-		h.writeJSONRPCError(w, req.ID, mcp.InvalidRequest, "Session recycled due to identity change. Please re-initialize.")
-		return
+		h.writeJSONRPCError(w, id, mcp.InvalidRequest, "Session recycled due to identity change. Please re-initialize.")
+		return nil
+	}
+
+	return session
+}
+
+// dispatchMessage handles one JSON-RPC message on an established session: a client
+// answer to a server-initiated request, a notification, or a request routed through
+// the proxy. It returns the response to send (nil when the message gets none) and
+// whether a request was handled and should be recorded.
+func (h *Handler) dispatchMessage(ctx context.Context, session *Session, req *mcp.JSONRPCRequest, body []byte) (*mcp.JSONRPCResponse, bool) {
+	// Client answers to server-initiated requests (sampling, elicitation, roots)
+	if req.Method == "" {
+		var msg mcp.JSONRPCMessage
+		if err := json.Unmarshal(body, &msg); err != nil || !msg.IsResponse() {
+			return mcp.NewErrorResponse(req.ID, mcp.InvalidRequest, "Invalid request"), false
+		}
+		if !h.proxy.ResolveServerRequest(session, msg.ToResponse()) {
+			log.Debug().
+				Str("session_id", session.ID).
+				Str("id", string(msg.ID)).
+				Msg("Dropping response to unknown or expired server request")
+		}
+		return nil, false
 	}
 
 	// Handle notifications (no response needed)
 	if req.IsNotification() {
-		w.WriteHeader(http.StatusAccepted)
-		return
+		switch req.Method {
+		case mcp.MethodNotificationRootsListChanged:
+			h.proxy.ForwardRootsListChanged(ctx, session, &mcp.JSONRPCNotification{
+				JSONRPC: mcp.JSONRPCVersion,
+				Method:  req.Method,
+				Params:  req.Params,
+			})
+		case mcp.MethodNotificationCancelled:
+			// Aborting the request context makes the upstream client send its own
+			// notifications/cancelled with the ID it allocated
+			var params mcp.CancelledParams
+			if err := json.Unmarshal(req.Params, &params); err == nil {
+				if !session.CancelRequest(params.RequestID, params.Reason) {
+					log.Debug().
+						Str("session_id", session.ID).
+						Str("request_id", string(params.RequestID)).
+						Msg("Cancellation for unknown or finished request")
+				}
+			}
+		}
+		return nil, false
 	}
 
+	// Track the request so a notifications/cancelled from the client can abort it
+	reqCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	untrack := session.TrackRequest(req.ID, cancel)
+	defer untrack()
+
 	// Route request through proxy
-	resp, err := h.proxy.HandleRequest(ctx, session, &req)
+	resp, err := h.proxy.HandleRequest(reqCtx, session, req)
 	if err != nil {
-		span.SetStatus(codes.Error, err.Error())
-		h.writeJSONRPCError(w, req.ID, mcp.InternalError, err.Error())
-		return
+		trace.SpanFromContext(ctx).SetStatus(codes.Error, err.Error())
+		return mcp.NewErrorResponse(req.ID, mcp.InternalError, err.Error()), false
 	}
+	return resp, true
+}
 
-	w.Header().Set("Mcp-Session-Id", session.ID)
-	h.writeJSON(w, resp)
-
-	// Record metrics and emit activity
+// recordRequest records metrics and the audit log entry for a handled request
+func (h *Handler) recordRequest(ctx context.Context, sessionID string, userID uuid.UUID, method string, body []byte, startTime time.Time) {
 	duration := float64(time.Since(startTime).Milliseconds())
 	telemetry.MCPRequestsTotal.Add(ctx, 1,
-		otelmetric.WithAttributes(attribute.String("method", req.Method), attribute.String("status", "ok")),
+		otelmetric.WithAttributes(attribute.String("method", method), attribute.String("status", "ok")),
 	)
 	telemetry.MCPRequestDuration.Record(ctx, duration,
-		otelmetric.WithAttributes(attribute.String("method", req.Method)),
+		otelmetric.WithAttributes(attribute.String("method", method)),
 	)
 
 	// Audit log
-	h.logRequest(ctx, session.ID, &userID, req.Method, "", body, http.StatusOK, startTime)
+	h.logRequest(ctx, sessionID, &userID, method, "", body, http.StatusOK, startTime)
 }
 
 // handleInitialize creates a new session (or reuses an existing one from SSE compat),
 // connects to authorized upstream MCP servers, and returns the aggregated capabilities.
 func (h *Handler) handleInitialize(w http.ResponseWriter, r *http.Request, ctx context.Context, userID uuid.UUID, existingSessionID string, req *mcp.JSONRPCRequest, body []byte, startTime time.Time) {
+	session, result, errResp := h.initializeSession(ctx, userID, existingSessionID, req)
+	if errResp != nil {
+		h.writeJSON(w, errResp)
+		return
+	}
+
+	// Return session ID in header so the client can use it for subsequent requests
+	w.Header().Set("Mcp-Session-Id", session.ID)
+	w.Header().Set("MCP-Protocol-Version", result.ProtocolVersion)
+	h.writeJSONRPCResponse(w, req.ID, result)
+
+	h.recordInitialize(ctx, session.ID, userID, body, startTime)
+}
+
+// initializeSession runs an initialize request for any client transport: it creates
+// the session (or reuses existingSessionID) and connects the upstream targets. On
+// failure it returns the error response to send instead.
+func (h *Handler) initializeSession(ctx context.Context, userID uuid.UUID, existingSessionID string, req *mcp.JSONRPCRequest) (*Session, *mcp.InitializeResult, *mcp.JSONRPCResponse) {
 	ctx, span := tracer.Start(ctx, "handleInitialize",
 		trace.WithAttributes(
 			attribute.String("mcp.user_id", userID.String()),
@@ -217,8 +382,7 @@ func (h *Handler) handleInitialize(w http.ResponseWriter, r *http.Request, ctx c
 		session, err = h.sessionManager.CreateSession(ctx, userID, role, groups)
 		if err != nil {
 			span.SetStatus(codes.Error, "Failed to create session")
-			h.writeJSONRPCError(w, req.ID, mcp.InternalError, "Failed to create session")
-			return
+			return nil, nil, mcp.NewErrorResponse(req.ID, mcp.InternalError, "Failed to create session")
 		}
 		// Emit session created event
 		if h.obsHub != nil {
@@ -232,8 +396,7 @@ func (h *Handler) handleInitialize(w http.ResponseWriter, r *http.Request, ctx c
 
 	var params mcp.InitializeParams
 	if err := json.Unmarshal(req.Params, &params); err != nil {
-		h.writeJSONRPCError(w, req.ID, mcp.InvalidParams, "Invalid initialize params")
-		return
+		return nil, nil, mcp.NewErrorResponse(req.ID, mcp.InvalidParams, "Invalid initialize params")
 	}
 
 	log.Info().
@@ -247,16 +410,14 @@ func (h *Handler) handleInitialize(w http.ResponseWriter, r *http.Request, ctx c
 	result, err := h.proxy.InitializeSession(ctx, session, &params)
 	if err != nil {
 		span.SetStatus(codes.Error, err.Error())
-		h.writeJSONRPCError(w, req.ID, mcp.InternalError, err.Error())
-		return
+		return nil, nil, mcp.NewErrorResponse(req.ID, mcp.InternalError, err.Error())
 	}
 
-	// Return session ID in header so the client can use it for subsequent requests
-	w.Header().Set("Mcp-Session-Id", session.ID)
-	w.Header().Set("MCP-Protocol-Version", mcp.MCPProtocolVersion)
-	h.writeJSONRPCResponse(w, req.ID, result)
+	return session, result, nil
+}
 
-	// Metrics
+// recordInitialize records metrics, activity and the audit log entry for a successful initialize
+func (h *Handler) recordInitialize(ctx context.Context, sessionID string, userID uuid.UUID, body []byte, startTime time.Time) {
 	duration := float64(time.Since(startTime).Milliseconds())
 	telemetry.MCPRequestsTotal.Add(ctx, 1,
 		otelmetric.WithAttributes(attribute.String("method", "initialize"), attribute.String("status", "ok")),
@@ -266,7 +427,7 @@ func (h *Handler) handleInitialize(w http.ResponseWriter, r *http.Request, ctx c
 	)
 	h.emitRequestActivity(ctx, startTime, userID.String(), "initialize", "", "", "ok")
 
-	h.logRequest(ctx, session.ID, &userID, req.Method, "", body, http.StatusOK, startTime)
+	h.logRequest(ctx, sessionID, &userID, mcp.MethodInitialize, "", body, http.StatusOK, startTime)
 }
 
 // handleSSE handles GET /mcp.
@@ -321,6 +482,21 @@ func (h *Handler) handleSSE(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// A client that lost a stream reconnects with the last event ID it saw
+	resumeStreamID, resumeSeq := "", uint64(0)
+	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" && r.Header.Get("Mcp-Session-Id") != "" {
+		streamID, seq, err := parseEventID(lastEventID)
+		if err != nil {
+			http.Error(w, "Invalid Last-Event-ID", http.StatusBadRequest)
+			return
+		}
+		if !session.SSEHub().HasStream(streamID) {
+			http.Error(w, "Stream not found", http.StatusNotFound)
+			return
+		}
+		resumeStreamID, resumeSeq = streamID, seq
+	}
+
 	w.Header().Set("Mcp-Session-Id", session.ID)
 
 	sseWriter, err := mcp.NewSSEWriter(w)
@@ -329,6 +505,11 @@ func (h *Handler) handleSSE(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if resumeStreamID != "" && resumeStreamID != notificationStreamID {
+		h.resumeResponseStream(ctx, session, sseWriter, resumeStreamID, resumeSeq)
+		return
+	}
+
 	// If session was just created (no prior session ID), send the endpoint event
 	// so SSE-transport clients know where to POST their JSON-RPC messages.
 	if r.Header.Get("Mcp-Session-Id") == "" {
@@ -354,13 +535,55 @@ func (h *Handler) handleSSE(w http.ResponseWriter, r *http.Request) {
 		log.Info().Str("session_id", sessionID).Msg("SSE notification stream opened")
 	}
 
-	// Keep alive until client disconnects
-	<-ctx.Done()
+	// Register with the session hub so upstream notifications reach this stream.
+	// From here on, all writes go through the hub to keep them serialized.
+	connID := uuid.New().String()
+	var conn *SSEConnection
+	if resumeStreamID == notificationStreamID {
+		conn, err = session.SSEHub().ResumeConnection(connID, sseWriter, resumeSeq)
+		if err != nil {
+			log.Debug().Err(err).Str("session_id", sessionID).Msg("Failed to replay notification stream")
+			return
+		}
+	} else {
+		conn = session.SSEHub().AddConnection(connID, sseWriter)
+	}
+
+	// Keep alive until client disconnects or the session goes away
+	select {
+	case <-ctx.Done():
+		session.SSEHub().RemoveConnection(connID)
+	case <-conn.Done:
+		// Session was deleted or expired; the hub no longer writes to this stream
+		sseWriter.Close()
+	}
 
-	sseWriter.Close()
 	log.Info().Str("session_id", sessionID).Msg("SSE stream closed")
 }
 
+// resumeResponseStream continues a POST response stream on a GET connection: it
+// replays the events after lastSeq, then forwards the rest until the response.
+func (h *Handler) resumeResponseStream(ctx context.Context, session *Session, sseWriter *mcp.SSEWriter, streamID string, lastSeq uint64) {
+	stream, err := session.SSEHub().ResumeResponseStream(streamID, sseWriter, lastSeq)
+	if err != nil {
+		log.Debug().Err(err).Str("session_id", session.ID).Str("stream_id", streamID).Msg("Failed to resume response stream")
+		return
+	}
+
+	log.Info().
+		Str("session_id", session.ID).
+		Str("stream_id", streamID).
+		Uint64("last_event", lastSeq).
+		Msg("Resumed SSE response stream")
+
+	select {
+	case <-ctx.Done():
+	case <-stream.Done():
+	}
+	// The handler owns sseWriter; make sure the stream stops using it
+	stream.Detach(sseWriter)
+}
+
 // handleDelete terminates a session per the Streamable HTTP spec.
 func (h *Handler) handleDelete(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
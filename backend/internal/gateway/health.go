@@ -0,0 +1,342 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reflow/gateway/internal/database"
+	"github.com/reflow/gateway/internal/mcp"
+	"github.com/reflow/gateway/internal/observability"
+	"github.com/reflow/gateway/internal/telemetry"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	otelmetric "go.opentelemetry.io/otel/metric"
+)
+
+const (
+	defaultHealthCheckInterval = 30 * time.Second
+	defaultHealthCheckTimeout  = 10 * time.Second
+
+	// healthRecordTimeout bounds storing a result, which must not share the
+	// deadline of a check that timed out
+	healthRecordTimeout = 5 * time.Second
+
+	// maxHealthCheckPages bounds the tools/list pages a health check counts
+	maxHealthCheckPages = 10
+
+	// healthStaleChecks is how many check intervals a result stays current.
+	// Older results, say when no replica is checking, never skip a target.
+	healthStaleChecks = 3
+)
+
+// HealthCheckerConfig holds health checker settings
+type HealthCheckerConfig struct {
+	// Interval is the time between checks of every enabled target (default 30s)
+	Interval time.Duration
+
+	// Timeout bounds a single target's check (default 10s)
+	Timeout time.Duration
+}
+
+// HealthChecker periodically checks the enabled targets and records the
+// results. Each endpoint of an HTTP, SSE or WebSocket target is probed with
+// initialize followed by tools/list, or ping when the target has no tools,
+// using the target's default credentials. STDIO targets are checked for a live process.
+// Kubernetes targets are left to their readiness probes.
+type HealthChecker struct {
+	proxy    *Proxy
+	repo     *database.Repository
+	interval time.Duration
+	timeout  time.Duration
+
+	mu       sync.Mutex
+	statuses map[uuid.UUID]string // last status seen by this checker, to report changes
+
+	stop chan struct{}
+}
+
+// NewHealthChecker creates a health checker and starts checking
+func NewHealthChecker(proxy *Proxy, repo *database.Repository, cfg HealthCheckerConfig) *HealthChecker {
+	if cfg.Interval == 0 {
+		cfg.Interval = defaultHealthCheckInterval
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultHealthCheckTimeout
+	}
+
+	c := &HealthChecker{
+		proxy:    proxy,
+		repo:     repo,
+		interval: cfg.Interval,
+		timeout:  cfg.Timeout,
+		statuses: make(map[uuid.UUID]string),
+		stop:     make(chan struct{}),
+	}
+	go c.loop()
+	return c
+}
+
+// Stop stops checking
+func (c *HealthChecker) Stop() {
+	close(c.stop)
+}
+
+func (c *HealthChecker) loop() {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	c.checkAll()
+	for {
+		select {
+		case <-ticker.C:
+			c.checkAll()
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// checkAll checks every enabled target concurrently
+func (c *HealthChecker) checkAll() {
+	targets, err := c.repo.GetEnabledTargets(context.Background())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load targets for health checks")
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, target := range targets {
+		if target.TransportType == "kubernetes" {
+			continue
+		}
+		wg.Add(1)
+		go func(target *database.Target) {
+			defer wg.Done()
+			c.check(target)
+		}(target)
+	}
+	wg.Wait()
+}
+
+// check checks one target and records the result
+func (c *HealthChecker) check(target *database.Target) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	var check *database.TargetHealthCheck
+	if target.TransportType == "stdio" {
+		check = c.checkProcesses(target)
+	} else {
+		check = c.probe(ctx, target)
+	}
+
+	recordCtx, cancelRecord := context.WithTimeout(context.Background(), healthRecordTimeout)
+	defer cancelRecord()
+	if err := c.repo.RecordTargetHealth(recordCtx, check); err != nil {
+		log.Error().Err(err).Str("target", target.Name).Msg("Failed to record target health")
+	}
+
+	if check.LatencyMS != nil {
+		telemetry.MCPHealthCheckDuration.Record(recordCtx, float64(*check.LatencyMS),
+			otelmetric.WithAttributes(
+				attribute.String("target", target.Name),
+				attribute.String("status", check.Status),
+			),
+		)
+	}
+
+	c.mu.Lock()
+	previous := c.statuses[target.ID]
+	c.statuses[target.ID] = check.Status
+	c.mu.Unlock()
+
+	if check.Status != previous && (check.Status == database.TargetUnhealthy || previous == database.TargetUnhealthy) {
+		c.proxy.reportHealth(target.Name, check)
+	}
+}
+
+// probe connects to each endpoint of an HTTP, SSE or WebSocket target with its
+// default credentials, the ones resolved for no particular user. A target is
+// healthy while any of its endpoints is; endpoints that fail are moved to the
+// back of the load balancer's order.
+func (c *HealthChecker) probe(ctx context.Context, target *database.Target) *database.TargetHealthCheck {
+	endpoints := target.UpstreamEndpoints()
+	results := make([]database.EndpointHealth, len(endpoints))
+	toolCounts := make([]*int, len(endpoints))
+
+	var wg sync.WaitGroup
+	for i, endpoint := range endpoints {
+		wg.Add(1)
+		go func(i int, url string) {
+			defer wg.Done()
+
+			start := time.Now()
+			toolCount, err := c.proxy.probeTarget(ctx, target, url)
+			latency := int(time.Since(start).Milliseconds())
+
+			results[i] = database.EndpointHealth{URL: url, Status: database.TargetHealthy, LatencyMS: &latency}
+			toolCounts[i] = toolCount
+			if err != nil {
+				msg := err.Error()
+				results[i].Status = database.TargetUnhealthy
+				results[i].Error = &msg
+			}
+			c.proxy.markEndpointProbe(target, url, err != nil)
+		}(i, endpoint.URL)
+	}
+	wg.Wait()
+
+	check := &database.TargetHealthCheck{TargetID: target.ID, Status: database.TargetUnhealthy}
+	var failures []string
+	for i, result := range results {
+		if result.Status == database.TargetHealthy && check.Status != database.TargetHealthy {
+			check.Status = database.TargetHealthy
+			check.LatencyMS = result.LatencyMS
+			check.ToolCount = toolCounts[i]
+		}
+		if result.Error != nil {
+			if len(target.Endpoints) > 0 {
+				failures = append(failures, result.URL+": "+*result.Error)
+			} else {
+				failures = append(failures, *result.Error)
+			}
+		}
+	}
+	if check.Status == database.TargetUnhealthy {
+		check.LatencyMS = results[0].LatencyMS
+	}
+	if len(failures) > 0 {
+		msg := strings.Join(failures, "; ")
+		check.Error = &msg
+	}
+	if len(target.Endpoints) > 0 {
+		check.Endpoints = results
+	}
+	return check
+}
+
+// checkProcesses reports whether a STDIO target has a live process. A target
+// without processes is unknown: the next session starts one.
+func (c *HealthChecker) checkProcesses(target *database.Target) *database.TargetHealthCheck {
+	check := &database.TargetHealthCheck{TargetID: target.ID, Status: database.TargetUnknown}
+	if c.proxy.stdioManager == nil {
+		return check
+	}
+
+	alive, exited := c.proxy.stdioManager.TargetProcesses(target.Name)
+	switch {
+	case alive > 0:
+		check.Status = database.TargetHealthy
+	case exited > 0:
+		msg := fmt.Sprintf("%d STDIO process(es) exited", exited)
+		check.Status = database.TargetUnhealthy
+		check.Error = &msg
+	}
+	return check
+}
+
+// probeTarget initializes a fresh connection to an endpoint of a target and
+// counts its tools, or pings it if it has none. The tool count is nil when it
+// was not listed.
+func (p *Proxy) probeTarget(ctx context.Context, target *database.Target, url string) (*int, error) {
+	client := mcp.NewClient(p.httpClientConfig(ctx, target, url, uuid.Nil, "", nil))
+	defer client.Close()
+
+	result, err := client.Initialize(ctx, &mcp.InitializeParams{
+		ProtocolVersion: mcp.MCPProtocolVersion,
+		ClientInfo: mcp.ClientInfo{
+			Name:    "reflow-gateway-health",
+			Version: "1.0.0",
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("initialize: %w", err)
+	}
+
+	if result.Capabilities.Tools == nil {
+		resp, err := client.SendRawRequest(ctx, &mcp.JSONRPCRequest{
+			JSONRPC: mcp.JSONRPCVersion,
+			ID:      json.RawMessage(`"health-check"`),
+			Method:  mcp.MethodPing,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("ping: %w", err)
+		}
+		if resp.Error != nil {
+			return nil, mcp.NewUpstreamError(mcp.MethodPing, resp.Error)
+		}
+		return nil, nil
+	}
+
+	count := 0
+	var cursor *string
+	for page := 0; page < maxHealthCheckPages; page++ {
+		tools, err := client.ListTools(ctx, cursor)
+		if err != nil {
+			return nil, err
+		}
+		count += len(tools.Tools)
+		if tools.NextCursor == nil || *tools.NextCursor == "" {
+			break
+		}
+		cursor = tools.NextCursor
+	}
+	return &count, nil
+}
+
+// unhealthyTargets returns the targets whose current health check failed.
+// Only probed targets count: STDIO processes that exited are replaced when a
+// session starts.
+func (p *Proxy) unhealthyTargets(ctx context.Context) map[uuid.UUID]bool {
+	results, err := p.repo.ListTargetHealth(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load target health, not skipping unhealthy targets")
+		return nil
+	}
+
+	interval := p.config.HealthCheckInterval
+	if interval == 0 {
+		interval = defaultHealthCheckInterval
+	}
+	unhealthy := make(map[uuid.UUID]bool)
+	for _, health := range results {
+		if health.Status != database.TargetUnhealthy || health.TransportType == "stdio" {
+			continue
+		}
+		if health.LastCheckedAt == nil || time.Since(*health.LastCheckedAt) > healthStaleChecks*interval {
+			continue
+		}
+		unhealthy[health.TargetID] = true
+	}
+	return unhealthy
+}
+
+// reportHealth logs a target becoming unhealthy or recovering and shows it on
+// the dashboard
+func (p *Proxy) reportHealth(target string, check *database.TargetHealthCheck) {
+	var msg string
+	if check.Status == database.TargetUnhealthy {
+		msg = fmt.Sprintf("Target %s failed its health check", target)
+		if check.Error != nil {
+			msg += ": " + *check.Error
+		}
+		log.Warn().Str("target", target).Msg(msg)
+	} else {
+		msg = fmt.Sprintf("Target %s recovered; health check status is %s", target, check.Status)
+		log.Info().Str("target", target).Msg(msg)
+	}
+
+	if p.obsHub != nil {
+		p.obsHub.EmitError(observability.ErrorEvent{
+			Timestamp: time.Now(),
+			Target:    target,
+			ErrorType: "target_health_" + check.Status,
+			Message:   msg,
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package gateway
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/reflow/gateway/internal/observability"
+	"github.com/rs/zerolog/log"
+)
+
+// defaultToolDelimiter separates a target's namespace from an upstream name
+const defaultToolDelimiter = "_"
+
+// prefixedName returns the client-visible name for an upstream tool, prompt,
+// resource URI or URI template: the target's namespace, the delimiter, then the
+// upstream name. Names are prefixed the same way however many targets are connected.
+func (p *Proxy) prefixedName(session *Session, targetName, name string) string {
+	return session.GetTargetNamespace(targetName) + p.config.ToolDelimiter + name
+}
+
+// resolvePrefixedName splits a client-visible name into its target and upstream
+// name. Namespaces cannot contain the delimiter, so the first delimiter ends it.
+// It is the fallback for names that have no mapping in the session.
+func (p *Proxy) resolvePrefixedName(session *Session, name string) (string, string, bool) {
+	namespace, upstreamName, found := strings.Cut(name, p.config.ToolDelimiter)
+	if !found {
+		return "", "", false
+	}
+	targetName, ok := session.TargetForNamespace(namespace)
+	if !ok {
+		return "", "", false
+	}
+	return targetName, upstreamName, true
+}
+
+// nameCollision is a client-visible name claimed by two upstream items
+type nameCollision struct {
+	name   string
+	first  string // target/upstream name that claimed the name first
+	second string
+}
+
+// nameClaims detects client-visible names that two upstream items map to within
+// one list response, or across the pages of one listing.
+type nameClaims struct {
+	owners     map[string]string // client-visible name -> target/upstream name
+	collisions []nameCollision
+}
+
+func newNameClaims() *nameClaims {
+	return &nameClaims{owners: make(map[string]string)}
+}
+
+// claim records that owner lists name. previousOwner is the owner from an earlier
+// page of the same listing, if any. Returns false, recording a collision, when the
+// name is already taken by someone else.
+func (c *nameClaims) claim(name, owner, previousOwner string) bool {
+	if existing, ok := c.owners[name]; ok {
+		c.collisions = append(c.collisions, nameCollision{name: name, first: existing, second: owner})
+		return false
+	}
+	if previousOwner != "" && previousOwner != owner {
+		c.collisions = append(c.collisions, nameCollision{name: name, first: previousOwner, second: owner})
+		return false
+	}
+	c.owners[name] = owner
+	return true
+}
+
+// err describes every collision, or returns nil if there were none
+func (c *nameClaims) err(kind string) error {
+	if len(c.collisions) == 0 {
+		return nil
+	}
+	sort.Slice(c.collisions, func(i, j int) bool { return c.collisions[i].name < c.collisions[j].name })
+
+	parts := make([]string, 0, len(c.collisions))
+	for _, collision := range c.collisions {
+		parts = append(parts, fmt.Sprintf("%q is listed by both %s and %s", collision.name, collision.first, collision.second))
+	}
+	return fmt.Errorf("%s name collision: %s", kind, strings.Join(parts, "; "))
+}
+
+// reportNameCollision logs a list collision and surfaces it on the observability hub
+func (p *Proxy) reportNameCollision(session *Session, err error) {
+	log.Error().
+		Err(err).
+		Str("session_id", session.ID).
+		Msg("Upstream names collide after prefixing")
+
+	if p.obsHub != nil {
+		p.obsHub.EmitError(observability.ErrorEvent{
+			Timestamp: time.Now(),
+			UserID:    session.UserID.String(),
+			ErrorType: "name_collision",
+			Message:   err.Error(),
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package gateway
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/reflow/gateway/internal/mcp"
+	"github.com/rs/zerolog/log"
+)
+
+// cacheInvalidationSubscriber keys the handler that drops cached reads of updated
+// resources. Every session on a connection registers the same key, so a shared
+// STDIO process invalidates once per notification.
+const cacheInvalidationSubscriber = "response-cache"
+
+// subscribeNotifications fans in notifications from an upstream client to the session.
+// The handler is keyed by session ID so shared STDIO processes can serve many sessions.
+func (p *Proxy) subscribeNotifications(session *Session, targetName string, client mcp.MCPClient) {
+	client.OnNotification(session.ID, func(notification *mcp.JSONRPCNotification) {
+		p.relayNotification(session, targetName, notification)
+	})
+
+	if targetID, ok := session.GetTargetID(targetName); ok {
+		client.OnNotification(cacheInvalidationSubscriber, func(notification *mcp.JSONRPCNotification) {
+			p.invalidateUpdatedResource(targetID, notification)
+		})
+	}
+}
+
+// invalidateUpdatedResource drops cached reads of a resource an upstream reports
+// updated, whether or not any session subscribed to it. The store call runs off
+// the upstream's read loop.
+func (p *Proxy) invalidateUpdatedResource(targetID uuid.UUID, notification *mcp.JSONRPCNotification) {
+	if notification.Method != mcp.MethodNotificationResourcesUpdated {
+		return
+	}
+	var updated mcp.ResourceSubscribeParams
+	if err := json.Unmarshal(notification.Params, &updated); err != nil {
+		return
+	}
+	go p.invalidateResource(targetID, updated.URI)
+}
+
+// relayNotification rewrites an upstream notification into the gateway's
+// multiplexed namespace and pushes it to the session's notification stream.
+func (p *Proxy) relayNotification(session *Session, targetName string, notification *mcp.JSONRPCNotification) {
+	out := &mcp.JSONRPCNotification{
+		JSONRPC: mcp.JSONRPCVersion,
+		Method:  notification.Method,
+		Params:  notification.Params,
+	}
+	var stream *ResponseStream
+
+	switch notification.Method {
+	case mcp.MethodNotificationToolsListChanged,
+		mcp.MethodNotificationResourcesListChanged,
+		mcp.MethodNotificationPromptsListChanged:
+		// No target-specific names in params; forward as-is
+
+	case mcp.MethodNotificationProgress:
+		// Only progress for this session's own requests, under the client's token
+		params, requestStream, ok := restoreProgressToken(session, notification.Params)
+		if !ok {
+			return
+		}
+		out.Params = downgradeProgressParams(session.ProtocolVersion(), params)
+		stream = requestStream
+
+	case mcp.MethodNotificationResourcesUpdated:
+		// Only sessions that subscribed to the resource receive its updates
+		var updated mcp.ResourceSubscribeParams
+		if err := json.Unmarshal(notification.Params, &updated); err != nil {
+			return
+		}
+		if !session.IsSubscribed(targetName, updated.URI) {
+			return
+		}
+		params, err := rewriteParam(notification.Params, "uri", func(uri string) string {
+			return p.prefixedName(session, targetName, uri)
+		})
+		if err != nil {
+			log.Debug().Err(err).Str("target", targetName).Msg("Dropping malformed resources/updated notification")
+			return
+		}
+		out.Params = params
+
+	case mcp.MethodNotificationMessage:
+		// Attribute log messages to their target via the logger name
+		params, err := rewriteParam(notification.Params, "logger", func(logger string) string {
+			if logger == "" {
+				return session.GetTargetNamespace(targetName)
+			}
+			return p.prefixedName(session, targetName, logger)
+		})
+		if err != nil {
+			log.Debug().Err(err).Str("target", targetName).Msg("Dropping malformed log notification")
+			return
+		}
+		out.Params = params
+
+	default:
+		log.Debug().
+			Str("target", targetName).
+			Str("method", notification.Method).
+			Msg("Dropping unsupported upstream notification")
+		return
+	}
+
+	log.Debug().
+		Str("session_id", session.ID).
+		Str("target", targetName).
+		Str("method", notification.Method).
+		Msg("Relaying upstream notification")
+
+	// Progress for a streamed request goes on that request's response stream
+	if stream != nil && stream.Send(out) == nil {
+		return
+	}
+	session.SendNotification(out)
+}
+
+// rewriteParam applies fn to a string field of a params object while keeping
+// every other field intact. A missing field is passed to fn as "".
+func rewriteParam(raw json.RawMessage, field string, fn func(string) string) (json.RawMessage, error) {
+	params := make(map[string]json.RawMessage)
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return nil, err
+		}
+	}
+
+	var value string
+	if v, ok := params[field]; ok {
+		if err := json.Unmarshal(v, &value); err != nil {
+			return nil, err
+		}
+	}
+
+	encoded, err := json.Marshal(fn(value))
+	if err != nil {
+		return nil, err
+	}
+	params[field] = encoded
+
+	return json.Marshal(params)
+}
@@ -0,0 +1,68 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+// bindProgressToken replaces the client's progressToken in _meta with a
+// gateway-issued token. Clients choose tokens freely and upstream connections can
+// be shared between sessions, so the gateway token keeps progress notifications
+// routed to the session that asked for them, and to the request's response stream
+// when it is streamed. The returned func releases the token.
+func bindProgressToken(ctx context.Context, session *Session, meta json.RawMessage) (json.RawMessage, func()) {
+	noop := func() {}
+	if len(meta) == 0 {
+		return meta, noop
+	}
+
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(meta, &fields); err != nil {
+		return meta, noop
+	}
+	clientToken, ok := fields["progressToken"]
+	if !ok || string(clientToken) == "null" {
+		return meta, noop
+	}
+
+	gatewayToken := "ptk-" + uuid.New().String()
+	encoded, _ := json.Marshal(gatewayToken)
+	fields["progressToken"] = encoded
+
+	rewritten, err := json.Marshal(fields)
+	if err != nil {
+		return meta, noop
+	}
+
+	session.SetProgressToken(gatewayToken, clientToken, responseStreamFrom(ctx))
+	return rewritten, func() { session.RemoveProgressToken(gatewayToken) }
+}
+
+// restoreProgressToken rewrites the token of an upstream progress notification
+// back to the one the client sent, and returns the response stream of the request
+// (nil if not streamed). Returns false if the token was not issued for this
+// session (or its request already finished).
+func restoreProgressToken(session *Session, params json.RawMessage) (json.RawMessage, *ResponseStream, bool) {
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(params, &fields); err != nil {
+		return nil, nil, false
+	}
+
+	var gatewayToken string
+	if err := json.Unmarshal(fields["progressToken"], &gatewayToken); err != nil {
+		return nil, nil, false
+	}
+	clientToken, stream, ok := session.GetProgressToken(gatewayToken)
+	if !ok {
+		return nil, nil, false
+	}
+	fields["progressToken"] = clientToken
+
+	rewritten, err := json.Marshal(fields)
+	if err != nil {
+		return nil, nil, false
+	}
+	return rewritten, stream, true
+}
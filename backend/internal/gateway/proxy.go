@@ -3,14 +3,16 @@ package gateway
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
-	"strings"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/reflow/gateway/internal/auth"
 	"github.com/reflow/gateway/internal/database"
+	"github.com/reflow/gateway/internal/jsonschema"
 	"github.com/reflow/gateway/internal/k8s"
 	"github.com/reflow/gateway/internal/mcp"
 	"github.com/reflow/gateway/internal/observability"
@@ -23,9 +25,6 @@ import (
 	"go.opentelemetry.io/otel/trace"
 )
 
-// toolDelimiter separates target name from tool name (matches AgentGateway)
-const toolDelimiter = "_"
-
 // Proxy handles proxying requests to upstream MCP servers
 type Proxy struct {
 	repo         *database.Repository
@@ -34,10 +33,94 @@ type Proxy struct {
 	stdioManager *stdio.Manager
 	k8sManager   *k8s.Manager
 	obsHub       *observability.Hub
+	broker       *RequestBroker
+	redactions   redactionCache
+	limits       rateLimitCache
+	buckets      bucketStore
+	usage        usageCache
+	responses    responseStore
+	breakers     breakerSet
+	pools        endpointPools
+	config       ProxyConfig
+}
+
+// ProxyConfig holds optional proxy behavior
+type ProxyConfig struct {
+	// ValidateOutputSchema checks structuredContent in tool results against the
+	// tool's declared outputSchema and turns mismatches into tool errors.
+	ValidateOutputSchema bool
+
+	// ToolDelimiter separates a target's namespace from upstream names (default "_")
+	ToolDelimiter string
+
+	// ApprovalTimeout is how long a tools/call waits for an approver before it
+	// fails (default 5m)
+	ApprovalTimeout time.Duration
+
+	// RateLimitStore keeps rate limit buckets in "postgres" (default), shared
+	// across replicas, or in "memory"
+	RateLimitStore string
+
+	// ResponseCacheStore keeps cached upstream responses in "memory" (default)
+	// or in "postgres", shared across replicas
+	ResponseCacheStore string
+
+	// UpstreamTimeout bounds each attempt of an upstream request other than
+	// tools/call (default 30s)
+	UpstreamTimeout time.Duration
+
+	// ToolCallTimeout bounds upstream tools/call requests; 0 leaves them to
+	// the transport
+	ToolCallTimeout time.Duration
+
+	// UpstreamMaxAttempts is how often idempotent requests are tried before
+	// their failure is returned (default 3)
+	UpstreamMaxAttempts int
+
+	// RetryBackoff is the base of the jittered exponential backoff between
+	// attempts (default 200ms)
+	RetryBackoff time.Duration
+
+	// BreakerFailures is how many consecutive failures open a target's
+	// circuit breaker (default 5)
+	BreakerFailures int
+
+	// BreakerCooldown is how long an open circuit breaker fails requests fast
+	// before letting a probe through (default 30s)
+	BreakerCooldown time.Duration
+
+	// SkipUnhealthyTargets leaves targets whose latest health check failed
+	// out of new sessions
+	SkipUnhealthyTargets bool
+
+	// HealthCheckInterval is the health checker's interval; results older
+	// than a few intervals do not skip targets (default 30s)
+	HealthCheckInterval time.Duration
 }
 
 // NewProxy creates a new proxy
-func NewProxy(repo *database.Repository, encryptor *auth.TokenEncryptor, authorizer *Authorizer, stdioManager *stdio.Manager, k8sManager *k8s.Manager, obsHub *observability.Hub) *Proxy {
+func NewProxy(repo *database.Repository, encryptor *auth.TokenEncryptor, authorizer *Authorizer, stdioManager *stdio.Manager, k8sManager *k8s.Manager, obsHub *observability.Hub, cfg ProxyConfig) *Proxy {
+	if cfg.ToolDelimiter == "" {
+		cfg.ToolDelimiter = defaultToolDelimiter
+	}
+	if cfg.ApprovalTimeout == 0 {
+		cfg.ApprovalTimeout = defaultApprovalTimeout
+	}
+	if cfg.UpstreamTimeout == 0 {
+		cfg.UpstreamTimeout = defaultUpstreamTimeout
+	}
+	if cfg.UpstreamMaxAttempts == 0 {
+		cfg.UpstreamMaxAttempts = defaultUpstreamMaxAttempts
+	}
+	if cfg.RetryBackoff == 0 {
+		cfg.RetryBackoff = defaultRetryBackoff
+	}
+	if cfg.BreakerFailures == 0 {
+		cfg.BreakerFailures = defaultBreakerFailures
+	}
+	if cfg.BreakerCooldown == 0 {
+		cfg.BreakerCooldown = defaultBreakerCooldown
+	}
 	return &Proxy{
 		repo:         repo,
 		encryptor:    encryptor,
@@ -45,6 +128,10 @@ func NewProxy(repo *database.Repository, encryptor *auth.TokenEncryptor, authori
 		stdioManager: stdioManager,
 		k8sManager:   k8sManager,
 		obsHub:       obsHub,
+		broker:       NewRequestBroker(authorizer),
+		buckets:      newBucketStore(cfg.RateLimitStore, repo),
+		responses:    newResponseStore(cfg.ResponseCacheStore, repo),
+		config:       cfg,
 	}
 }
 
@@ -58,9 +145,16 @@ func (p *Proxy) InitializeSession(ctx context.Context, session *Session, params
 		return nil, fmt.Errorf("failed to get targets: %w", err)
 	}
 
+	version := mcp.NegotiateProtocolVersion(params.ProtocolVersion)
+	session.SetProtocolVersion(version)
+	span.SetAttributes(attribute.String("mcp.protocol_version", version))
+
+	clientCaps := params.Capabilities
+	session.SetClientCapabilities(&clientCaps)
+
 	if len(targets) == 0 {
 		return &mcp.InitializeResult{
-			ProtocolVersion: mcp.MCPProtocolVersion,
+			ProtocolVersion: version,
 			Capabilities:    mcp.ServerCapabilities{},
 			ServerInfo: mcp.ServerInfo{
 				Name:    "reflow-gateway",
@@ -70,6 +164,11 @@ func (p *Proxy) InitializeSession(ctx context.Context, session *Session, params
 		}, nil
 	}
 
+	var unhealthy map[uuid.UUID]bool
+	if p.config.SkipUnhealthyTargets {
+		unhealthy = p.unhealthyTargets(ctx)
+	}
+
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 	var errors []error
@@ -115,28 +214,16 @@ func (p *Proxy) InitializeSession(ctx context.Context, session *Session, params
 					Msg("User authorized for target")
 			}
 
-			var client mcp.MCPClient
-			var clientErr error
-
-			switch target.TransportType {
-			case "stdio":
-				client, clientErr = p.createStdioClient(ctx, session, target)
-			case "kubernetes":
-				client, clientErr = p.createK8sClient(ctx, session, target)
-			default:
-				client, clientErr = p.createHTTPClient(ctx, session, target)
-			}
-			if clientErr != nil {
+			if unhealthy[target.ID] {
 				mu.Lock()
-				errors = append(errors, fmt.Errorf("target %s: %w", target.Name, clientErr))
+				errors = append(errors, fmt.Errorf("target %s: skipped, failing health checks", target.Name))
 				mu.Unlock()
-				log.Error().Err(clientErr).Str("target", target.Name).Msg("Failed to create client")
+				log.Warn().Str("target", target.Name).Msg("Skipping unhealthy target")
 				return
 			}
 
-			result, err := client.Initialize(ctx, params)
+			client, result, err := p.connectTarget(ctx, session, target, p.upstreamInitializeParams(ctx, session, target.ID, params))
 			if err != nil {
-				client.Close()
 				mu.Lock()
 				errors = append(errors, fmt.Errorf("target %s: %w", target.Name, err))
 				mu.Unlock()
@@ -146,6 +233,11 @@ func (p *Proxy) InitializeSession(ctx context.Context, session *Session, params
 
 			session.SetClient(target.Name, client)
 			session.SetTargetID(target.Name, target.ID)
+			session.SetTargetNamespace(target.Name, target.Namespace)
+			session.SetArgumentValidation(target.Name, target.ArgumentValidation)
+			session.SetCacheTTL(target.Name, time.Duration(target.CacheTTLSeconds)*time.Second)
+			p.subscribeNotifications(session, target.Name, client)
+			p.subscribeRequests(session, target.Name, client)
 
 			mu.Lock()
 			authorizedTargets++
@@ -169,12 +261,16 @@ func (p *Proxy) InitializeSession(ctx context.Context, session *Session, params
 			if result.Capabilities.Logging != nil {
 				aggregatedCaps.Logging = &mcp.LoggingCapability{}
 			}
+			if result.Capabilities.Completions != nil {
+				aggregatedCaps.Completions = &mcp.CompletionsCapability{}
+			}
 			mu.Unlock()
 
 			log.Info().
 				Str("target", target.Name).
 				Str("server_name", result.ServerInfo.Name).
 				Str("server_version", result.ServerInfo.Version).
+				Str("protocol_version", result.ProtocolVersion).
 				Msg("Initialized upstream target")
 		}(target)
 	}
@@ -186,7 +282,7 @@ func (p *Proxy) InitializeSession(ctx context.Context, session *Session, params
 			return nil, fmt.Errorf("all targets failed to initialize: %v", errors)
 		}
 		return &mcp.InitializeResult{
-			ProtocolVersion: mcp.MCPProtocolVersion,
+			ProtocolVersion: version,
 			Capabilities:    mcp.ServerCapabilities{},
 			ServerInfo: mcp.ServerInfo{
 				Name:    "reflow-gateway",
@@ -199,8 +295,8 @@ func (p *Proxy) InitializeSession(ctx context.Context, session *Session, params
 	session.SetInitialized(aggregatedCaps)
 
 	return &mcp.InitializeResult{
-		ProtocolVersion: mcp.MCPProtocolVersion,
-		Capabilities:    *aggregatedCaps,
+		ProtocolVersion: version,
+		Capabilities:    downgradeServerCapabilities(version, *aggregatedCaps),
 		ServerInfo: mcp.ServerInfo{
 			Name:    "reflow-gateway",
 			Version: "1.0.0",
@@ -209,27 +305,55 @@ func (p *Proxy) InitializeSession(ctx context.Context, session *Session, params
 }
 
 // ListTools aggregates tools from all connected upstream targets.
-// Tools are prefixed with target name when there are multiple targets.
-func (p *Proxy) ListTools(ctx context.Context, session *Session) (*mcp.ToolsListResult, error) {
+// Tools are prefixed with their target's namespace (see prefixedName). Two tools
+// that end up with the same name fail the listing instead of shadowing each other;
+// the session's mappings are only updated by a listing without collisions. A
+// virtual tool shadows an upstream tool of the same name, as it does in CallTool.
+// The cursor is a composite gateway cursor (see listCursor); nil requests the first page.
+func (p *Proxy) ListTools(ctx context.Context, session *Session, cursor *string) (*mcp.ToolsListResult, error) {
 	clients := session.GetAllClients()
 	if len(clients) == 0 {
 		return &mcp.ToolsListResult{Tools: []mcp.Tool{}}, nil
 	}
 
-	multiplexing := len(clients) > 1
+	pageClients, upstreamCursors, err := pageTargets(clients, cursor)
+	if err != nil {
+		return nil, err
+	}
 
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 	var allTools []mcp.Tool
+	nextCursors := make(map[string]string)
+	claims := newNameClaims()
+	mappings := make(map[string]ToolMapping)
+
+	// Mappings accumulate across pages; only a fresh listing replaces them.
+	// Virtual tools are listed once, on the first page.
+	if cursor == nil {
+		virtualTools, err := p.listVirtualTools(ctx, session)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to list virtual tools")
+		}
+		for _, vt := range virtualTools {
+			if !claims.claim(vt.Name, virtualToolOwner+"/"+vt.Name, "") {
+				continue
+			}
+			allTools = append(allTools, mcp.Tool{
+				Name:        vt.Name,
+				Description: vt.Description,
+				InputSchema: vt.InputSchema,
+			})
+			mappings[vt.Name] = ToolMapping{ToolName: vt.Name, VirtualTool: vt}
+		}
+	}
 
-	session.ClearToolMappings()
-
-	for targetName, client := range clients {
+	for targetName, client := range pageClients {
 		wg.Add(1)
 		go func(name string, c mcp.MCPClient) {
 			defer wg.Done()
 
-			result, err := c.ListTools(ctx, nil)
+			result, err := c.ListTools(ctx, upstreamCursors[name])
 			if err != nil {
 				log.Error().Err(err).Str("target", name).Msg("Failed to list tools")
 				return
@@ -237,7 +361,17 @@ func (p *Proxy) ListTools(ctx context.Context, session *Session) (*mcp.ToolsList
 
 			targetID, _ := session.GetTargetID(name)
 
+			// Without its overrides a target could expose arguments an admin pinned
+			overrides, err := p.loadToolOverrides(ctx, targetID)
+			if err != nil {
+				log.Error().Err(err).Str("target", name).Msg("Failed to list tools")
+				return
+			}
+
 			mu.Lock()
+			if result.NextCursor != nil && *result.NextCursor != "" {
+				nextCursors[name] = *result.NextCursor
+			}
 			for _, tool := range result.Tools {
 				// Check tool-level authorization
 				if p.authorizer != nil {
@@ -247,24 +381,46 @@ func (p *Proxy) ListTools(ctx context.Context, session *Session) (*mcp.ToolsList
 					}
 				}
 
-				// Prefix tool name with target name when multiplexing
-				displayName := tool.Name
-				if multiplexing {
-					displayName = name + toolDelimiter + tool.Name
+				listed, fixedArgs, err := overrides.apply(tool)
+				if err != nil {
+					log.Error().Err(err).Str("target", name).Str("tool", tool.Name).Msg("Failed to apply tool override")
+					continue
 				}
 
-				prefixedTool := mcp.Tool{
-					Name:        displayName,
-					Description: tool.Description,
-					InputSchema: tool.InputSchema,
+				displayName := p.prefixedName(session, name, listed.Name)
+				if m, ok := mappings[displayName]; ok && m.VirtualTool != nil {
+					log.Warn().Str("target", name).Str("tool", tool.Name).Msg("Virtual tool shadows upstream tool")
+					continue
+				}
+				previousOwner := ""
+				if cursor != nil {
+					if m, ok := session.GetToolMapping(displayName); ok {
+						if m.VirtualTool != nil {
+							log.Warn().Str("target", name).Str("tool", tool.Name).Msg("Virtual tool shadows upstream tool")
+							continue
+						}
+						previousOwner = m.TargetName + "/" + m.ToolName
+					}
+				}
+				if !claims.claim(displayName, name+"/"+tool.Name, previousOwner) {
+					continue
 				}
+
+				// Copy so title, outputSchema, annotations, _meta and unknown fields survive
+				prefixedTool := listed
+				prefixedTool.Name = displayName
 				allTools = append(allTools, prefixedTool)
 
-				session.SetToolMapping(displayName, ToolMapping{
-					TargetID:   targetID,
-					TargetName: name,
-					ToolName:   tool.Name,
-				})
+				mappings[displayName] = ToolMapping{
+					TargetID:       targetID,
+					TargetName:     name,
+					ToolName:       tool.Name,
+					OutputSchema:   tool.OutputSchema,
+					InputSchema:    listed.InputSchema,
+					SchemaOverride: overrides.overridesSchema(tool.Name),
+					FixedArguments: fixedArgs,
+					CacheTTL:       toolCacheTTL(session, name, overrides[tool.Name], tool.Annotations),
+				}
 			}
 			mu.Unlock()
 		}(targetName, client)
@@ -272,7 +428,13 @@ func (p *Proxy) ListTools(ctx context.Context, session *Session) (*mcp.ToolsList
 
 	wg.Wait()
 
-	return &mcp.ToolsListResult{Tools: allTools}, nil
+	if err := claims.err("tool"); err != nil {
+		p.reportNameCollision(session, err)
+		return nil, err
+	}
+	session.StoreToolMappings(mappings, cursor == nil)
+
+	return &mcp.ToolsListResult{Tools: allTools, NextCursor: encodeListCursor(nextCursors)}, nil
 }
 
 // CallTool routes a tool call to the appropriate upstream target
@@ -287,31 +449,55 @@ func (p *Proxy) CallTool(ctx context.Context, session *Session, params *mcp.Tool
 	mapping, exists := session.GetToolMapping(params.Name)
 
 	if !exists {
-		// Try to parse the tool name as target_toolname
-		clients := session.GetAllClients()
-		if len(clients) > 1 {
-			// Multiplexing: parse prefix
-			targetName, toolName := parseResourceName(params.Name)
-			if targetName != "" {
-				targetID, _ := session.GetTargetID(targetName)
+		// Not listed yet: a virtual tool of that name takes precedence
+		vt, err := p.lookupVirtualTool(ctx, params.Name)
+		if err != nil {
+			return nil, err
+		}
+		if vt != nil {
+			mapping = ToolMapping{ToolName: vt.Name, VirtualTool: vt}
+			exists = true
+		}
+	}
+
+	if !exists {
+		// Otherwise route by the namespace prefix, undoing any rename
+		if targetName, listedName, ok := p.resolvePrefixedName(session, params.Name); ok {
+			targetID, _ := session.GetTargetID(targetName)
+			overrides, err := p.loadToolOverrides(ctx, targetID)
+			if err != nil {
+				return nil, err
+			}
+			if toolName, ok := overrides.upstreamName(listedName); ok {
 				mapping = ToolMapping{
 					TargetID:   targetID,
 					TargetName: targetName,
 					ToolName:   toolName,
 				}
-				exists = true
-			}
-		} else {
-			// Single target: use the tool name as-is
-			for name, _ := range clients {
-				targetID, _ := session.GetTargetID(name)
-				mapping = ToolMapping{
-					TargetID:   targetID,
-					TargetName: name,
-					ToolName:   params.Name,
+				if override, ok := overrides[toolName]; ok {
+					mapping.FixedArguments = override.FixedArguments
+					mapping.CacheTTL = toolCacheTTL(session, targetName, override, nil)
+					if overrides.overridesSchema(toolName) {
+						// The override's schema is enforced without listing the target
+						schema, err := hideArguments(override.InputSchema, override.FixedArguments)
+						if err != nil {
+							return nil, err
+						}
+						mapping.InputSchema = schema
+						mapping.SchemaOverride = true
+					}
 				}
 				exists = true
-				break
+
+				// Without the listed schemas the call could not be validated
+				if p.needsToolSchemas(session, targetName) {
+					if err := p.lookupToolSchemas(ctx, session, params.Name, &mapping, overrides); err != nil {
+						if session.GetArgumentValidation(targetName) == database.ArgumentValidationEnforce {
+							return mcp.NewToolCallError(fmt.Sprintf("Cannot validate arguments for tool %s: %v", params.Name, err)), nil
+						}
+						log.Warn().Err(err).Str("target", targetName).Str("tool", toolName).Msg("Failed to look up tool schemas")
+					}
+				}
 			}
 		}
 	}
@@ -320,12 +506,21 @@ func (p *Proxy) CallTool(ctx context.Context, session *Session, params *mcp.Tool
 		return mcp.NewToolCallError(fmt.Sprintf("Tool not found: %s", params.Name)), nil
 	}
 
-	// Re-check authorization
-	if p.authorizer != nil {
-		canAccess, _, err := p.authorizer.CanAccess(ctx, session.UserID, session.Role, session.Groups, &mapping.TargetID, "tool", mapping.ToolName)
-		if err != nil || !canAccess {
-			return mcp.NewToolCallError(fmt.Sprintf("Not authorized to call tool: %s", params.Name)), nil
-		}
+	if mapping.VirtualTool != nil {
+		return p.callVirtualTool(ctx, session, mapping.VirtualTool, params)
+	}
+
+	// Use original tool name (without prefix), always pass arguments (even if empty)
+	args := params.Arguments
+	if args == nil {
+		args = make(map[string]interface{})
+	}
+	callArgs := applyFixedArguments(ctx, session, args, mapping.FixedArguments)
+
+	// Re-check authorization, with conditions evaluated over the arguments as forwarded
+	denied, approvalPolicy := p.authorizeToolCall(ctx, session, mapping, params.Name, callArgs)
+	if denied != nil {
+		return denied, nil
 	}
 
 	client := session.GetClient(mapping.TargetName)
@@ -335,17 +530,56 @@ func (p *Proxy) CallTool(ctx context.Context, session *Session, params *mcp.Tool
 
 	span.SetAttributes(attribute.String("tool.target", mapping.TargetName))
 
-	// Use original tool name (without prefix), always pass arguments (even if empty)
-	args := params.Arguments
-	if args == nil {
-		args = make(map[string]interface{})
+	// Validate what the client sent, before fixed arguments are added
+	if invalid := p.checkArguments(ctx, session, mapping, params.Name, args); invalid != nil {
+		p.emitActivity(ctx, callStart, session, "tools/call", mapping.TargetName, mapping.ToolName, "error")
+		return invalid, nil
+	}
+	if approvalPolicy != nil {
+		if rejected := p.awaitApproval(ctx, session, approvalPolicy, mapping, params.Name, callArgs); rejected != nil {
+			p.emitActivity(ctx, callStart, session, "tools/call", mapping.TargetName, mapping.ToolName, "error")
+			return rejected, nil
+		}
 	}
+
+	// Limits are checked once the call will go ahead: a rejected call takes no
+	// tokens, and quotas see the usage recorded while approval was pending
+	if err := p.checkRateLimits(ctx, session, mapping); err != nil {
+		p.emitActivity(ctx, callStart, session, "tools/call", mapping.TargetName, mapping.ToolName, "error")
+		return nil, err
+	}
+	quotas, err := p.checkQuotas(ctx, session, mapping.TargetID, mapping.TargetName)
+	if err != nil {
+		p.emitActivity(ctx, callStart, session, "tools/call", mapping.TargetName, mapping.ToolName, "error")
+		return nil, err
+	}
+	meta, releaseProgress := bindProgressToken(ctx, session, params.Meta)
+	defer releaseProgress()
+
 	originalParams := &mcp.ToolCallParams{
 		Name:      mapping.ToolName,
-		Arguments: args,
+		Arguments: callArgs,
+		Meta:      meta,
 	}
 
-	result, err := client.CallTool(ctx, originalParams)
+	// Results are cached as the client receives them: validated and redacted.
+	// Cached results skip the upstream and are metered at no cost.
+	var result *mcp.ToolCallResult
+	cached := p.cacheableRequest(session, mapping.CacheTTL, mapping.TargetID, mapping.TargetName, mcp.MethodToolsCall, mapping.ToolName, callArgs)
+	cacheStart := time.Now()
+	hit := p.loadCached(ctx, cached, &result)
+	if hit {
+		p.recordCacheHit(ctx, session, cacheStart, mcp.MethodToolsCall, mapping.TargetID, mapping.TargetName, mapping.ToolName, callArgs, result)
+	} else {
+		upstreamStart := time.Now()
+		result, err = client.CallTool(ctx, originalParams)
+
+		metered := usageStatus(err)
+		if result != nil && result.IsError {
+			metered = "error"
+		}
+		p.recordUsage(ctx, session, quotas, upstreamStart, mcp.MethodToolsCall, mapping.TargetID, mapping.TargetName, mapping.ToolName, callArgs, result, metered)
+	}
 
 	// Record tool call metrics
 	callDuration := float64(time.Since(callStart).Milliseconds())
@@ -364,34 +598,79 @@ func (p *Proxy) CallTool(ctx context.Context, session *Session, params *mcp.Tool
 	if err != nil {
 		span.SetStatus(codes.Error, err.Error())
 		p.emitActivity(ctx, callStart, session, "tools/call", mapping.TargetName, mapping.ToolName, "error")
-	} else {
+		return result, err
+	}
+	if hit {
 		p.emitActivity(ctx, callStart, session, "tools/call", mapping.TargetName, mapping.ToolName, "ok")
+		return result, nil
+	}
+
+	if p.config.ValidateOutputSchema {
+		if verr := validateStructuredContent(mapping, result); verr != nil {
+			log.Warn().
+				Err(verr).
+				Str("target", mapping.TargetName).
+				Str("tool", mapping.ToolName).
+				Msg("Tool result does not match its outputSchema")
+			p.emitActivity(ctx, callStart, session, "tools/call", mapping.TargetName, mapping.ToolName, "error")
+			return mcp.NewToolCallError(fmt.Sprintf("Tool %s returned structured content that does not match its output schema: %v", params.Name, verr)), nil
+		}
 	}
 
-	return result, err
+	result, err = p.redactToolResult(ctx, session, mapping, params.Name, result)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		p.emitActivity(ctx, callStart, session, "tools/call", mapping.TargetName, mapping.ToolName, "error")
+		return nil, err
+	}
+	if result != nil && !result.IsError {
+		p.storeCached(ctx, cached, result)
+	}
+
+	p.emitActivity(ctx, callStart, session, "tools/call", mapping.TargetName, mapping.ToolName, "ok")
+	return result, nil
 }
 
-// ListResources aggregates resources from all connected upstream targets
-func (p *Proxy) ListResources(ctx context.Context, session *Session) (*mcp.ResourcesListResult, error) {
+// validateStructuredContent checks a successful tool result against the tool's
+// declared outputSchema. Results without structured content, error results and
+// tools without an outputSchema are not checked.
+func validateStructuredContent(mapping ToolMapping, result *mcp.ToolCallResult) error {
+	if result == nil || result.IsError || len(mapping.OutputSchema) == 0 {
+		return nil
+	}
+	if len(result.StructuredContent) == 0 {
+		return fmt.Errorf("structuredContent is missing")
+	}
+	return jsonschema.Validate(mapping.OutputSchema, result.StructuredContent)
+}
+
+// ListResources aggregates resources from all connected upstream targets.
+// The cursor is a composite gateway cursor (see listCursor); nil requests the first page.
+func (p *Proxy) ListResources(ctx context.Context, session *Session, cursor *string) (*mcp.ResourcesListResult, error) {
 	clients := session.GetAllClients()
 	if len(clients) == 0 {
 		return &mcp.ResourcesListResult{Resources: []mcp.Resource{}}, nil
 	}
 
-	multiplexing := len(clients) > 1
+	pageClients, upstreamCursors, err := pageTargets(clients, cursor)
+	if err != nil {
+		return nil, err
+	}
 
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 	var allResources []mcp.Resource
+	nextCursors := make(map[string]string)
+	claims := newNameClaims()
+	// Mappings accumulate across pages; only a fresh listing replaces them
+	mappings := make(map[string]ResourceMapping)
 
-	session.ClearResourceMappings()
-
-	for targetName, client := range clients {
+	for targetName, client := range pageClients {
 		wg.Add(1)
 		go func(name string, c mcp.MCPClient) {
 			defer wg.Done()
 
-			result, err := c.ListResources(ctx, nil)
+			result, err := c.ListResources(ctx, upstreamCursors[name])
 			if err != nil {
 				log.Error().Err(err).Str("target", name).Msg("Failed to list resources")
 				return
@@ -400,6 +679,9 @@ func (p *Proxy) ListResources(ctx context.Context, session *Session) (*mcp.Resou
 			targetID, _ := session.GetTargetID(name)
 
 			mu.Lock()
+			if result.NextCursor != nil && *result.NextCursor != "" {
+				nextCursors[name] = *result.NextCursor
+			}
 			for _, resource := range result.Resources {
 				if p.authorizer != nil {
 					canAccess, _, err := p.authorizer.CanAccess(ctx, session.UserID, session.Role, session.Groups, &targetID, "resource", resource.URI)
@@ -408,24 +690,112 @@ func (p *Proxy) ListResources(ctx context.Context, session *Session) (*mcp.Resou
 					}
 				}
 
-				displayURI := resource.URI
-				if multiplexing {
-					displayURI = name + toolDelimiter + resource.URI
+				displayURI := p.prefixedName(session, name, resource.URI)
+				previousOwner := ""
+				if m, ok := session.GetResourceMapping(displayURI); ok && cursor != nil {
+					previousOwner = m.TargetName + "/" + m.URI
 				}
-
-				prefixedResource := mcp.Resource{
-					URI:         displayURI,
-					Name:        resource.Name,
-					Description: resource.Description,
-					MimeType:    resource.MimeType,
+				if !claims.claim(displayURI, name+"/"+resource.URI, previousOwner) {
+					continue
 				}
+
+				prefixedResource := resource
+				prefixedResource.URI = displayURI
 				allResources = append(allResources, prefixedResource)
 
-				session.SetResourceMapping(displayURI, ResourceMapping{
+				mappings[displayURI] = ResourceMapping{
 					TargetID:   targetID,
 					TargetName: name,
 					URI:        resource.URI,
+				}
+			}
+			mu.Unlock()
+		}(targetName, client)
+	}
+
+	wg.Wait()
+
+	if err := claims.err("resource"); err != nil {
+		p.reportNameCollision(session, err)
+		return nil, err
+	}
+	session.StoreResourceMappings(mappings, cursor == nil)
+
+	return &mcp.ResourcesListResult{Resources: allResources, NextCursor: encodeListCursor(nextCursors)}, nil
+}
+
+// ListResourceTemplates aggregates resource templates from all connected upstream targets.
+// Templates are prefixed like resources, so expanded URIs route back to their target.
+func (p *Proxy) ListResourceTemplates(ctx context.Context, session *Session, cursor *string) (*mcp.ResourceTemplatesListResult, error) {
+	clients := session.GetAllClients()
+	if len(clients) == 0 {
+		return &mcp.ResourceTemplatesListResult{ResourceTemplates: []mcp.ResourceTemplate{}}, nil
+	}
+
+	pageClients, upstreamCursors, err := pageTargets(clients, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var allTemplates []mcp.ResourceTemplate
+	nextCursors := make(map[string]string)
+	claims := newNameClaims()
+	// Mappings accumulate across pages; only a fresh listing replaces them
+	mappings := make(map[string]ResourceTemplateMapping)
+
+	for targetName, client := range pageClients {
+		wg.Add(1)
+		go func(name string, c mcp.MCPClient) {
+			defer wg.Done()
+
+			result, err := c.ListResourceTemplates(ctx, upstreamCursors[name])
+			if err != nil {
+				log.Error().Err(err).Str("target", name).Msg("Failed to list resource templates")
+				return
+			}
+
+			targetID, _ := session.GetTargetID(name)
+
+			prefix := p.prefixedName(session, name, "")
+
+			mu.Lock()
+			if result.NextCursor != nil && *result.NextCursor != "" {
+				nextCursors[name] = *result.NextCursor
+			}
+			for _, template := range result.ResourceTemplates {
+				if p.authorizer != nil {
+					canAccess, _, err := p.authorizer.CanAccess(ctx, session.UserID, session.Role, session.Groups, &targetID, "resource", template.URITemplate)
+					if err != nil || !canAccess {
+						continue
+					}
+				}
+
+				displayTemplate := prefix + template.URITemplate
+				previousOwner := ""
+				if m, ok := session.GetResourceTemplateMapping(displayTemplate); ok && cursor != nil {
+					previousOwner = m.TargetName + "/" + m.URITemplate
+				}
+				if !claims.claim(displayTemplate, name+"/"+template.URITemplate, previousOwner) {
+					continue
+				}
+
+				mapping, err := newResourceTemplateMapping(displayTemplate, ResourceTemplateMapping{
+					TargetID:    targetID,
+					TargetName:  name,
+					URITemplate: template.URITemplate,
+					Prefix:      prefix,
 				})
+				if err != nil {
+					log.Warn().Err(err).Str("target", name).Msg("Skipping malformed resource template")
+					continue
+				}
+				mappings[displayTemplate] = mapping
+
+				prefixedTemplate := template
+				prefixedTemplate.URITemplate = displayTemplate
+				allTemplates = append(allTemplates, prefixedTemplate)
 			}
 			mu.Unlock()
 		}(targetName, client)
@@ -433,18 +803,65 @@ func (p *Proxy) ListResources(ctx context.Context, session *Session) (*mcp.Resou
 
 	wg.Wait()
 
-	return &mcp.ResourcesListResult{Resources: allResources}, nil
+	if err := claims.err("resource template"); err != nil {
+		p.reportNameCollision(session, err)
+		return nil, err
+	}
+	session.StoreResourceTemplateMappings(mappings, cursor == nil)
+
+	return &mcp.ResourceTemplatesListResult{ResourceTemplates: allTemplates, NextCursor: encodeListCursor(nextCursors)}, nil
+}
+
+// resolveResource maps a client-visible URI to its upstream target. URIs that
+// were not listed directly are matched against listed resource templates, and
+// failing that routed by their namespace prefix.
+func (p *Proxy) resolveResource(session *Session, uri string) (ResourceMapping, bool) {
+	mapping, exists := session.GetResourceMapping(uri)
+	if !exists {
+		mapping, exists = session.MatchResourceTemplate(uri)
+	}
+	if !exists {
+		if targetName, upstreamURI, ok := p.resolvePrefixedName(session, uri); ok {
+			targetID, _ := session.GetTargetID(targetName)
+			mapping = ResourceMapping{
+				TargetID:   targetID,
+				TargetName: targetName,
+				URI:        upstreamURI,
+			}
+			exists = true
+		}
+	}
+	return mapping, exists
+}
+
+// resolvePrompt maps a client-visible prompt name to its upstream target, routing
+// names that were not listed by their namespace prefix.
+func (p *Proxy) resolvePrompt(session *Session, name string) (PromptMapping, bool) {
+	mapping, exists := session.GetPromptMapping(name)
+	if !exists {
+		if targetName, promptName, ok := p.resolvePrefixedName(session, name); ok {
+			targetID, _ := session.GetTargetID(targetName)
+			mapping = PromptMapping{
+				TargetID:   targetID,
+				TargetName: targetName,
+				PromptName: promptName,
+			}
+			exists = true
+		}
+	}
+	return mapping, exists
 }
 
 // ReadResource routes a resource read to the appropriate upstream target
 func (p *Proxy) ReadResource(ctx context.Context, session *Session, uri string) (*mcp.ResourceReadResult, error) {
-	mapping, exists := session.GetResourceMapping(uri)
+	mapping, exists := p.resolveResource(session, uri)
 	if !exists {
 		return nil, fmt.Errorf("resource not found: %s", uri)
 	}
 
 	if p.authorizer != nil {
-		canAccess, _, err := p.authorizer.CanAccess(ctx, session.UserID, session.Role, session.Groups, &mapping.TargetID, "resource", mapping.URI)
+		input := requestInput(ctx, mapping.TargetName, nil)
+		canAccess, _, err := p.authorizer.CanAccessWith(ctx, session.UserID, session.Role, session.Groups, &mapping.TargetID, "resource", mapping.URI, input)
 		if err != nil || !canAccess {
 			return nil, fmt.Errorf("not authorized to read resource: %s", uri)
 		}
@@ -455,30 +872,104 @@ func (p *Proxy) ReadResource(ctx context.Context, session *Session, uri string)
 		return nil, fmt.Errorf("target not connected: %s", mapping.TargetName)
 	}
 
-	return client.ReadResource(ctx, mapping.URI)
+	quotas, err := p.checkQuotas(ctx, session, mapping.TargetID, mapping.TargetName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Contents are cached redacted, as the client receives them
+	var result *mcp.ResourceReadResult
+	cached := p.cacheableRequest(session, session.GetCacheTTL(mapping.TargetName), mapping.TargetID, mapping.TargetName, mcp.MethodResourcesRead, mapping.URI, nil)
+	start := time.Now()
+	if p.loadCached(ctx, cached, &result) {
+		p.recordCacheHit(ctx, session, start, mcp.MethodResourcesRead, mapping.TargetID, mapping.TargetName, mapping.URI, nil, result)
+		return result, nil
+	}
+
+	result, err = client.ReadResource(ctx, mapping.URI)
+	p.recordUsage(ctx, session, quotas, start, mcp.MethodResourcesRead, mapping.TargetID, mapping.TargetName, mapping.URI, nil, result, usageStatus(err))
+	if err != nil {
+		return nil, err
+	}
+	result, err = p.redactResourceContents(ctx, session, mapping, uri, result)
+	if err != nil {
+		return nil, err
+	}
+	p.storeCached(ctx, cached, result)
+	return result, nil
 }
 
-// ListPrompts aggregates prompts from all connected upstream targets
-func (p *Proxy) ListPrompts(ctx context.Context, session *Session) (*mcp.PromptsListResult, error) {
+// SubscribeResource subscribes the session to updates of a resource on its owning target
+func (p *Proxy) SubscribeResource(ctx context.Context, session *Session, uri string) error {
+	mapping, exists := p.resolveResource(session, uri)
+	if !exists {
+		return fmt.Errorf("resource not found: %s", uri)
+	}
+
+	if p.authorizer != nil {
+		input := requestInput(ctx, mapping.TargetName, nil)
+		canAccess, _, err := p.authorizer.CanAccessWith(ctx, session.UserID, session.Role, session.Groups, &mapping.TargetID, "resource", mapping.URI, input)
+		if err != nil || !canAccess {
+			return fmt.Errorf("not authorized to subscribe to resource: %s", uri)
+		}
+	}
+
+	client := session.GetClient(mapping.TargetName)
+	if client == nil {
+		return fmt.Errorf("target not connected: %s", mapping.TargetName)
+	}
+	if caps := client.GetCapabilities(); caps == nil || caps.Resources == nil || !caps.Resources.Subscribe {
+		return fmt.Errorf("target does not support resource subscriptions: %s", mapping.TargetName)
+	}
+
+	if err := client.SubscribeResource(ctx, session.ID, mapping.URI); err != nil {
+		return err
+	}
+	session.AddSubscription(uri, mapping)
+	return nil
+}
+
+// UnsubscribeResource cancels a session's subscription to a resource
+func (p *Proxy) UnsubscribeResource(ctx context.Context, session *Session, uri string) error {
+	mapping, exists := session.RemoveSubscription(uri)
+	if !exists {
+		return fmt.Errorf("not subscribed to resource: %s", uri)
+	}
+
+	client := session.GetClient(mapping.TargetName)
+	if client == nil {
+		return nil
+	}
+	return client.UnsubscribeResource(ctx, session.ID, mapping.URI)
+}
+
+// ListPrompts aggregates prompts from all connected upstream targets.
+// The cursor is a composite gateway cursor (see listCursor); nil requests the first page.
+func (p *Proxy) ListPrompts(ctx context.Context, session *Session, cursor *string) (*mcp.PromptsListResult, error) {
 	clients := session.GetAllClients()
 	if len(clients) == 0 {
 		return &mcp.PromptsListResult{Prompts: []mcp.Prompt{}}, nil
 	}
 
-	multiplexing := len(clients) > 1
+	pageClients, upstreamCursors, err := pageTargets(clients, cursor)
+	if err != nil {
+		return nil, err
+	}
 
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 	var allPrompts []mcp.Prompt
+	nextCursors := make(map[string]string)
+	claims := newNameClaims()
+	// Mappings accumulate across pages; only a fresh listing replaces them
+	mappings := make(map[string]PromptMapping)
 
-	session.ClearPromptMappings()
-
-	for targetName, client := range clients {
+	for targetName, client := range pageClients {
 		wg.Add(1)
 		go func(name string, c mcp.MCPClient) {
 			defer wg.Done()
 
-			result, err := c.ListPrompts(ctx, nil)
+			result, err := c.ListPrompts(ctx, upstreamCursors[name])
 			if err != nil {
 				log.Error().Err(err).Str("target", name).Msg("Failed to list prompts")
 				return
@@ -487,6 +978,9 @@ func (p *Proxy) ListPrompts(ctx context.Context, session *Session) (*mcp.Prompts
 			targetID, _ := session.GetTargetID(name)
 
 			mu.Lock()
+			if result.NextCursor != nil && *result.NextCursor != "" {
+				nextCursors[name] = *result.NextCursor
+			}
 			for _, prompt := range result.Prompts {
 				if p.authorizer != nil {
 					canAccess, _, err := p.authorizer.CanAccess(ctx, session.UserID, session.Role, session.Groups, &targetID, "prompt", prompt.Name)
@@ -495,23 +989,24 @@ func (p *Proxy) ListPrompts(ctx context.Context, session *Session) (*mcp.Prompts
 					}
 				}
 
-				displayName := prompt.Name
-				if multiplexing {
-					displayName = name + toolDelimiter + prompt.Name
+				displayName := p.prefixedName(session, name, prompt.Name)
+				previousOwner := ""
+				if m, ok := session.GetPromptMapping(displayName); ok && cursor != nil {
+					previousOwner = m.TargetName + "/" + m.PromptName
 				}
-
-				prefixedPrompt := mcp.Prompt{
-					Name:        displayName,
-					Description: prompt.Description,
-					Arguments:   prompt.Arguments,
+				if !claims.claim(displayName, name+"/"+prompt.Name, previousOwner) {
+					continue
 				}
+
+				prefixedPrompt := prompt
+				prefixedPrompt.Name = displayName
 				allPrompts = append(allPrompts, prefixedPrompt)
 
-				session.SetPromptMapping(displayName, PromptMapping{
+				mappings[displayName] = PromptMapping{
 					TargetID:   targetID,
 					TargetName: name,
 					PromptName: prompt.Name,
-				})
+				}
 			}
 			mu.Unlock()
 		}(targetName, client)
@@ -519,18 +1014,29 @@ func (p *Proxy) ListPrompts(ctx context.Context, session *Session) (*mcp.Prompts
 
 	wg.Wait()
 
-	return &mcp.PromptsListResult{Prompts: allPrompts}, nil
+	if err := claims.err("prompt"); err != nil {
+		p.reportNameCollision(session, err)
+		return nil, err
+	}
+	session.StorePromptMappings(mappings, cursor == nil)
+
+	return &mcp.PromptsListResult{Prompts: allPrompts, NextCursor: encodeListCursor(nextCursors)}, nil
 }
 
 // GetPrompt routes a prompt get to the appropriate upstream target
 func (p *Proxy) GetPrompt(ctx context.Context, session *Session, params *mcp.PromptGetParams) (*mcp.PromptGetResult, error) {
-	mapping, exists := session.GetPromptMapping(params.Name)
+	mapping, exists := p.resolvePrompt(session, params.Name)
 	if !exists {
 		return nil, fmt.Errorf("prompt not found: %s", params.Name)
 	}
 
 	if p.authorizer != nil {
-		canAccess, _, err := p.authorizer.CanAccess(ctx, session.UserID, session.Role, session.Groups, &mapping.TargetID, "prompt", mapping.PromptName)
+		args := make(map[string]interface{}, len(params.Arguments))
+		for name, value := range params.Arguments {
+			args[name] = value
+		}
+		input := requestInput(ctx, mapping.TargetName, args)
+		canAccess, _, err := p.authorizer.CanAccessWith(ctx, session.UserID, session.Role, session.Groups, &mapping.TargetID, "prompt", mapping.PromptName, input)
 		if err != nil || !canAccess {
 			return nil, fmt.Errorf("not authorized to get prompt: %s", params.Name)
 		}
@@ -541,12 +1047,132 @@ func (p *Proxy) GetPrompt(ctx context.Context, session *Session, params *mcp.Pro
 		return nil, fmt.Errorf("target not connected: %s", mapping.TargetName)
 	}
 
+	quotas, err := p.checkQuotas(ctx, session, mapping.TargetID, mapping.TargetName)
+	if err != nil {
+		return nil, err
+	}
+
+	meta, releaseProgress := bindProgressToken(ctx, session, params.Meta)
+	defer releaseProgress()
+
 	originalParams := &mcp.PromptGetParams{
 		Name:      mapping.PromptName,
 		Arguments: params.Arguments,
+		Meta:      meta,
+	}
+
+	// Messages are cached redacted, as the client receives them
+	var result *mcp.PromptGetResult
+	cached := p.cacheableRequest(session, session.GetCacheTTL(mapping.TargetName), mapping.TargetID, mapping.TargetName, mcp.MethodPromptsGet, mapping.PromptName, params.Arguments)
+	start := time.Now()
+	if p.loadCached(ctx, cached, &result) {
+		p.recordCacheHit(ctx, session, start, mcp.MethodPromptsGet, mapping.TargetID, mapping.TargetName, mapping.PromptName, params.Arguments, result)
+		return result, nil
+	}
+
+	result, err = client.GetPrompt(ctx, originalParams)
+	p.recordUsage(ctx, session, quotas, start, mcp.MethodPromptsGet, mapping.TargetID, mapping.TargetName, mapping.PromptName, params.Arguments, result, usageStatus(err))
+	if err != nil {
+		return nil, err
+	}
+	result, err = p.redactPromptMessages(ctx, session, mapping, params.Name, result)
+	if err != nil {
+		return nil, err
+	}
+	p.storeCached(ctx, cached, result)
+	return result, nil
+}
+
+// Complete routes a completion request to the target that owns the referenced
+// prompt or resource template, rewriting the reference to its upstream name.
+func (p *Proxy) Complete(ctx context.Context, session *Session, params *mcp.CompleteParams) (*mcp.CompleteResult, error) {
+	var targetName, resourceType, resourceName string
+	var targetID uuid.UUID
+	upstreamRef := params.Ref
+
+	switch params.Ref.Type {
+	case mcp.CompletionRefPrompt:
+		mapping, exists := p.resolvePrompt(session, params.Ref.Name)
+		if !exists {
+			return nil, fmt.Errorf("prompt not found: %s", params.Ref.Name)
+		}
+		targetName, targetID = mapping.TargetName, mapping.TargetID
+		resourceType, resourceName = "prompt", mapping.PromptName
+		upstreamRef.Name = mapping.PromptName
+
+	case mcp.CompletionRefResource:
+		if mapping, exists := session.GetResourceTemplateMapping(params.Ref.URI); exists {
+			targetName, targetID = mapping.TargetName, mapping.TargetID
+			resourceName = mapping.URITemplate
+		} else if mapping, exists := session.GetResourceMapping(params.Ref.URI); exists {
+			targetName, targetID = mapping.TargetName, mapping.TargetID
+			resourceName = mapping.URI
+		} else {
+			return nil, fmt.Errorf("resource not found: %s", params.Ref.URI)
+		}
+		resourceType = "resource"
+		upstreamRef.URI = resourceName
+
+	default:
+		return nil, fmt.Errorf("unsupported completion reference type: %s", params.Ref.Type)
+	}
+
+	if p.authorizer != nil {
+		input := requestInput(ctx, targetName, nil)
+		canAccess, _, err := p.authorizer.CanAccessWith(ctx, session.UserID, session.Role, session.Groups, &targetID, resourceType, resourceName, input)
+		if err != nil || !canAccess {
+			return nil, fmt.Errorf("not authorized to complete %s: %s", resourceType, resourceName)
+		}
+	}
+
+	client := session.GetClient(targetName)
+	if client == nil {
+		return nil, fmt.Errorf("target not connected: %s", targetName)
+	}
+
+	originalParams := &mcp.CompleteParams{
+		Ref:      upstreamRef,
+		Argument: params.Argument,
+		Context:  params.Context,
+	}
+	downgradeCompleteParams(client.ProtocolVersion(), originalParams)
+
+	return client.Complete(ctx, originalParams)
+}
+
+// SetLoggingLevel fans a logging level change out to every connected target that
+// advertised the logging capability. It fails only if no target accepted the change.
+func (p *Proxy) SetLoggingLevel(ctx context.Context, session *Session, level string) error {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var errors []error
+	var attempted int
+
+	for targetName, client := range session.GetAllClients() {
+		if caps := client.GetCapabilities(); caps == nil || caps.Logging == nil {
+			continue
+		}
+		attempted++
+
+		wg.Add(1)
+		go func(name string, c mcp.MCPClient) {
+			defer wg.Done()
+
+			if err := c.SetLoggingLevel(ctx, level); err != nil {
+				log.Warn().Err(err).Str("target", name).Msg("Failed to set upstream logging level")
+				mu.Lock()
+				errors = append(errors, fmt.Errorf("target %s: %w", name, err))
+				mu.Unlock()
+			}
+		}(targetName, client)
 	}
 
-	return client.GetPrompt(ctx, originalParams)
+	wg.Wait()
+
+	if attempted > 0 && len(errors) == attempted {
+		return fmt.Errorf("all targets failed to set logging level: %v", errors)
+	}
+	return nil
 }
 
 // ForwardRequest forwards a raw request to all targets or a specific target
@@ -573,11 +1199,16 @@ func (p *Proxy) HandleRequest(ctx context.Context, session *Session, req *mcp.JS
 
 	switch req.Method {
 	case mcp.MethodToolsList:
-		result, err := p.ListTools(ctx, session)
+		cursor, ok := parseCursor(req.Params)
+		if !ok {
+			return mcp.NewErrorResponse(req.ID, mcp.InvalidParams, "Invalid cursor"), nil
+		}
+		result, err := p.ListTools(ctx, session, cursor)
 		if err != nil {
 			p.emitActivity(ctx, start, session, req.Method, "", "", "error")
 			return mcp.NewErrorResponse(req.ID, mcp.InternalError, err.Error()), nil
 		}
+		downgradeTools(session.ProtocolVersion(), result.Tools)
 		p.emitActivity(ctx, start, session, req.Method, "", "", "ok")
 		return mcp.NewSuccessResponse(req.ID, result)
 
@@ -589,16 +1220,36 @@ func (p *Proxy) HandleRequest(ctx context.Context, session *Session, req *mcp.JS
 		}
 		result, err := p.CallTool(ctx, session, &params)
 		if err != nil {
-			return mcp.NewErrorResponse(req.ID, mcp.InternalError, err.Error()), nil
+			return routingErrorResponse(req.ID, err), nil
 		}
+		downgradeToolCallResult(session.ProtocolVersion(), result)
 		return mcp.NewSuccessResponse(req.ID, result)
 
 	case mcp.MethodResourcesList:
-		result, err := p.ListResources(ctx, session)
+		cursor, ok := parseCursor(req.Params)
+		if !ok {
+			return mcp.NewErrorResponse(req.ID, mcp.InvalidParams, "Invalid cursor"), nil
+		}
+		result, err := p.ListResources(ctx, session, cursor)
 		if err != nil {
 			p.emitActivity(ctx, start, session, req.Method, "", "", "error")
 			return mcp.NewErrorResponse(req.ID, mcp.InternalError, err.Error()), nil
 		}
+		downgradeResources(session.ProtocolVersion(), result.Resources)
+		p.emitActivity(ctx, start, session, req.Method, "", "", "ok")
+		return mcp.NewSuccessResponse(req.ID, result)
+
+	case mcp.MethodResourcesTemplates:
+		cursor, ok := parseCursor(req.Params)
+		if !ok {
+			return mcp.NewErrorResponse(req.ID, mcp.InvalidParams, "Invalid cursor"), nil
+		}
+		result, err := p.ListResourceTemplates(ctx, session, cursor)
+		if err != nil {
+			p.emitActivity(ctx, start, session, req.Method, "", "", "error")
+			return mcp.NewErrorResponse(req.ID, mcp.InternalError, err.Error()), nil
+		}
+		downgradeResourceTemplates(session.ProtocolVersion(), result.ResourceTemplates)
 		p.emitActivity(ctx, start, session, req.Method, "", "", "ok")
 		return mcp.NewSuccessResponse(req.ID, result)
 
@@ -610,17 +1261,40 @@ func (p *Proxy) HandleRequest(ctx context.Context, session *Session, req *mcp.JS
 		result, err := p.ReadResource(ctx, session, params.URI)
 		if err != nil {
 			p.emitActivity(ctx, start, session, req.Method, "", "", "error")
-			return mcp.NewErrorResponse(req.ID, mcp.InternalError, err.Error()), nil
+			return routingErrorResponse(req.ID, err), nil
 		}
 		p.emitActivity(ctx, start, session, req.Method, "", "", "ok")
 		return mcp.NewSuccessResponse(req.ID, result)
 
+	case mcp.MethodResourcesSubscribe, mcp.MethodResourcesUnsubscribe:
+		var params mcp.ResourceSubscribeParams
+		if err := json.Unmarshal(req.Params, &params); err != nil || params.URI == "" {
+			return mcp.NewErrorResponse(req.ID, mcp.InvalidParams, "Invalid params"), nil
+		}
+		var err error
+		if req.Method == mcp.MethodResourcesSubscribe {
+			err = p.SubscribeResource(ctx, session, params.URI)
+		} else {
+			err = p.UnsubscribeResource(ctx, session, params.URI)
+		}
+		if err != nil {
+			p.emitActivity(ctx, start, session, req.Method, "", "", "error")
+			return mcp.NewErrorResponse(req.ID, mcp.InternalError, err.Error()), nil
+		}
+		p.emitActivity(ctx, start, session, req.Method, "", "", "ok")
+		return mcp.NewSuccessResponse(req.ID, map[string]interface{}{})
+
 	case mcp.MethodPromptsList:
-		result, err := p.ListPrompts(ctx, session)
+		cursor, ok := parseCursor(req.Params)
+		if !ok {
+			return mcp.NewErrorResponse(req.ID, mcp.InvalidParams, "Invalid cursor"), nil
+		}
+		result, err := p.ListPrompts(ctx, session, cursor)
 		if err != nil {
 			p.emitActivity(ctx, start, session, req.Method, "", "", "error")
 			return mcp.NewErrorResponse(req.ID, mcp.InternalError, err.Error()), nil
 		}
+		downgradePrompts(session.ProtocolVersion(), result.Prompts)
 		p.emitActivity(ctx, start, session, req.Method, "", "", "ok")
 		return mcp.NewSuccessResponse(req.ID, result)
 
@@ -630,6 +1304,20 @@ func (p *Proxy) HandleRequest(ctx context.Context, session *Session, req *mcp.JS
 			return mcp.NewErrorResponse(req.ID, mcp.InvalidParams, "Invalid params"), nil
 		}
 		result, err := p.GetPrompt(ctx, session, &params)
+		if err != nil {
+			p.emitActivity(ctx, start, session, req.Method, "", "", "error")
+			return routingErrorResponse(req.ID, err), nil
+		}
+		downgradePromptGetResult(session.ProtocolVersion(), result)
+		p.emitActivity(ctx, start, session, req.Method, "", "", "ok")
+		return mcp.NewSuccessResponse(req.ID, result)
+
+	case mcp.MethodCompletionComplete:
+		var params mcp.CompleteParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return mcp.NewErrorResponse(req.ID, mcp.InvalidParams, "Invalid params"), nil
+		}
+		result, err := p.Complete(ctx, session, &params)
 		if err != nil {
 			p.emitActivity(ctx, start, session, req.Method, "", "", "error")
 			return mcp.NewErrorResponse(req.ID, mcp.InternalError, err.Error()), nil
@@ -637,6 +1325,18 @@ func (p *Proxy) HandleRequest(ctx context.Context, session *Session, req *mcp.JS
 		p.emitActivity(ctx, start, session, req.Method, "", "", "ok")
 		return mcp.NewSuccessResponse(req.ID, result)
 
+	case mcp.MethodLoggingSetLevel:
+		var params mcp.SetLevelParams
+		if err := json.Unmarshal(req.Params, &params); err != nil || params.Level == "" {
+			return mcp.NewErrorResponse(req.ID, mcp.InvalidParams, "Invalid params"), nil
+		}
+		if err := p.SetLoggingLevel(ctx, session, params.Level); err != nil {
+			p.emitActivity(ctx, start, session, req.Method, "", "", "error")
+			return mcp.NewErrorResponse(req.ID, mcp.InternalError, err.Error()), nil
+		}
+		p.emitActivity(ctx, start, session, req.Method, "", "", "ok")
+		return mcp.NewSuccessResponse(req.ID, map[string]interface{}{})
+
 	case mcp.MethodPing:
 		return mcp.NewSuccessResponse(req.ID, map[string]interface{}{})
 
@@ -645,6 +1345,25 @@ func (p *Proxy) HandleRequest(ctx context.Context, session *Session, req *mcp.JS
 	}
 }
 
+// parseCursor extracts the optional pagination cursor from list request params.
+// Returns false when the params or the cursor itself are malformed.
+func parseCursor(raw json.RawMessage) (*string, bool) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, true
+	}
+	var params mcp.PaginatedParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, false
+	}
+	if params.Cursor == nil || *params.Cursor == "" {
+		return nil, true
+	}
+	if _, err := decodeListCursor(*params.Cursor); err != nil {
+		return nil, false
+	}
+	return params.Cursor, true
+}
+
 // emitActivity publishes an activity event via the observability hub.
 func (p *Proxy) emitActivity(ctx context.Context, start time.Time, session *Session, method, target, tool, status string) {
 	if p.obsHub == nil {
@@ -668,19 +1387,84 @@ func (p *Proxy) emitActivity(ctx context.Context, start time.Time, session *Sess
 	})
 }
 
+// routingErrorResponse converts an error from routing a request upstream into a
+// JSON-RPC error, with details for clients to back off from limits they hit
+func routingErrorResponse(id json.RawMessage, err error) *mcp.JSONRPCResponse {
+	var limited *RateLimitError
+	if errors.As(err, &limited) {
+		return mcp.NewErrorResponseWithData(id, mcp.RateLimited, limited.Error(), map[string]interface{}{
+			"retryAfter": limited.RetryAfterSeconds(),
+			"limit":      limited.Limit,
+		})
+	}
+	var overQuota *QuotaError
+	if errors.As(err, &overQuota) {
+		return mcp.NewErrorResponseWithData(id, mcp.QuotaExceeded, overQuota.Error(), map[string]interface{}{
+			"quota":   overQuota.Quota,
+			"period":  overQuota.Period,
+			"resetAt": overQuota.ResetAt,
+		})
+	}
+	var open *CircuitOpenError
+	if errors.As(err, &open) {
+		return mcp.NewErrorResponseWithData(id, mcp.TargetUnavailable, open.Error(), map[string]interface{}{
+			"target":     open.Target,
+			"retryAfter": open.RetryAfterSeconds(),
+		})
+	}
+	return mcp.NewErrorResponse(id, mcp.InternalError, err.Error())
+}
+
+// requestInput collects what policy conditions are evaluated over for a request
+func requestInput(ctx context.Context, targetName string, args map[string]interface{}) *ConditionInput {
+	userEmail, _ := auth.GetUserEmail(ctx)
+	return &ConditionInput{
+		Arguments:  args,
+		UserEmail:  userEmail,
+		TargetName: targetName,
+		Time:       time.Now(),
+	}
+}
+
 // GetAuthorizer returns the authorizer
 func (p *Proxy) GetAuthorizer() *Authorizer {
 	return p.authorizer
 }
 
-// parseResourceName splits a prefixed resource name into (targetName, resourceName).
-// E.g., "github_list_repos" with delimiter "_" → tries to match known target names.
-func parseResourceName(name string) (string, string) {
-	idx := strings.Index(name, toolDelimiter)
-	if idx == -1 {
-		return "", name
+// connectTarget creates a session's client for a target and initializes it.
+// HTTP, SSE and WebSocket targets connect to one of their endpoints (see
+// connectEndpoint).
+func (p *Proxy) connectTarget(ctx context.Context, session *Session, target *database.Target, params *mcp.InitializeParams) (mcp.MCPClient, *mcp.InitializeResult, error) {
+	var upstream mcp.MCPClient
+	var err error
+
+	switch target.TransportType {
+	case "stdio":
+		upstream, err = p.createStdioClient(ctx, session, target)
+	case "kubernetes":
+		upstream, err = p.createK8sClient(ctx, session, target)
+	default:
+		client, result, err := p.connectEndpoint(ctx, session, target, params, "")
+		if err != nil {
+			return nil, nil, err
+		}
+		if target.Statefulness != "stateful" && len(target.Endpoints) > 1 {
+			client.failover = p.endpointFailover(session, target, params)
+		}
+		return client, result, nil
+	}
+	if err != nil {
+		log.Error().Err(err).Str("target", target.Name).Msg("Failed to create client")
+		return nil, nil, err
+	}
+
+	client := p.withResilience(target.ID, target.Name, nil, upstream)
+	result, err := client.Initialize(ctx, params)
+	if err != nil {
+		client.Close()
+		return nil, nil, err
 	}
-	return name[:idx], name[idx+len(toolDelimiter):]
+	return client, result, nil
 }
 
 // createStdioClient creates a STDIO MCP client with resolved environment configs
@@ -739,6 +1523,7 @@ func (p *Proxy) createStdioClient(ctx context.Context, session *Session, target
 	if err != nil {
 		return nil, fmt.Errorf("STDIO process: %w", err)
 	}
+	session.SetCacheScope(target.Name, cacheScope(subjectKey, nil))
 
 	log.Info().
 		Str("target", target.Name).
@@ -803,6 +1588,7 @@ func (p *Proxy) createK8sClient(ctx context.Context, session *Session, target *d
 	if err != nil {
 		return nil, fmt.Errorf("Kubernetes instance: %w", err)
 	}
+	session.SetCacheScope(target.Name, cacheScope(subjectKey, nil))
 
 	log.Info().
 		Str("target", target.Name).
@@ -814,24 +1600,43 @@ func (p *Proxy) createK8sClient(ctx context.Context, session *Session, target *d
 	return client, nil
 }
 
-// createHTTPClient creates an HTTP MCP client with resolved environment configs
-func (p *Proxy) createHTTPClient(ctx context.Context, session *Session, target *database.Target) (*mcp.Client, error) {
+// createHTTPClient creates an HTTP MCP client for one of a target's endpoints
+// with resolved environment configs
+func (p *Proxy) createHTTPClient(ctx context.Context, session *Session, target *database.Target, url string) (*mcp.Client, error) {
 	_, span := tracer.Start(ctx, "createHTTPClient",
 		trace.WithAttributes(
 			attribute.String("target.name", target.Name),
 			attribute.String("target.transport", target.TransportType),
+			attribute.String("target.endpoint", url),
 		),
 	)
 	defer span.End()
 
+	cfg := p.httpClientConfig(ctx, target, url, session.UserID, session.Role, session.Groups)
+
+	// Sessions sending the same credentials see the same upstream responses,
+	// whichever endpoint serves them
+	scopeCfg := cfg
+	if scopeCfg.URL == url {
+		scopeCfg.URL = target.URL
+	}
+	subjectKey := stdio.ComputeSubjectKey(target, session.UserID.String(), session.Role, session.Groups)
+	session.SetCacheScope(target.Name, cacheScope(subjectKey, &scopeCfg))
+
+	return mcp.NewClient(cfg), nil
+}
+
+// httpClientConfig builds the client config for an endpoint of an HTTP target
+// from the env configs and tokens resolved for a user, role and groups
+func (p *Proxy) httpClientConfig(ctx context.Context, target *database.Target, url string, userID uuid.UUID, role string, groups []string) mcp.ClientConfig {
 	cfg := mcp.ClientConfig{
-		URL:           target.URL,
+		URL:           url,
 		CustomHeaders: make(map[string]string),
 		TransportType: mcp.TransportType(target.TransportType),
 	}
 
 	// Resolve environment configs for this target
-	envConfigs, err := p.repo.ResolveEnvConfigsForTarget(ctx, target.ID, session.UserID, session.Role, session.Groups)
+	envConfigs, err := p.repo.ResolveEnvConfigsForTarget(ctx, target.ID, userID, role, groups)
 	if err != nil {
 		log.Warn().Err(err).Str("target", target.Name).Msg("Failed to resolve env configs, using defaults")
 	}
@@ -858,7 +1663,13 @@ func (p *Proxy) createHTTPClient(ctx context.Context, session *Session, target *
 			cfg.AuthHeader = target.AuthHeaderName
 		}
 
-		if baseURL, ok := envConfigs["BASE_URL"]; ok && p.encryptor != nil {
+		if baseURL, ok := envConfigs["BASE_URL"]; ok && len(target.Endpoints) > 0 {
+			// Replacing every endpoint's URL would collapse the pool onto one upstream
+			log.Warn().
+				Str("target", target.Name).
+				Str("env_source", baseURL.Source).
+				Msg("Ignoring BASE_URL env config for a target with endpoints")
+		} else if ok && p.encryptor != nil {
 			decrypted, err := p.encryptor.Decrypt(baseURL.Value)
 			if err == nil {
 				cfg.URL = decrypted
@@ -897,7 +1708,7 @@ func (p *Proxy) createHTTPClient(ctx context.Context, session *Session, target *
 
 	// Fallback to legacy token resolution
 	if cfg.AuthToken == "" && (target.AuthType == "bearer" || target.AuthType == "header") {
-		tokenInfo, err := p.repo.ResolveTokenForTarget(ctx, session.UserID, session.Role, session.Groups, target.ID)
+		tokenInfo, err := p.repo.ResolveTokenForTarget(ctx, userID, role, groups, target.ID)
 		if err == nil && p.encryptor != nil {
 			decrypted, err := p.encryptor.Decrypt(tokenInfo.Token)
 			if err == nil {
@@ -913,5 +1724,5 @@ func (p *Proxy) createHTTPClient(ctx context.Context, session *Session, target *
 		}
 	}
 
-	return mcp.NewClient(cfg), nil
+	return cfg
 }
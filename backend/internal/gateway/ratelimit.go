@@ -0,0 +1,244 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reflow/gateway/internal/database"
+	"github.com/reflow/gateway/internal/telemetry"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	otelmetric "go.opentelemetry.io/otel/metric"
+)
+
+// rateLimitsTTL bounds how long rate limit changes take to reach running sessions
+const rateLimitsTTL = 10 * time.Second
+
+// Rate limit bucket stores
+const (
+	RateLimitStorePostgres = "postgres" // buckets shared by all gateway replicas
+	RateLimitStoreMemory   = "memory"   // buckets local to this gateway process
+)
+
+// RateLimitError is returned by CallTool when a rate limit has no tokens left
+type RateLimitError struct {
+	Limit      string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("Rate limit %q exceeded, retry after %ds", e.Limit, e.RetryAfterSeconds())
+}
+
+// RetryAfterSeconds rounds the wait up to whole seconds
+func (e *RateLimitError) RetryAfterSeconds() int {
+	return int(math.Ceil(e.RetryAfter.Seconds()))
+}
+
+type rateLimit struct {
+	*database.RateLimit
+	toolPattern *regexp.Regexp
+}
+
+// rateLimitCache holds the enabled rate limits
+type rateLimitCache struct {
+	mu       sync.Mutex
+	limits   []rateLimit
+	loadedAt time.Time
+}
+
+// bucketStore takes tokens from rate limit buckets
+type bucketStore interface {
+	// take takes a token from every bucket, or from none if one of them is
+	// empty. It returns the index of the first empty bucket, or -1, and how
+	// many tokens that bucket has.
+	take(ctx context.Context, buckets []database.RateLimitBucket) (int, float64, error)
+}
+
+// postgresBuckets keeps buckets in the database
+type postgresBuckets struct {
+	repo *database.Repository
+}
+
+func (b *postgresBuckets) take(ctx context.Context, buckets []database.RateLimitBucket) (int, float64, error) {
+	return b.repo.TakeRateLimitTokens(ctx, buckets)
+}
+
+type memoryBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// memoryBuckets keeps buckets in this process
+type memoryBuckets struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+}
+
+func (b *memoryBuckets) take(_ context.Context, buckets []database.RateLimitBucket) (int, float64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	refilled := make([]*memoryBucket, len(buckets))
+	for i, ref := range buckets {
+		id := ref.LimitID.String() + "/" + ref.Key
+		bucket, ok := b.buckets[id]
+		if !ok {
+			bucket = &memoryBucket{tokens: ref.Capacity, updatedAt: now}
+			b.buckets[id] = bucket
+		}
+		bucket.tokens = math.Min(ref.Capacity, bucket.tokens+now.Sub(bucket.updatedAt).Seconds()*ref.RefillRate)
+		bucket.updatedAt = now
+		refilled[i] = bucket
+	}
+
+	for i, bucket := range refilled {
+		if bucket.tokens < 1 {
+			return i, bucket.tokens, nil
+		}
+	}
+	for _, bucket := range refilled {
+		bucket.tokens--
+	}
+	return -1, 0, nil
+}
+
+// newBucketStore returns the bucket store for the configured kind
+func newBucketStore(kind string, repo *database.Repository) bucketStore {
+	if kind == RateLimitStoreMemory || repo == nil {
+		return &memoryBuckets{buckets: make(map[string]*memoryBucket)}
+	}
+	return &postgresBuckets{repo: repo}
+}
+
+// rateLimits returns the enabled rate limits
+func (p *Proxy) rateLimits(ctx context.Context) ([]rateLimit, error) {
+	if p.repo == nil {
+		return nil, nil
+	}
+
+	p.limits.mu.Lock()
+	defer p.limits.mu.Unlock()
+
+	if time.Since(p.limits.loadedAt) > rateLimitsTTL {
+		stored, err := p.repo.ListRateLimits(ctx, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load rate limits: %w", err)
+		}
+		limits := make([]rateLimit, 0, len(stored))
+		for _, limit := range stored {
+			compiled := rateLimit{RateLimit: limit}
+			if limit.ToolPattern != nil && *limit.ToolPattern != "" {
+				re, err := regexp.Compile(*limit.ToolPattern)
+				if err != nil {
+					log.Error().Err(err).Str("rate_limit", limit.Name).Msg("Skipping rate limit with invalid tool pattern")
+					continue
+				}
+				compiled.toolPattern = re
+			}
+			limits = append(limits, compiled)
+		}
+		p.limits.limits = limits
+		p.limits.loadedAt = time.Now()
+	}
+	return p.limits.limits, nil
+}
+
+// checkRateLimits takes a token from the bucket of every rate limit that
+// applies to a tool call, returning a *RateLimitError for the first that is
+// empty. Tokens are taken from all buckets or from none, so a rejected call
+// does not use up the other limits. Rate limits fail open: if buckets cannot
+// be read, the call proceeds.
+func (p *Proxy) checkRateLimits(ctx context.Context, session *Session, mapping ToolMapping) error {
+	limits, err := p.rateLimits(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Rate limits not enforced")
+		return nil
+	}
+
+	var applied []rateLimit
+	var buckets []database.RateLimitBucket
+	for _, limit := range limits {
+		if !limit.applies(session, mapping.TargetID, mapping.ToolName) {
+			continue
+		}
+
+		key := "*"
+		if limit.PerUser {
+			key = session.UserID.String()
+		}
+		applied = append(applied, limit)
+		buckets = append(buckets, database.RateLimitBucket{
+			LimitID:    limit.ID,
+			Key:        key,
+			Capacity:   float64(limit.Burst),
+			RefillRate: limit.RefillRate(),
+		})
+	}
+	if len(buckets) == 0 {
+		return nil
+	}
+
+	denied, tokens, err := p.buckets.take(ctx, buckets)
+	if err != nil {
+		log.Error().Err(err).Msg("Rate limits not enforced")
+		return nil
+	}
+	if denied < 0 {
+		return nil
+	}
+	limit := applied[denied]
+
+	telemetry.MCPToolCallsRateLimited.Add(ctx, 1,
+		otelmetric.WithAttributes(
+			attribute.String("tool_name", mapping.ToolName),
+			attribute.String("target", mapping.TargetName),
+			attribute.String("rate_limit", limit.Name),
+		),
+	)
+	log.Info().
+		Str("rate_limit", limit.Name).
+		Str("user_id", session.UserID.String()).
+		Str("target", mapping.TargetName).
+		Str("tool", mapping.ToolName).
+		Msg("Tool call rate limited")
+
+	wait := (1 - tokens) / limit.RefillRate()
+	return &RateLimitError{Limit: limit.Name, RetryAfter: time.Duration(wait * float64(time.Second))}
+}
+
+// applies reports whether a rate limit covers a session's call to a tool
+func (l rateLimit) applies(session *Session, targetID uuid.UUID, toolName string) bool {
+	if l.TargetID != nil && *l.TargetID != targetID {
+		return false
+	}
+	if l.toolPattern != nil && !l.toolPattern.MatchString(toolName) {
+		return false
+	}
+
+	value := ""
+	if l.SubjectValue != nil {
+		value = *l.SubjectValue
+	}
+	switch l.SubjectType {
+	case "everyone":
+		return true
+	case "user":
+		return value == session.UserID.String()
+	case "role":
+		return value == session.Role
+	case "group":
+		for _, group := range session.Groups {
+			if group == value {
+				return true
+			}
+		}
+	}
+	return false
+}
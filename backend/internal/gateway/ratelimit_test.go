@@ -0,0 +1,42 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/reflow/gateway/internal/database"
+)
+
+func TestMemoryBucketsTakeAllOrNone(t *testing.T) {
+	// No refill, so every token taken stays taken
+	wide := database.RateLimitBucket{LimitID: uuid.New(), Key: "*", Capacity: 2}
+	narrow := database.RateLimitBucket{LimitID: uuid.New(), Key: "user-1", Capacity: 1}
+	other := database.RateLimitBucket{LimitID: wide.LimitID, Key: "user-2", Capacity: 1}
+
+	steps := []struct {
+		name       string
+		buckets    []database.RateLimitBucket
+		wantDenied int
+		wantLeft   float64
+	}{
+		{"both have tokens", []database.RateLimitBucket{wide, narrow}, -1, 0},
+		{"second is empty", []database.RateLimitBucket{wide, narrow}, 1, 0},
+		// The rejected call above must not have taken wide's last token
+		{"first kept its token", []database.RateLimitBucket{wide}, -1, 0},
+		{"first is empty", []database.RateLimitBucket{wide, narrow}, 0, 0},
+		{"keys of one limit are separate buckets", []database.RateLimitBucket{other}, -1, 0},
+		{"other key is empty", []database.RateLimitBucket{other}, 0, 0},
+	}
+
+	b := newBucketStore(RateLimitStoreMemory, nil)
+	for _, step := range steps {
+		denied, left, err := b.take(context.Background(), step.buckets)
+		if err != nil {
+			t.Fatalf("%s: take() error = %v", step.name, err)
+		}
+		if denied != step.wantDenied || left != step.wantLeft {
+			t.Fatalf("%s: take() = %d, %v, want %d, %v", step.name, denied, left, step.wantDenied, step.wantLeft)
+		}
+	}
+}
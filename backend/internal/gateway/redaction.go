@@ -0,0 +1,298 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reflow/gateway/internal/auth"
+	"github.com/reflow/gateway/internal/database"
+	"github.com/reflow/gateway/internal/mcp"
+	"github.com/reflow/gateway/internal/observability"
+	"github.com/reflow/gateway/internal/redaction"
+	"github.com/rs/zerolog/log"
+)
+
+// redactionRulesTTL bounds how long rule changes take to reach running sessions
+const redactionRulesTTL = 10 * time.Second
+
+type redactionRule struct {
+	*redaction.Rule
+	targetID *uuid.UUID
+	role     *string
+}
+
+// redactionCache holds the compiled enabled redaction rules
+type redactionCache struct {
+	mu       sync.Mutex
+	rules    []redactionRule
+	loadedAt time.Time
+}
+
+// redactionRules returns the enabled rules that apply to a target for the session's role
+func (p *Proxy) redactionRules(ctx context.Context, session *Session, targetID uuid.UUID) ([]*redaction.Rule, error) {
+	if p.repo == nil {
+		return nil, nil
+	}
+
+	p.redactions.mu.Lock()
+	defer p.redactions.mu.Unlock()
+
+	if time.Since(p.redactions.loadedAt) > redactionRulesTTL {
+		stored, err := p.repo.ListRedactionRules(ctx, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load redaction rules: %w", err)
+		}
+		rules := make([]redactionRule, 0, len(stored))
+		for _, rule := range stored {
+			compiled, err := database.CompileRedactionRule(rule)
+			if err != nil {
+				log.Error().Err(err).Str("rule", rule.Name).Msg("Skipping invalid redaction rule")
+				continue
+			}
+			rules = append(rules, redactionRule{Rule: compiled, targetID: rule.TargetID, role: rule.Role})
+		}
+		p.redactions.rules = rules
+		p.redactions.loadedAt = time.Now()
+	}
+
+	var applicable []*redaction.Rule
+	for _, rule := range p.redactions.rules {
+		if rule.targetID != nil && *rule.targetID != targetID {
+			continue
+		}
+		if rule.role != nil && *rule.role != session.Role {
+			continue
+		}
+		applicable = append(applicable, rule.Rule)
+	}
+	return applicable, nil
+}
+
+// redactToolResult applies redaction rules to a tool result's content blocks and
+// structured content. A matching "drop" rule removes the content block (or the
+// whole structuredContent); a "block" rule replaces the result with an error.
+func (p *Proxy) redactToolResult(ctx context.Context, session *Session, mapping ToolMapping, name string, result *mcp.ToolCallResult) (*mcp.ToolCallResult, error) {
+	if result == nil {
+		return result, nil
+	}
+	rules, err := p.redactionRules(ctx, session, mapping.TargetID)
+	if err != nil || len(rules) == 0 {
+		return result, err
+	}
+
+	var findings redaction.Findings
+	content, err := redactList(rules, result.Content, &findings, contentText)
+	if err != nil {
+		return nil, err
+	}
+
+	structured := result.StructuredContent
+	if len(structured) > 0 {
+		var value interface{}
+		if err := json.Unmarshal(structured, &value); err != nil {
+			return nil, fmt.Errorf("invalid structuredContent: %w", err)
+		}
+		var found redaction.Findings
+		value = redaction.ScanValue(rules, value, &found)
+		switch found.Action() {
+		case "":
+		case redaction.ActionDrop:
+			structured = nil
+		default:
+			if structured, err = json.Marshal(value); err != nil {
+				return nil, err
+			}
+		}
+		findings.Merge(found)
+	}
+
+	if len(findings) == 0 {
+		return result, nil
+	}
+	p.recordRedactions(ctx, session, mcp.MethodToolsCall, mapping.TargetName, name, findings)
+
+	if blocking := findings.Blocking(); blocking != nil {
+		return mcp.NewToolCallError(fmt.Sprintf("Result of tool %s was blocked by redaction rule %s", name, blocking.Rule)), nil
+	}
+	redacted := *result
+	redacted.Content = content
+	redacted.StructuredContent = structured
+	return &redacted, nil
+}
+
+// redactResourceContents applies redaction rules to the text of a resource
+// read. A matching "drop" rule removes that content entry; a "block" rule fails
+// the read.
+func (p *Proxy) redactResourceContents(ctx context.Context, session *Session, mapping ResourceMapping, uri string, result *mcp.ResourceReadResult) (*mcp.ResourceReadResult, error) {
+	if result == nil {
+		return result, nil
+	}
+	rules, err := p.redactionRules(ctx, session, mapping.TargetID)
+	if err != nil || len(rules) == 0 {
+		return result, err
+	}
+
+	var findings redaction.Findings
+	contents, err := redactList(rules, result.Contents, &findings, func(entry map[string]interface{}) map[string]interface{} {
+		return entry
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(findings) == 0 {
+		return result, nil
+	}
+	p.recordRedactions(ctx, session, mcp.MethodResourcesRead, mapping.TargetName, uri, findings)
+
+	if blocking := findings.Blocking(); blocking != nil {
+		return nil, fmt.Errorf("resource %s was blocked by redaction rule %s", uri, blocking.Rule)
+	}
+	redacted := *result
+	redacted.Contents = contents
+	return &redacted, nil
+}
+
+// redactPromptMessages applies redaction rules to the content of each prompt
+// message. A matching "drop" rule removes the message; a "block" rule fails the
+// request.
+func (p *Proxy) redactPromptMessages(ctx context.Context, session *Session, mapping PromptMapping, name string, result *mcp.PromptGetResult) (*mcp.PromptGetResult, error) {
+	if result == nil {
+		return result, nil
+	}
+	rules, err := p.redactionRules(ctx, session, mapping.TargetID)
+	if err != nil || len(rules) == 0 {
+		return result, err
+	}
+
+	var findings redaction.Findings
+	messages, err := redactList(rules, result.Messages, &findings, func(message map[string]interface{}) map[string]interface{} {
+		block, _ := message["content"].(map[string]interface{})
+		return contentText(block)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(findings) == 0 {
+		return result, nil
+	}
+	p.recordRedactions(ctx, session, mcp.MethodPromptsGet, mapping.TargetName, name, findings)
+
+	if blocking := findings.Blocking(); blocking != nil {
+		return nil, fmt.Errorf("prompt %s was blocked by redaction rule %s", name, blocking.Rule)
+	}
+	redacted := *result
+	redacted.Messages = messages
+	return &redacted, nil
+}
+
+// redactList masks the "text" field of each entry of a JSON array. textOf
+// returns the object holding an entry's text, or nil to leave the entry alone.
+// Entries matched by a "drop" rule are removed.
+func redactList(rules []*redaction.Rule, raw json.RawMessage, findings *redaction.Findings, textOf func(map[string]interface{}) map[string]interface{}) (json.RawMessage, error) {
+	if len(raw) == 0 {
+		return raw, nil
+	}
+	var entries []map[string]interface{}
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, fmt.Errorf("invalid content: %w", err)
+	}
+
+	var kept []map[string]interface{}
+	var found redaction.Findings
+	for _, entry := range entries {
+		holder := textOf(entry)
+		text, ok := holder["text"].(string)
+		if !ok {
+			kept = append(kept, entry)
+			continue
+		}
+
+		var entryFindings redaction.Findings
+		holder["text"] = redaction.Scan(rules, text, &entryFindings)
+		found.Merge(entryFindings)
+		if entryFindings.Action() != redaction.ActionDrop {
+			kept = append(kept, entry)
+		}
+	}
+	if len(found) == 0 {
+		return raw, nil
+	}
+	findings.Merge(found)
+
+	if kept == nil {
+		kept = []map[string]interface{}{}
+	}
+	return json.Marshal(kept)
+}
+
+// contentText returns the object holding the text of an MCP content block: the
+// block itself for text content, the embedded resource for resource content
+func contentText(block map[string]interface{}) map[string]interface{} {
+	switch block["type"] {
+	case "text":
+		return block
+	case "resource":
+		resource, _ := block["resource"].(map[string]interface{})
+		return resource
+	}
+	return nil
+}
+
+// recordRedactions logs what the rules matched, records it in the request audit
+// log and reports each rule on the observability hub
+func (p *Proxy) recordRedactions(ctx context.Context, session *Session, method, targetName, name string, findings redaction.Findings) {
+	log.Info().
+		Str("session_id", session.ID).
+		Str("method", method).
+		Str("target", targetName).
+		Str("name", name).
+		Str("action", findings.Action()).
+		Interface("redactions", findings).
+		Msg("Redacted upstream content")
+
+	if p.repo != nil {
+		status := http.StatusOK
+		if findings.Blocking() != nil {
+			status = http.StatusForbidden
+		}
+		body, _ := json.Marshal(map[string]interface{}{
+			"name":       name,
+			"redactions": findings,
+		})
+		userID := session.UserID
+		reqLog := &database.RequestLog{
+			SessionID:      session.ID,
+			UserID:         &userID,
+			Method:         method,
+			TargetName:     targetName,
+			RequestBody:    body,
+			ResponseStatus: status,
+		}
+		if err := p.repo.CreateRequestLog(ctx, reqLog); err != nil {
+			log.Error().Err(err).Str("session_id", session.ID).Msg("Failed to record redactions")
+		}
+	}
+
+	if p.obsHub != nil {
+		userEmail, _ := auth.GetUserEmail(ctx)
+		for _, finding := range findings {
+			p.obsHub.EmitRedaction(observability.RedactionEvent{
+				Timestamp: time.Now(),
+				UserID:    session.UserID.String(),
+				UserEmail: userEmail,
+				Method:    method,
+				Target:    targetName,
+				Name:      name,
+				Rule:      finding.Rule,
+				Detector:  finding.Detector,
+				Action:    finding.Action,
+				Count:     finding.Count,
+			})
+		}
+	}
+}
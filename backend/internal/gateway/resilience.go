@@ -0,0 +1,581 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reflow/gateway/internal/mcp"
+	"github.com/reflow/gateway/internal/observability"
+	"github.com/reflow/gateway/internal/telemetry"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	otelmetric "go.opentelemetry.io/otel/metric"
+)
+
+const (
+	defaultUpstreamTimeout     = 30 * time.Second
+	defaultUpstreamMaxAttempts = 3
+	defaultRetryBackoff        = 200 * time.Millisecond
+	defaultBreakerFailures     = 5
+	defaultBreakerCooldown     = 30 * time.Second
+
+	// maxRetryBackoff caps the exponential backoff between retries
+	maxRetryBackoff = 5 * time.Second
+)
+
+// Circuit breaker states
+const (
+	BreakerClosed   = "closed"    // requests flow; consecutive failures are counted
+	BreakerOpen     = "open"      // requests fail fast until the cooldown has passed
+	BreakerHalfOpen = "half_open" // one probe request decides whether to close again
+)
+
+// idempotentMethods may be retried after a failure. tools/call is not: the
+// upstream may have acted on a call whose response was lost.
+var idempotentMethods = map[string]bool{
+	mcp.MethodToolsList:          true,
+	mcp.MethodResourcesList:      true,
+	mcp.MethodResourcesRead:      true,
+	mcp.MethodResourcesTemplates: true,
+	mcp.MethodPromptsList:        true,
+	mcp.MethodPromptsGet:         true,
+	mcp.MethodCompletionComplete: true,
+	mcp.MethodLoggingSetLevel:    true,
+	mcp.MethodPing:               true,
+}
+
+// CircuitOpenError is returned without contacting a target whose circuit
+// breaker is open
+type CircuitOpenError struct {
+	Target     string
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("Target %s is unavailable (circuit breaker open), retry after %ds", e.Target, e.RetryAfterSeconds())
+}
+
+// RetryAfterSeconds rounds the wait up to whole seconds
+func (e *CircuitOpenError) RetryAfterSeconds() int {
+	return int(math.Ceil(e.RetryAfter.Seconds()))
+}
+
+// circuitBreaker tracks the health of one target across all sessions
+type circuitBreaker struct {
+	mu       sync.Mutex
+	state    string
+	failures int       // consecutive failures
+	openedAt time.Time // when the breaker last opened
+	probing  bool      // a half-open probe is in flight
+}
+
+// allow reports whether a request may go upstream. An open breaker turns
+// half-open once its cooldown has passed and then lets one probe through at a
+// time. It returns the state it moved from, if it changed.
+func (b *circuitBreaker) allow(cooldown time.Duration) (bool, time.Duration, string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	from := ""
+	if b.state == BreakerOpen {
+		wait := cooldown - time.Since(b.openedAt)
+		if wait > 0 {
+			return false, wait, ""
+		}
+		from, b.state = b.state, BreakerHalfOpen
+	}
+	if b.state == BreakerHalfOpen {
+		if b.probing {
+			return false, time.Second, from
+		}
+		b.probing = true
+	}
+	return true, 0, from
+}
+
+// record counts a request's outcome and returns the states the breaker moved
+// from and to, if it changed. A failed probe reopens the breaker, a successful
+// one closes it. Requests that were in flight when the breaker opened do not
+// count.
+func (b *circuitBreaker) record(failed bool, threshold int) (string, string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	from := b.state
+	if from == BreakerOpen {
+		return "", ""
+	}
+	if b.state == BreakerHalfOpen {
+		b.probing = false
+	}
+	if !failed {
+		b.failures = 0
+		b.state = BreakerClosed
+	} else {
+		b.failures++
+		if b.state == BreakerHalfOpen || b.failures >= threshold {
+			b.state = BreakerOpen
+			b.openedAt = time.Now()
+		}
+	}
+	if b.state == from {
+		return "", ""
+	}
+	return from, b.state
+}
+
+// available reports whether the breaker would let a request through now,
+// without taking a half-open probe slot
+func (b *circuitBreaker) available(cooldown time.Duration) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		return time.Since(b.openedAt) >= cooldown
+	case BreakerHalfOpen:
+		return !b.probing
+	}
+	return true
+}
+
+// abandon releases a request the caller gave up on without counting it
+func (b *circuitBreaker) abandon() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerHalfOpen {
+		b.probing = false
+	}
+}
+
+// breakerSet holds the circuit breaker of every target, or of every endpoint
+// of an HTTP, SSE or WebSocket target (see endpointBreakerKey)
+type breakerSet struct {
+	mu       sync.Mutex
+	breakers map[string]*circuitBreaker
+}
+
+// get returns a circuit breaker, creating a closed one
+func (s *breakerSet) get(key string) *circuitBreaker {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.breakers == nil {
+		s.breakers = make(map[string]*circuitBreaker)
+	}
+	b, ok := s.breakers[key]
+	if !ok {
+		b = &circuitBreaker{state: BreakerClosed}
+		s.breakers[key] = b
+	}
+	return b
+}
+
+// resilientClient wraps an upstream client with request timeouts, retries of
+// idempotent requests with jittered backoff, and the target's circuit breaker.
+// Everything else is passed through to the wrapped client.
+type resilientClient struct {
+	mcp.MCPClient
+	proxy    *Proxy
+	target   string
+	endpoint *upstreamEndpoint // nil for STDIO and Kubernetes targets
+	breaker  *circuitBreaker
+
+	// failover connects the session to another endpoint after this one
+	// failed, returning the client that replaced this one. Only set for
+	// stateless targets with several endpoints.
+	failover func(ctx context.Context, failed *resilientClient, cause error) (*resilientClient, error)
+}
+
+// withResilience wraps a target's upstream client. Clients of an endpoint use
+// that endpoint's circuit breaker.
+func (p *Proxy) withResilience(targetID uuid.UUID, targetName string, endpoint *upstreamEndpoint, client mcp.MCPClient) *resilientClient {
+	key := targetID.String()
+	if endpoint != nil {
+		key = endpointBreakerKey(targetID, endpoint.url)
+	}
+	return &resilientClient{
+		MCPClient: client,
+		proxy:     p,
+		target:    targetName,
+		endpoint:  endpoint,
+		breaker:   p.breakers.get(key),
+	}
+}
+
+// unwrapClient returns the upstream client behind any resilience wrapper
+func unwrapClient(client mcp.MCPClient) mcp.MCPClient {
+	if c, ok := client.(*resilientClient); ok {
+		return c.MCPClient
+	}
+	return client
+}
+
+// do sends a request through the circuit breaker, retrying idempotent methods
+// after failures. JSON-RPC errors from the upstream are answers, not failures:
+// they are neither retried nor counted against the breaker, and neither are
+// requests the caller gave up on. A connection error, or a failure that opens
+// an endpoint's breaker, fails the session over to another endpoint if the
+// client has a failover; retries then go to the new endpoint.
+func (c *resilientClient) do(ctx context.Context, method string, fn func(ctx context.Context, upstream mcp.MCPClient) error) error {
+	cfg := c.proxy.config
+	attempts := 1
+	if idempotentMethods[method] {
+		attempts = cfg.UpstreamMaxAttempts
+	}
+	timeout := cfg.UpstreamTimeout
+	if method == mcp.MethodToolsCall {
+		timeout = cfg.ToolCallTimeout
+	}
+
+	var err error
+	current := c
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			if !sleepContext(ctx, retryBackoff(cfg.RetryBackoff, attempt-1)) {
+				return err
+			}
+			telemetry.MCPUpstreamRetries.Add(ctx, 1,
+				otelmetric.WithAttributes(
+					attribute.String("target", c.target),
+					attribute.String("method", method),
+				),
+			)
+		}
+
+		allowed, wait := current.allow(ctx)
+		if !allowed && current.failover != nil {
+			// This endpoint fails fast; another may not
+			next, failoverErr := current.failover(ctx, current, &CircuitOpenError{Target: c.target, RetryAfter: wait})
+			if failoverErr == nil {
+				current = next
+				allowed, wait = current.allow(ctx)
+			}
+		}
+		if !allowed {
+			telemetry.MCPUpstreamRejected.Add(ctx, 1,
+				otelmetric.WithAttributes(
+					attribute.String("target", c.target),
+					attribute.String("method", method),
+				),
+			)
+			return &CircuitOpenError{Target: c.target, RetryAfter: wait}
+		}
+
+		err = current.attempt(ctx, method, timeout, fn)
+		if err != nil && ctx.Err() != nil {
+			current.breaker.abandon()
+			return err
+		}
+		failed := err != nil && !isUpstreamAnswer(err)
+		from, to := current.breaker.record(failed, cfg.BreakerFailures)
+		if from != "" {
+			c.proxy.reportBreaker(ctx, c.target, current.breakerEndpoint(), from, to, err)
+		}
+		if !failed {
+			return err
+		}
+		log.Debug().
+			Err(err).
+			Str("target", c.target).
+			Str("method", method).
+			Int("attempt", attempt).
+			Msg("Upstream request failed")
+
+		if current.failover != nil && (isConnectionError(err) || to == BreakerOpen) {
+			next, failoverErr := current.failover(ctx, current, err)
+			if failoverErr != nil {
+				log.Warn().Err(failoverErr).Str("target", c.target).Msg("Endpoint failover failed")
+			} else {
+				current = next
+			}
+		}
+	}
+	return err
+}
+
+// allow asks the client's circuit breaker whether a request may go upstream
+func (c *resilientClient) allow(ctx context.Context) (bool, time.Duration) {
+	allowed, wait, opened := c.breaker.allow(c.proxy.config.BreakerCooldown)
+	if opened != "" {
+		c.proxy.reportBreaker(ctx, c.target, c.breakerEndpoint(), opened, BreakerHalfOpen, nil)
+	}
+	return allowed, wait
+}
+
+// breakerEndpoint returns the URL of the endpoint whose circuit breaker the
+// client uses, or "" for STDIO and Kubernetes targets
+func (c *resilientClient) breakerEndpoint() string {
+	if c.endpoint == nil {
+		return ""
+	}
+	return c.endpoint.url
+}
+
+// attempt makes one request, bounded by timeout if set, and counts it against
+// the client's endpoint
+func (c *resilientClient) attempt(ctx context.Context, method string, timeout time.Duration, fn func(ctx context.Context, upstream mcp.MCPClient) error) error {
+	if c.endpoint != nil {
+		attrs := otelmetric.WithAttributes(
+			attribute.String("target", c.target),
+			attribute.String("endpoint", c.endpoint.url),
+		)
+		c.endpoint.outstanding.Add(1)
+		telemetry.MCPEndpointOutstanding.Add(ctx, 1, attrs)
+		start := time.Now()
+		defer func() {
+			c.endpoint.outstanding.Add(-1)
+			telemetry.MCPEndpointOutstanding.Add(ctx, -1, attrs)
+			telemetry.MCPEndpointDuration.Record(ctx, float64(time.Since(start).Milliseconds()), attrs)
+		}()
+	}
+
+	var err error
+	if timeout <= 0 {
+		err = fn(ctx, c.MCPClient)
+	} else {
+		attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		err = fn(attemptCtx, c.MCPClient)
+		if err != nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			err = fmt.Errorf("target %s did not respond within %s: %w", c.target, timeout, err)
+		}
+	}
+
+	if c.endpoint != nil {
+		status := "success"
+		if err != nil && !isUpstreamAnswer(err) {
+			status = "error"
+		}
+		telemetry.MCPEndpointRequests.Add(ctx, 1,
+			otelmetric.WithAttributes(
+				attribute.String("target", c.target),
+				attribute.String("endpoint", c.endpoint.url),
+				attribute.String("method", method),
+				attribute.String("status", status),
+			),
+		)
+	}
+	return err
+}
+
+// isUpstreamAnswer reports whether an error is the upstream's answer to the
+// request rather than a sign it is unwell: a JSON-RPC error, or a 4xx status
+// such as one user's rejected credentials
+func isUpstreamAnswer(err error) bool {
+	var upstream *mcp.UpstreamError
+	if errors.As(err, &upstream) {
+		return true
+	}
+	var status *mcp.HTTPStatusError
+	return errors.As(err, &status) && status.StatusCode >= 400 && status.StatusCode < 500
+}
+
+// isConnectionError reports whether an error means the endpoint could not be
+// reached or dropped the connection, so another endpoint may do better
+func isConnectionError(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var status *mcp.HTTPStatusError
+	if errors.As(err, &status) {
+		switch status.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+	}
+	return false
+}
+
+// retryBackoff returns a random wait of up to base*2^(retry-1), capped at
+// maxRetryBackoff ("full jitter"), so clients retrying together spread out
+func retryBackoff(base time.Duration, retry int) time.Duration {
+	ceiling := base << (retry - 1)
+	if ceiling <= 0 || ceiling > maxRetryBackoff {
+		ceiling = maxRetryBackoff
+	}
+	return rand.N(ceiling) + 1
+}
+
+// sleepContext waits for d, returning false if ctx is done first
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// reportBreaker logs a circuit breaker state change and surfaces it as an
+// error event on the observability hub and as a metric. Endpoint is the URL of
+// an HTTP, SSE or WebSocket target's endpoint, empty for other targets.
+func (p *Proxy) reportBreaker(ctx context.Context, target, endpoint, from, to string, cause error) {
+	telemetry.MCPBreakerTransitions.Add(ctx, 1,
+		otelmetric.WithAttributes(
+			attribute.String("target", target),
+			attribute.String("endpoint", endpoint),
+			attribute.String("from", from),
+			attribute.String("to", to),
+		),
+	)
+
+	name := target
+	if endpoint != "" {
+		name = fmt.Sprintf("%s (%s)", target, endpoint)
+	}
+
+	var msg string
+	switch to {
+	case BreakerOpen:
+		msg = fmt.Sprintf("Circuit breaker for target %s opened; requests fail fast for %s", name, p.config.BreakerCooldown)
+		if cause != nil {
+			msg += ": " + cause.Error()
+		}
+	case BreakerHalfOpen:
+		msg = fmt.Sprintf("Circuit breaker for target %s is half-open; probing the upstream", name)
+	default:
+		msg = fmt.Sprintf("Circuit breaker for target %s closed; the upstream recovered", name)
+	}
+
+	event := log.Info()
+	if to == BreakerOpen {
+		event = log.Warn()
+	}
+	if endpoint != "" {
+		event = event.Str("endpoint", endpoint)
+	}
+	event.Str("target", target).Str("from", from).Str("to", to).Msg(msg)
+
+	if p.obsHub != nil {
+		p.obsHub.EmitError(observability.ErrorEvent{
+			Timestamp: time.Now(),
+			Target:    target,
+			ErrorType: "circuit_breaker_" + to,
+			Message:   msg,
+		})
+	}
+}
+
+func (c *resilientClient) Initialize(ctx context.Context, params *mcp.InitializeParams) (*mcp.InitializeResult, error) {
+	var result *mcp.InitializeResult
+	err := c.do(ctx, mcp.MethodInitialize, func(ctx context.Context, upstream mcp.MCPClient) (err error) {
+		result, err = upstream.Initialize(ctx, params)
+		return err
+	})
+	return result, err
+}
+
+func (c *resilientClient) ListTools(ctx context.Context, cursor *string) (*mcp.ToolsListResult, error) {
+	var result *mcp.ToolsListResult
+	err := c.do(ctx, mcp.MethodToolsList, func(ctx context.Context, upstream mcp.MCPClient) (err error) {
+		result, err = upstream.ListTools(ctx, cursor)
+		return err
+	})
+	return result, err
+}
+
+func (c *resilientClient) CallTool(ctx context.Context, params *mcp.ToolCallParams) (*mcp.ToolCallResult, error) {
+	var result *mcp.ToolCallResult
+	err := c.do(ctx, mcp.MethodToolsCall, func(ctx context.Context, upstream mcp.MCPClient) (err error) {
+		result, err = upstream.CallTool(ctx, params)
+		return err
+	})
+	return result, err
+}
+
+func (c *resilientClient) ListResources(ctx context.Context, cursor *string) (*mcp.ResourcesListResult, error) {
+	var result *mcp.ResourcesListResult
+	err := c.do(ctx, mcp.MethodResourcesList, func(ctx context.Context, upstream mcp.MCPClient) (err error) {
+		result, err = upstream.ListResources(ctx, cursor)
+		return err
+	})
+	return result, err
+}
+
+func (c *resilientClient) ReadResource(ctx context.Context, uri string) (*mcp.ResourceReadResult, error) {
+	var result *mcp.ResourceReadResult
+	err := c.do(ctx, mcp.MethodResourcesRead, func(ctx context.Context, upstream mcp.MCPClient) (err error) {
+		result, err = upstream.ReadResource(ctx, uri)
+		return err
+	})
+	return result, err
+}
+
+func (c *resilientClient) ListResourceTemplates(ctx context.Context, cursor *string) (*mcp.ResourceTemplatesListResult, error) {
+	var result *mcp.ResourceTemplatesListResult
+	err := c.do(ctx, mcp.MethodResourcesTemplates, func(ctx context.Context, upstream mcp.MCPClient) (err error) {
+		result, err = upstream.ListResourceTemplates(ctx, cursor)
+		return err
+	})
+	return result, err
+}
+
+func (c *resilientClient) SubscribeResource(ctx context.Context, subscriberID, uri string) error {
+	return c.do(ctx, mcp.MethodResourcesSubscribe, func(ctx context.Context, upstream mcp.MCPClient) error {
+		return upstream.SubscribeResource(ctx, subscriberID, uri)
+	})
+}
+
+func (c *resilientClient) UnsubscribeResource(ctx context.Context, subscriberID, uri string) error {
+	return c.do(ctx, mcp.MethodResourcesUnsubscribe, func(ctx context.Context, upstream mcp.MCPClient) error {
+		return upstream.UnsubscribeResource(ctx, subscriberID, uri)
+	})
+}
+
+func (c *resilientClient) ListPrompts(ctx context.Context, cursor *string) (*mcp.PromptsListResult, error) {
+	var result *mcp.PromptsListResult
+	err := c.do(ctx, mcp.MethodPromptsList, func(ctx context.Context, upstream mcp.MCPClient) (err error) {
+		result, err = upstream.ListPrompts(ctx, cursor)
+		return err
+	})
+	return result, err
+}
+
+func (c *resilientClient) GetPrompt(ctx context.Context, params *mcp.PromptGetParams) (*mcp.PromptGetResult, error) {
+	var result *mcp.PromptGetResult
+	err := c.do(ctx, mcp.MethodPromptsGet, func(ctx context.Context, upstream mcp.MCPClient) (err error) {
+		result, err = upstream.GetPrompt(ctx, params)
+		return err
+	})
+	return result, err
+}
+
+func (c *resilientClient) Complete(ctx context.Context, params *mcp.CompleteParams) (*mcp.CompleteResult, error) {
+	var result *mcp.CompleteResult
+	err := c.do(ctx, mcp.MethodCompletionComplete, func(ctx context.Context, upstream mcp.MCPClient) (err error) {
+		result, err = upstream.Complete(ctx, params)
+		return err
+	})
+	return result, err
+}
+
+func (c *resilientClient) SetLoggingLevel(ctx context.Context, level string) error {
+	return c.do(ctx, mcp.MethodLoggingSetLevel, func(ctx context.Context, upstream mcp.MCPClient) error {
+		return upstream.SetLoggingLevel(ctx, level)
+	})
+}
+
+// SendRawRequest forwards a request the gateway does not interpret. A JSON-RPC
+// error in the response is the upstream's answer and does not count as a failure.
+func (c *resilientClient) SendRawRequest(ctx context.Context, req *mcp.JSONRPCRequest) (*mcp.JSONRPCResponse, error) {
+	var resp *mcp.JSONRPCResponse
+	err := c.do(ctx, req.Method, func(ctx context.Context, upstream mcp.MCPClient) (err error) {
+		resp, err = upstream.SendRawRequest(ctx, req)
+		return err
+	})
+	return resp, err
+}
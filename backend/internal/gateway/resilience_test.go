@@ -0,0 +1,66 @@
+package gateway
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	const threshold = 2
+	const (
+		allow   = "allow"
+		fail    = "fail"
+		succeed = "succeed"
+		abandon = "abandon"
+	)
+
+	// cooldown only matters for allow: an hour keeps an open breaker open, 0
+	// lets its cooldown pass
+	steps := []struct {
+		name      string
+		op        string
+		cooldown  time.Duration
+		wantAllow bool
+		wantFrom  string // state left, "" if unchanged
+		wantTo    string // state entered by record
+		wantState string
+	}{
+		{"closed allows", allow, time.Hour, true, "", "", BreakerClosed},
+		{"failure below threshold", fail, 0, false, "", "", BreakerClosed},
+		{"success resets failures", succeed, 0, false, "", "", BreakerClosed},
+		{"first failure", fail, 0, false, "", "", BreakerClosed},
+		{"threshold opens", fail, 0, false, BreakerClosed, BreakerOpen, BreakerOpen},
+		{"open rejects during cooldown", allow, time.Hour, false, "", "", BreakerOpen},
+		{"in-flight failure does not count", fail, 0, false, "", "", BreakerOpen},
+		{"cooldown passed lets a probe through", allow, 0, true, BreakerOpen, "", BreakerHalfOpen},
+		{"second probe is rejected", allow, 0, false, "", "", BreakerHalfOpen},
+		{"abandoned probe frees the slot", abandon, 0, false, "", "", BreakerHalfOpen},
+		{"next probe", allow, 0, true, "", "", BreakerHalfOpen},
+		{"failed probe reopens", fail, 0, false, BreakerHalfOpen, BreakerOpen, BreakerOpen},
+		{"probe after reopening", allow, 0, true, BreakerOpen, "", BreakerHalfOpen},
+		{"successful probe closes", succeed, 0, false, BreakerHalfOpen, BreakerClosed, BreakerClosed},
+		{"closed again", allow, time.Hour, true, "", "", BreakerClosed},
+		{"failures counted from zero", fail, 0, false, "", "", BreakerClosed},
+	}
+
+	b := &circuitBreaker{state: BreakerClosed}
+	for _, step := range steps {
+		switch step.op {
+		case allow:
+			allowed, _, from := b.allow(step.cooldown)
+			if allowed != step.wantAllow || from != step.wantFrom {
+				t.Fatalf("%s: allow() = %v, %q, want %v, %q", step.name, allowed, from, step.wantAllow, step.wantFrom)
+			}
+		case fail, succeed:
+			from, to := b.record(step.op == fail, threshold)
+			if from != step.wantFrom || to != step.wantTo {
+				t.Fatalf("%s: record() = %q, %q, want %q, %q", step.name, from, to, step.wantFrom, step.wantTo)
+			}
+		case abandon:
+			b.abandon()
+		}
+		if b.state != step.wantState {
+			t.Fatalf("%s: state = %q, want %q", step.name, b.state, step.wantState)
+		}
+	}
+}
@@ -2,6 +2,10 @@ package gateway
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,9 +19,15 @@ import (
 
 // ToolMapping maps a tool name to its upstream target
 type ToolMapping struct {
-	TargetID   uuid.UUID
-	TargetName string
-	ToolName   string // original (unprefixed) tool name
+	TargetID       uuid.UUID
+	TargetName     string
+	ToolName       string                 // original (unprefixed) tool name
+	OutputSchema   json.RawMessage        // declared outputSchema, if any
+	InputSchema    json.RawMessage        // inputSchema as listed to the client
+	SchemaOverride bool                   // InputSchema was set by a tool override and is always enforced
+	FixedArguments map[string]interface{} // set by a tool override on every call
+	CacheTTL       time.Duration          // how long results may be served from the response cache; 0 = never
+	VirtualTool    *database.VirtualTool  // set instead of a target for composite virtual tools
 }
 
 // ResourceMapping maps a resource URI to its upstream target
@@ -27,6 +37,15 @@ type ResourceMapping struct {
 	URI        string // original (unprefixed) URI
 }
 
+// ResourceTemplateMapping maps a resource URI template to its upstream target
+type ResourceTemplateMapping struct {
+	TargetID    uuid.UUID
+	TargetName  string
+	URITemplate string         // original (unprefixed) URI template
+	Prefix      string         // namespace prefix added to expanded URIs
+	pattern     *regexp.Regexp // matches prefixed URIs expanded from the template
+}
+
 // PromptMapping maps a prompt name to its upstream target
 type PromptMapping struct {
 	TargetID   uuid.UUID
@@ -36,20 +55,34 @@ type PromptMapping struct {
 
 // Session represents an active MCP session
 type Session struct {
-	ID           string
-	UserID       uuid.UUID
-	Role         string
-	Groups       []string
-	CreatedAt    time.Time
-	ExpiresAt    time.Time
-	clients      map[string]mcp.MCPClient // map[targetName]MCPClient
-	mu           sync.RWMutex
-	initialized  bool
-	capabilities *mcp.ServerCapabilities
-	targetIDs    map[string]uuid.UUID       // targetName -> targetID
-	toolMap      map[string]ToolMapping     // prefixedName -> mapping
-	resourceMap  map[string]ResourceMapping // prefixedURI -> mapping
-	promptMap    map[string]PromptMapping   // prefixedName -> mapping
+	ID            string
+	UserID        uuid.UUID
+	Role          string
+	Groups        []string
+	CreatedAt     time.Time
+	ExpiresAt     time.Time
+	clients       map[string]mcp.MCPClient // map[targetName]MCPClient
+	mu            sync.RWMutex
+	initialized   bool
+	capabilities  *mcp.ServerCapabilities
+	clientCaps    *mcp.ClientCapabilities            // capabilities declared by the client at initialize
+	version       string                             // protocol revision negotiated with the client
+	targetIDs     map[string]uuid.UUID               // targetName -> targetID
+	namespaces    map[string]string                  // targetName -> namespace
+	argValidation map[string]string                  // targetName -> argument validation mode
+	cacheTTLs     map[string]time.Duration           // targetName -> response cache TTL
+	cacheScopes   map[string]string                  // targetName -> response cache scope (see cacheScope)
+	endpoints     map[string]string                  // targetName -> URL of the endpoint connected to; kept across Recycle
+	toolMap       map[string]ToolMapping             // prefixedName -> mapping
+	resourceMap   map[string]ResourceMapping         // prefixedURI -> mapping
+	templateMap   map[string]ResourceTemplateMapping // prefixedURITemplate -> mapping
+	promptMap     map[string]PromptMapping           // prefixedName -> mapping
+	subscriptions map[string]ResourceMapping         // prefixedURI -> subscribed resource
+	sseHub        *SSEHub                            // client-facing notification stream (GET /mcp)
+
+	inflightMu     sync.Mutex
+	inflight       map[string]context.CancelCauseFunc // client request ID -> cancel
+	progressTokens map[string]progressBinding         // gateway progress token -> client token and stream
 }
 
 // SessionManager manages MCP sessions
@@ -60,6 +93,7 @@ type SessionManager struct {
 	sessionTimeout  time.Duration
 	cleanupInterval time.Duration
 	stopCleanup     chan struct{}
+	sseManager      *SSEManager
 }
 
 // NewSessionManager creates a new session manager
@@ -70,6 +104,7 @@ func NewSessionManager(repo *database.Repository, timeout, cleanupInterval time.
 		sessionTimeout:  timeout,
 		cleanupInterval: cleanupInterval,
 		stopCleanup:     make(chan struct{}),
+		sseManager:      NewSSEManager(),
 	}
 
 	// Start cleanup goroutine
@@ -95,17 +130,25 @@ func (sm *SessionManager) CreateSession(ctx context.Context, userID uuid.UUID, r
 	}
 
 	session := &Session{
-		ID:          sessionID,
-		UserID:      userID,
-		Role:        role,
-		Groups:      groups,
-		CreatedAt:   now,
-		ExpiresAt:   expiresAt,
-		clients:     make(map[string]mcp.MCPClient),
-		targetIDs:   make(map[string]uuid.UUID),
-		toolMap:     make(map[string]ToolMapping),
-		resourceMap: make(map[string]ResourceMapping),
-		promptMap:   make(map[string]PromptMapping),
+		ID:            sessionID,
+		UserID:        userID,
+		Role:          role,
+		Groups:        groups,
+		CreatedAt:     now,
+		ExpiresAt:     expiresAt,
+		clients:       make(map[string]mcp.MCPClient),
+		targetIDs:     make(map[string]uuid.UUID),
+		namespaces:    make(map[string]string),
+		argValidation: make(map[string]string),
+		cacheTTLs:     make(map[string]time.Duration),
+		cacheScopes:   make(map[string]string),
+		endpoints:     make(map[string]string),
+		toolMap:       make(map[string]ToolMapping),
+		resourceMap:   make(map[string]ResourceMapping),
+		templateMap:   make(map[string]ResourceTemplateMapping),
+		promptMap:     make(map[string]PromptMapping),
+		subscriptions: make(map[string]ResourceMapping),
+		sseHub:        sm.sseManager.GetOrCreateHub(sessionID),
 	}
 
 	sm.mu.Lock()
@@ -169,17 +212,25 @@ func (sm *SessionManager) GetSession(ctx context.Context, sessionID string) (*Se
 	}
 
 	session = &Session{
-		ID:          dbSession.ID,
-		UserID:      dbSession.UserID,
-		Role:        role,
-		Groups:      groups,
-		CreatedAt:   dbSession.CreatedAt,
-		ExpiresAt:   dbSession.ExpiresAt,
-		clients:     make(map[string]mcp.MCPClient),
-		targetIDs:   make(map[string]uuid.UUID),
-		toolMap:     make(map[string]ToolMapping),
-		resourceMap: make(map[string]ResourceMapping),
-		promptMap:   make(map[string]PromptMapping),
+		ID:            dbSession.ID,
+		UserID:        dbSession.UserID,
+		Role:          role,
+		Groups:        groups,
+		CreatedAt:     dbSession.CreatedAt,
+		ExpiresAt:     dbSession.ExpiresAt,
+		clients:       make(map[string]mcp.MCPClient),
+		targetIDs:     make(map[string]uuid.UUID),
+		namespaces:    make(map[string]string),
+		argValidation: make(map[string]string),
+		cacheTTLs:     make(map[string]time.Duration),
+		cacheScopes:   make(map[string]string),
+		endpoints:     make(map[string]string),
+		toolMap:       make(map[string]ToolMapping),
+		resourceMap:   make(map[string]ResourceMapping),
+		templateMap:   make(map[string]ResourceTemplateMapping),
+		promptMap:     make(map[string]PromptMapping),
+		subscriptions: make(map[string]ResourceMapping),
+		sseHub:        sm.sseManager.GetOrCreateHub(sessionID),
 	}
 
 	sm.mu.Lock()
@@ -196,16 +247,13 @@ func (sm *SessionManager) DeleteSession(ctx context.Context, sessionID string) e
 	if exists {
 		// Close HTTP clients only; STDIO processes are managed by StdioManager
 		session.mu.Lock()
-		for _, client := range session.clients {
-			if _, isStdio := client.(*stdio.Process); !isStdio {
-				client.Close()
-			}
-		}
+		session.releaseClients()
 		session.mu.Unlock()
 		delete(sm.sessions, sessionID)
 		telemetry.MCPSessionsActive.Add(ctx, -1)
 	}
 	sm.mu.Unlock()
+	sm.sseManager.RemoveHub(sessionID)
 
 	// Delete from database
 	return sm.repo.DeleteMCPSession(ctx, sessionID)
@@ -253,35 +301,131 @@ func (s *Session) GetTargetID(targetName string) (uuid.UUID, bool) {
 	return id, ok
 }
 
-// SetToolMapping stores a tool mapping
-func (s *Session) SetToolMapping(prefixedName string, mapping ToolMapping) {
+// SetTargetNamespace stores the namespace that prefixes a target's names
+func (s *Session) SetTargetNamespace(targetName, namespace string) {
 	s.mu.Lock()
-	s.toolMap[prefixedName] = mapping
+	s.namespaces[targetName] = namespace
 	s.mu.Unlock()
 }
 
-// GetToolMapping retrieves a tool mapping
-func (s *Session) GetToolMapping(prefixedName string) (ToolMapping, bool) {
+// GetTargetNamespace returns the namespace for a target name
+func (s *Session) GetTargetNamespace(targetName string) string {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	m, ok := s.toolMap[prefixedName]
-	return m, ok
+	if namespace, ok := s.namespaces[targetName]; ok {
+		return namespace
+	}
+	return database.DefaultNamespace(targetName)
 }
 
-// ClearToolMappings resets tool mappings
-func (s *Session) ClearToolMappings() {
+// SetArgumentValidation stores how a target's tool call arguments are validated
+func (s *Session) SetArgumentValidation(targetName, mode string) {
 	s.mu.Lock()
-	s.toolMap = make(map[string]ToolMapping)
+	s.argValidation[targetName] = mode
+	s.mu.Unlock()
+}
+
+// GetArgumentValidation returns how a target's tool call arguments are validated
+func (s *Session) GetArgumentValidation(targetName string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if mode, ok := s.argValidation[targetName]; ok {
+		return mode
+	}
+	return database.ArgumentValidationOff
+}
+
+// SetCacheTTL stores how long a target's cacheable responses are cached
+func (s *Session) SetCacheTTL(targetName string, ttl time.Duration) {
+	s.mu.Lock()
+	s.cacheTTLs[targetName] = ttl
+	s.mu.Unlock()
+}
+
+// GetCacheTTL returns how long a target's cacheable responses are cached
+func (s *Session) GetCacheTTL(targetName string) time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cacheTTLs[targetName]
+}
+
+// SetCacheScope stores the response cache scope of the session's connection to a target
+func (s *Session) SetCacheScope(targetName, scope string) {
+	s.mu.Lock()
+	s.cacheScopes[targetName] = scope
 	s.mu.Unlock()
 }
 
-// SetResourceMapping stores a resource mapping
-func (s *Session) SetResourceMapping(prefixedURI string, mapping ResourceMapping) {
+// GetCacheScope returns the response cache scope of the session's connection to a target
+func (s *Session) GetCacheScope(targetName string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	scope, ok := s.cacheScopes[targetName]
+	return scope, ok
+}
+
+// SetEndpoint stores the URL of the endpoint the session is connected to for a target
+func (s *Session) SetEndpoint(targetName, url string) {
 	s.mu.Lock()
-	s.resourceMap[prefixedURI] = mapping
+	s.endpoints[targetName] = url
 	s.mu.Unlock()
 }
 
+// GetEndpoint returns the URL of the endpoint the session last connected to
+// for a target. Stateful targets reconnect to it after a Recycle.
+func (s *Session) GetEndpoint(targetName string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	url, ok := s.endpoints[targetName]
+	return url, ok
+}
+
+// TargetForNamespace returns the name of the connected target using a namespace
+func (s *Session) TargetForNamespace(namespace string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for targetName, ns := range s.namespaces {
+		if ns == namespace {
+			return targetName, true
+		}
+	}
+	return "", false
+}
+
+// StoreToolMappings stores the tool mappings of a listing. A fresh listing
+// replaces the previous mappings; later pages add to them.
+func (s *Session) StoreToolMappings(mappings map[string]ToolMapping, replace bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if replace {
+		s.toolMap = make(map[string]ToolMapping, len(mappings))
+	}
+	for name, m := range mappings {
+		s.toolMap[name] = m
+	}
+}
+
+// GetToolMapping retrieves a tool mapping
+func (s *Session) GetToolMapping(prefixedName string) (ToolMapping, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	m, ok := s.toolMap[prefixedName]
+	return m, ok
+}
+
+// StoreResourceMappings stores the resource mappings of a listing. A fresh
+// listing replaces the previous mappings; later pages add to them.
+func (s *Session) StoreResourceMappings(mappings map[string]ResourceMapping, replace bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if replace {
+		s.resourceMap = make(map[string]ResourceMapping, len(mappings))
+	}
+	for uri, m := range mappings {
+		s.resourceMap[uri] = m
+	}
+}
+
 // GetResourceMapping retrieves a resource mapping
 func (s *Session) GetResourceMapping(prefixedURI string) (ResourceMapping, bool) {
 	s.mu.RLock()
@@ -290,20 +434,118 @@ func (s *Session) GetResourceMapping(prefixedURI string) (ResourceMapping, bool)
 	return m, ok
 }
 
-// ClearResourceMappings resets resource mappings
-func (s *Session) ClearResourceMappings() {
+// newResourceTemplateMapping prepares a resource template mapping for routing.
+// Returns an error if the template is malformed and cannot be routed.
+func newResourceTemplateMapping(prefixedTemplate string, mapping ResourceTemplateMapping) (ResourceTemplateMapping, error) {
+	pattern, err := compileURITemplate(prefixedTemplate)
+	if err != nil {
+		return mapping, err
+	}
+	mapping.pattern = pattern
+	return mapping, nil
+}
+
+// StoreResourceTemplateMappings stores the resource template mappings of a
+// listing. A fresh listing replaces the previous mappings; later pages add to them.
+func (s *Session) StoreResourceTemplateMappings(mappings map[string]ResourceTemplateMapping, replace bool) {
 	s.mu.Lock()
-	s.resourceMap = make(map[string]ResourceMapping)
-	s.mu.Unlock()
+	defer s.mu.Unlock()
+	if replace {
+		s.templateMap = make(map[string]ResourceTemplateMapping, len(mappings))
+	}
+	for template, m := range mappings {
+		s.templateMap[template] = m
+	}
+}
+
+// GetResourceTemplateMapping retrieves a resource template mapping
+func (s *Session) GetResourceTemplateMapping(prefixedTemplate string) (ResourceTemplateMapping, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	m, ok := s.templateMap[prefixedTemplate]
+	return m, ok
+}
+
+// MatchResourceTemplate resolves a prefixed URI expanded from a listed template.
+// When several templates match, the longest template wins.
+func (s *Session) MatchResourceTemplate(prefixedURI string) (ResourceMapping, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var best string
+	var match ResourceTemplateMapping
+	for prefixedTemplate, m := range s.templateMap {
+		if !m.pattern.MatchString(prefixedURI) || len(prefixedTemplate) <= len(best) {
+			continue
+		}
+		best = prefixedTemplate
+		match = m
+	}
+	if best == "" || !strings.HasPrefix(prefixedURI, match.Prefix) {
+		return ResourceMapping{}, false
+	}
+
+	return ResourceMapping{
+		TargetID:   match.TargetID,
+		TargetName: match.TargetName,
+		URI:        strings.TrimPrefix(prefixedURI, match.Prefix),
+	}, true
 }
 
-// SetPromptMapping stores a prompt mapping
-func (s *Session) SetPromptMapping(prefixedName string, mapping PromptMapping) {
+// AddSubscription records a resource subscription
+func (s *Session) AddSubscription(prefixedURI string, mapping ResourceMapping) {
 	s.mu.Lock()
-	s.promptMap[prefixedName] = mapping
+	s.subscriptions[prefixedURI] = mapping
 	s.mu.Unlock()
 }
 
+// RemoveSubscription forgets a resource subscription and returns what it pointed to
+func (s *Session) RemoveSubscription(prefixedURI string) (ResourceMapping, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.subscriptions[prefixedURI]
+	delete(s.subscriptions, prefixedURI)
+	return m, ok
+}
+
+// SubscribedURIs returns the upstream URIs the session subscribed to on a target
+func (s *Session) SubscribedURIs(targetName string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var uris []string
+	for _, m := range s.subscriptions {
+		if m.TargetName == targetName {
+			uris = append(uris, m.URI)
+		}
+	}
+	return uris
+}
+
+// IsSubscribed reports whether the session subscribed to an upstream resource
+func (s *Session) IsSubscribed(targetName, uri string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, m := range s.subscriptions {
+		if m.TargetName == targetName && m.URI == uri {
+			return true
+		}
+	}
+	return false
+}
+
+// StorePromptMappings stores the prompt mappings of a listing. A fresh listing
+// replaces the previous mappings; later pages add to them.
+func (s *Session) StorePromptMappings(mappings map[string]PromptMapping, replace bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if replace {
+		s.promptMap = make(map[string]PromptMapping, len(mappings))
+	}
+	for name, m := range mappings {
+		s.promptMap[name] = m
+	}
+}
+
 // GetPromptMapping retrieves a prompt mapping
 func (s *Session) GetPromptMapping(prefixedName string) (PromptMapping, bool) {
 	s.mu.RLock()
@@ -312,11 +554,92 @@ func (s *Session) GetPromptMapping(prefixedName string) (PromptMapping, bool) {
 	return m, ok
 }
 
-// ClearPromptMappings resets prompt mappings
-func (s *Session) ClearPromptMappings() {
-	s.mu.Lock()
-	s.promptMap = make(map[string]PromptMapping)
-	s.mu.Unlock()
+// SendRequest pushes a server-to-client request to all open notification streams
+func (s *Session) SendRequest(req *mcp.JSONRPCRequest) {
+	if s.sseHub == nil {
+		return
+	}
+	s.sseHub.BroadcastRequest(req)
+}
+
+// TrackRequest registers an in-flight client request so a later
+// notifications/cancelled can abort it. The returned func untracks it.
+func (s *Session) TrackRequest(requestID json.RawMessage, cancel context.CancelCauseFunc) func() {
+	key := string(requestID)
+	s.inflightMu.Lock()
+	if s.inflight == nil {
+		s.inflight = make(map[string]context.CancelCauseFunc)
+	}
+	s.inflight[key] = cancel
+	s.inflightMu.Unlock()
+
+	return func() {
+		s.inflightMu.Lock()
+		delete(s.inflight, key)
+		s.inflightMu.Unlock()
+	}
+}
+
+// CancelRequest aborts an in-flight client request. Returns false if it already finished.
+func (s *Session) CancelRequest(requestID json.RawMessage, reason string) bool {
+	s.inflightMu.Lock()
+	cancel, ok := s.inflight[string(requestID)]
+	s.inflightMu.Unlock()
+	if !ok {
+		return false
+	}
+
+	if reason == "" {
+		reason = "Request cancelled by client"
+	}
+	cancel(errors.New(reason))
+	return true
+}
+
+// progressBinding is where progress for a gateway-issued token is delivered
+type progressBinding struct {
+	clientToken json.RawMessage
+	stream      *ResponseStream // POST response stream of the request, nil for the GET stream
+}
+
+// SetProgressToken maps a gateway-issued progress token to the client's token and
+// the response stream of the request it belongs to (nil if not streamed)
+func (s *Session) SetProgressToken(gatewayToken string, clientToken json.RawMessage, stream *ResponseStream) {
+	s.inflightMu.Lock()
+	if s.progressTokens == nil {
+		s.progressTokens = make(map[string]progressBinding)
+	}
+	s.progressTokens[gatewayToken] = progressBinding{clientToken: clientToken, stream: stream}
+	s.inflightMu.Unlock()
+}
+
+// GetProgressToken returns the client's progress token for a gateway-issued one and
+// the response stream the progress should go to
+func (s *Session) GetProgressToken(gatewayToken string) (json.RawMessage, *ResponseStream, bool) {
+	s.inflightMu.Lock()
+	defer s.inflightMu.Unlock()
+	binding, ok := s.progressTokens[gatewayToken]
+	return binding.clientToken, binding.stream, ok
+}
+
+// RemoveProgressToken forgets a gateway-issued progress token
+func (s *Session) RemoveProgressToken(gatewayToken string) {
+	s.inflightMu.Lock()
+	delete(s.progressTokens, gatewayToken)
+	s.inflightMu.Unlock()
+}
+
+// SSEHub returns the hub that serves this session's GET /mcp notification streams
+func (s *Session) SSEHub() *SSEHub {
+	return s.sseHub
+}
+
+// SendNotification pushes a server-to-client notification to all open notification streams
+func (s *Session) SendNotification(notification *mcp.JSONRPCNotification) {
+	if s.sseHub == nil {
+		return
+	}
+	s.sseHub.BroadcastNotification(notification)
 }
 
 // SetInitialized marks the session as initialized
@@ -334,6 +657,34 @@ func (s *Session) IsInitialized() bool {
 	return s.initialized
 }
 
+// SetClientCapabilities stores the capabilities the client declared at initialize
+func (s *Session) SetClientCapabilities(caps *mcp.ClientCapabilities) {
+	s.mu.Lock()
+	s.clientCaps = caps
+	s.mu.Unlock()
+}
+
+// ClientCapabilities returns the capabilities the client declared at initialize
+func (s *Session) ClientCapabilities() *mcp.ClientCapabilities {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.clientCaps
+}
+
+// SetProtocolVersion stores the protocol revision negotiated with the client
+func (s *Session) SetProtocolVersion(version string) {
+	s.mu.Lock()
+	s.version = version
+	s.mu.Unlock()
+}
+
+// ProtocolVersion returns the protocol revision negotiated with the client
+func (s *Session) ProtocolVersion() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.version
+}
+
 // GetCapabilities returns the aggregated capabilities
 func (s *Session) GetCapabilities() *mcp.ServerCapabilities {
 	s.mu.RLock()
@@ -364,14 +715,10 @@ func (sm *SessionManager) cleanup() {
 	for id, session := range sm.sessions {
 		if now.After(session.ExpiresAt) {
 			session.mu.Lock()
-			for _, client := range session.clients {
-				// Skip STDIO processes; they're managed by StdioManager
-				if _, isStdio := client.(*stdio.Process); !isStdio {
-					client.Close()
-				}
-			}
+			session.releaseClients()
 			session.mu.Unlock()
 			delete(sm.sessions, id)
+			sm.sseManager.RemoveHub(id)
 			telemetry.MCPSessionsActive.Add(ctx, -1)
 			log.Debug().Str("session_id", id).Msg("Cleaned up expired session")
 		}
@@ -397,20 +744,24 @@ func (s *Session) Recycle(newRole string, newGroups []string) {
 	defer s.mu.Unlock()
 
 	// Close HTTP clients only (STDIO managed by StdioManager)
-	for _, client := range s.clients {
-		if _, isStdio := client.(*stdio.Process); !isStdio {
-			client.Close()
-		}
-	}
+	s.releaseClients()
 
 	// Reset session state
 	s.clients = make(map[string]mcp.MCPClient)
 	s.targetIDs = make(map[string]uuid.UUID)
+	s.namespaces = make(map[string]string)
+	s.argValidation = make(map[string]string)
+	s.cacheTTLs = make(map[string]time.Duration)
+	s.cacheScopes = make(map[string]string)
 	s.toolMap = make(map[string]ToolMapping)
 	s.resourceMap = make(map[string]ResourceMapping)
+	s.templateMap = make(map[string]ResourceTemplateMapping)
 	s.promptMap = make(map[string]PromptMapping)
+	s.subscriptions = make(map[string]ResourceMapping)
 	s.initialized = false
 	s.capabilities = nil
+	s.clientCaps = nil
+	s.version = ""
 
 	// Update identity context
 	s.Role = newRole
@@ -423,6 +774,22 @@ func (s *Session) Recycle(newRole string, newGroups []string) {
 		Msg("Session recycled")
 }
 
+// releaseClients detaches the session from its upstream clients.
+// HTTP clients are closed; STDIO processes may be shared with other sessions,
+// so only this session's notification handler is removed. Caller must hold s.mu.
+func (s *Session) releaseClients() {
+	for _, client := range s.clients {
+		client.RemoveNotificationHandler(s.ID)
+		client.RemoveRequestHandler(s.ID)
+		if _, isStdio := unwrapClient(client).(*stdio.Process); isStdio {
+			// Shared processes outlive the session; only drop its subscriptions
+			client.ReleaseSubscriptions(s.ID)
+		} else {
+			client.Close()
+		}
+	}
+}
+
 // NeedsRecycle returns true if the given role/groups differ from the session's stored values.
 func (s *Session) NeedsRecycle(role string, groups []string) bool {
 	s.mu.RLock()
@@ -2,7 +2,11 @@ package gateway
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
 	"sync"
+	"sync/atomic"
 
 	"github.com/reflow/gateway/internal/mcp"
 	"github.com/rs/zerolog/log"
@@ -15,12 +19,23 @@ type SSEHub struct {
 	mu          sync.RWMutex
 	broadcast   chan *mcp.SSEEvent
 	done        chan struct{}
+
+	replay     *replayBuffer              // recent events of all streams, for Last-Event-ID
+	seq        uint64                     // last event number on the notification stream (run only)
+	streams    map[string]*ResponseStream // open POST response streams, guarded by mu
+	nextStream atomic.Uint64
+}
+
+// EventWriter delivers hub events to one client connection: an SSE stream
+// (*mcp.SSEWriter) or a WebSocket
+type EventWriter interface {
+	WriteEvent(event *mcp.SSEEvent) error
 }
 
 // SSEConnection represents a single SSE connection
 type SSEConnection struct {
 	ID     string
-	Writer *mcp.SSEWriter
+	Writer EventWriter
 	Done   chan struct{}
 }
 
@@ -31,6 +46,8 @@ func NewSSEHub(sessionID string) *SSEHub {
 		connections: make(map[string]*SSEConnection),
 		broadcast:   make(chan *mcp.SSEEvent, 100),
 		done:        make(chan struct{}),
+		replay:      newReplayBuffer(replayBufferSize),
+		streams:     make(map[string]*ResponseStream),
 	}
 
 	go hub.run()
@@ -41,10 +58,15 @@ func NewSSEHub(sessionID string) *SSEHub {
 func (h *SSEHub) run() {
 	for {
 		select {
-		case event := <-h.broadcast:
+		case broadcast := <-h.broadcast:
 			h.mu.RLock()
+			// Number the event and keep it so a reconnecting client can catch up
+			h.seq++
+			event := *broadcast
+			event.ID = formatEventID(notificationStreamID, h.seq)
+			h.replay.add(notificationStreamID, h.seq, &event)
 			for id, conn := range h.connections {
-				if err := conn.Writer.WriteEvent(event); err != nil {
+				if err := conn.Writer.WriteEvent(&event); err != nil {
 					log.Error().Err(err).Str("connection", id).Msg("Failed to write SSE event")
 					// Mark for removal
 					go h.RemoveConnection(id)
@@ -58,16 +80,35 @@ func (h *SSEHub) run() {
 }
 
 // AddConnection adds a new SSE connection
-func (h *SSEHub) AddConnection(id string, writer *mcp.SSEWriter) *SSEConnection {
+func (h *SSEHub) AddConnection(id string, writer EventWriter) *SSEConnection {
+	h.mu.Lock()
+	conn := h.addConnectionLocked(id, writer)
+	h.mu.Unlock()
+	return conn
+}
+
+// ResumeConnection adds a notification stream connection for a client that
+// reconnected with Last-Event-ID, first replaying the buffered events it missed.
+// Holding the lock keeps run() from broadcasting in between.
+func (h *SSEHub) ResumeConnection(id string, writer EventWriter, lastSeq uint64) (*SSEConnection, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, event := range h.replay.after(notificationStreamID, lastSeq) {
+		if err := writer.WriteEvent(event); err != nil {
+			return nil, err
+		}
+	}
+	return h.addConnectionLocked(id, writer), nil
+}
+
+func (h *SSEHub) addConnectionLocked(id string, writer EventWriter) *SSEConnection {
 	conn := &SSEConnection{
 		ID:     id,
 		Writer: writer,
 		Done:   make(chan struct{}),
 	}
-
-	h.mu.Lock()
 	h.connections[id] = conn
-	h.mu.Unlock()
 
 	log.Debug().
 		Str("session_id", h.sessionID).
@@ -92,6 +133,63 @@ func (h *SSEHub) RemoveConnection(id string) {
 		Msg("Removed SSE connection")
 }
 
+// OpenResponseStream starts an SSE stream answering one POST, written to writer
+func (h *SSEHub) OpenResponseStream(writer *mcp.SSEWriter) *ResponseStream {
+	stream := &ResponseStream{
+		id:     strconv.FormatUint(h.nextStream.Add(1), 10),
+		hub:    h,
+		writer: writer,
+		done:   make(chan struct{}),
+	}
+
+	h.mu.Lock()
+	h.streams[stream.id] = stream
+	h.mu.Unlock()
+
+	return stream
+}
+
+// ResumeResponseStream reattaches a POST response stream to writer after the
+// client reconnected with Last-Event-ID, replaying the events after lastSeq. A
+// stream that already finished is replayed from the buffer and returned closed.
+func (h *SSEHub) ResumeResponseStream(streamID string, writer *mcp.SSEWriter, lastSeq uint64) (*ResponseStream, error) {
+	h.mu.RLock()
+	stream, open := h.streams[streamID]
+	h.mu.RUnlock()
+
+	if !open {
+		if !h.replay.has(streamID) {
+			return nil, fmt.Errorf("stream %s not found", streamID)
+		}
+		// Finished while the client was away: replay what it missed and end
+		stream = &ResponseStream{id: streamID, hub: h, closed: true, done: make(chan struct{})}
+		close(stream.done)
+	}
+
+	if err := stream.attach(writer, lastSeq); err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+// HasStream reports whether a stream can be resumed, because it is still open or
+// some of its events are still buffered
+func (h *SSEHub) HasStream(streamID string) bool {
+	if streamID == notificationStreamID {
+		return true
+	}
+	h.mu.RLock()
+	_, open := h.streams[streamID]
+	h.mu.RUnlock()
+	return open || h.replay.has(streamID)
+}
+
+func (h *SSEHub) removeStream(id string) {
+	h.mu.Lock()
+	delete(h.streams, id)
+	h.mu.Unlock()
+}
+
 // Broadcast sends an event to all connections
 func (h *SSEHub) Broadcast(event *mcp.SSEEvent) {
 	select {
@@ -101,16 +199,28 @@ func (h *SSEHub) Broadcast(event *mcp.SSEEvent) {
 	}
 }
 
-// BroadcastNotification broadcasts a JSON-RPC notification
+// BroadcastNotification broadcasts a JSON-RPC notification.
+// It goes through the broadcast channel so that writes to each connection
+// are serialized by run().
 func (h *SSEHub) BroadcastNotification(notification *mcp.JSONRPCNotification) {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
+	h.broadcastMessage(notification)
+}
 
-	for id, conn := range h.connections {
-		if err := conn.Writer.WriteJSONRPCNotification(notification); err != nil {
-			log.Error().Err(err).Str("connection", id).Msg("Failed to write notification")
-		}
+// BroadcastRequest broadcasts a server-initiated JSON-RPC request
+func (h *SSEHub) BroadcastRequest(req *mcp.JSONRPCRequest) {
+	h.broadcastMessage(req)
+}
+
+func (h *SSEHub) broadcastMessage(msg interface{}) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Error().Err(err).Str("session_id", h.sessionID).Msg("Failed to marshal message")
+		return
 	}
+	h.Broadcast(&mcp.SSEEvent{
+		Event: "message",
+		Data:  string(data),
+	})
 }
 
 // Close closes the hub and all connections
@@ -0,0 +1,204 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/reflow/gateway/internal/mcp"
+)
+
+// replayBufferSize bounds how many recent events a session keeps for
+// Last-Event-ID resumption, across all of its streams
+const replayBufferSize = 256
+
+// notificationStreamID identifies the session's GET /mcp notification stream in
+// event IDs. POST response streams are numbered from 1.
+const notificationStreamID = "0"
+
+var errStreamClosed = errors.New("response stream closed")
+
+// formatEventID builds an SSE event ID. IDs are unique within a session and name
+// the stream they belong to, so a reconnecting client resumes the right stream.
+func formatEventID(streamID string, seq uint64) string {
+	return streamID + ":" + strconv.FormatUint(seq, 10)
+}
+
+// parseEventID splits an SSE event ID into its stream and sequence number
+func parseEventID(id string) (string, uint64, error) {
+	streamID, seqStr, ok := strings.Cut(id, ":")
+	if !ok || streamID == "" {
+		return "", 0, fmt.Errorf("invalid event ID: %s", id)
+	}
+	seq, err := strconv.ParseUint(seqStr, 10, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid event ID: %s", id)
+	}
+	return streamID, seq, nil
+}
+
+// bufferedEvent is an event kept for replay
+type bufferedEvent struct {
+	streamID string
+	seq      uint64
+	event    *mcp.SSEEvent
+}
+
+// replayBuffer keeps the most recent events of a session's streams. Once full,
+// the oldest event is dropped for each new one.
+type replayBuffer struct {
+	mu     sync.Mutex
+	events []bufferedEvent
+	size   int
+}
+
+func newReplayBuffer(size int) *replayBuffer {
+	return &replayBuffer{size: size}
+}
+
+func (b *replayBuffer) add(streamID string, seq uint64, event *mcp.SSEEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.events) == b.size {
+		copy(b.events, b.events[1:])
+		b.events = b.events[:len(b.events)-1]
+	}
+	b.events = append(b.events, bufferedEvent{streamID: streamID, seq: seq, event: event})
+}
+
+// after returns the buffered events of a stream with a sequence number above seq
+func (b *replayBuffer) after(streamID string, seq uint64) []*mcp.SSEEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var events []*mcp.SSEEvent
+	for _, e := range b.events {
+		if e.streamID == streamID && e.seq > seq {
+			events = append(events, e.event)
+		}
+	}
+	return events
+}
+
+// has reports whether any event of a stream is still buffered
+func (b *replayBuffer) has(streamID string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, e := range b.events {
+		if e.streamID == streamID {
+			return true
+		}
+	}
+	return false
+}
+
+// ResponseStream is the SSE stream answering one POST /mcp request. It carries
+// notifications related to the request followed by the final response. Every
+// event is buffered in the session's hub, so when the POST connection drops the
+// client can reconnect with GET and Last-Event-ID and the stream continues on
+// the new connection.
+type ResponseStream struct {
+	id  string
+	hub *SSEHub
+
+	mu     sync.Mutex
+	seq    uint64
+	writer *mcp.SSEWriter // nil while no connection is attached
+	closed bool
+	done   chan struct{} // closed once the final response has been sent
+}
+
+// ID returns the stream's identifier within the session
+func (s *ResponseStream) ID() string {
+	return s.id
+}
+
+// Send writes a JSON-RPC message on the stream. The message is buffered for
+// replay even when no connection is attached.
+func (s *ResponseStream) Send(msg interface{}) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return errStreamClosed
+	}
+
+	s.seq++
+	event := &mcp.SSEEvent{
+		Event: "message",
+		ID:    formatEventID(s.id, s.seq),
+		Data:  string(data),
+	}
+	s.hub.replay.add(s.id, s.seq, event)
+
+	if s.writer != nil {
+		if err := s.writer.WriteEvent(event); err != nil {
+			// Connection gone; the client can resume from the buffer
+			s.writer = nil
+		}
+	}
+	return nil
+}
+
+// Close ends the stream after its final response
+func (s *ResponseStream) Close() {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		s.writer = nil
+		close(s.done)
+	}
+	s.mu.Unlock()
+	s.hub.removeStream(s.id)
+}
+
+// Done is closed once the stream has sent its final response
+func (s *ResponseStream) Done() <-chan struct{} {
+	return s.done
+}
+
+// attach replays the events after seq to writer and makes it the stream's
+// connection. Events sent concurrently are neither lost nor duplicated.
+func (s *ResponseStream) attach(writer *mcp.SSEWriter, seq uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, event := range s.hub.replay.after(s.id, seq) {
+		if err := writer.WriteEvent(event); err != nil {
+			return err
+		}
+	}
+	if !s.closed {
+		s.writer = writer
+	}
+	return nil
+}
+
+// Detach stops writing to writer if it is still the stream's connection. It must
+// be called before the HTTP handler that owns writer returns.
+func (s *ResponseStream) Detach(writer *mcp.SSEWriter) {
+	s.mu.Lock()
+	if s.writer == writer {
+		s.writer = nil
+	}
+	s.mu.Unlock()
+}
+
+type responseStreamKey struct{}
+
+// withResponseStream attaches the POST response stream a request is answered on
+func withResponseStream(ctx context.Context, stream *ResponseStream) context.Context {
+	return context.WithValue(ctx, responseStreamKey{}, stream)
+}
+
+// responseStreamFrom returns the POST response stream a request is answered on, if any
+func responseStreamFrom(ctx context.Context) *ResponseStream {
+	stream, _ := ctx.Value(responseStreamKey{}).(*ResponseStream)
+	return stream
+}
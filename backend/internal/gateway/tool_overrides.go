@@ -0,0 +1,142 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/reflow/gateway/internal/auth"
+	"github.com/reflow/gateway/internal/database"
+	"github.com/reflow/gateway/internal/mcp"
+)
+
+// toolOverrides indexes a target's admin-defined tool overrides by upstream tool name
+type toolOverrides map[string]*database.ToolOverride
+
+// loadToolOverrides reads a target's tool overrides
+func (p *Proxy) loadToolOverrides(ctx context.Context, targetID uuid.UUID) (toolOverrides, error) {
+	if p.repo == nil {
+		return nil, nil
+	}
+	list, err := p.repo.GetToolOverridesForTarget(ctx, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tool overrides: %w", err)
+	}
+
+	overrides := make(toolOverrides, len(list))
+	for _, override := range list {
+		overrides[override.ToolName] = override
+	}
+	return overrides, nil
+}
+
+// upstreamName maps a listed (unprefixed) tool name back to the upstream name.
+// An upstream name that an override renamed no longer resolves.
+func (o toolOverrides) upstreamName(name string) (string, bool) {
+	for _, override := range o {
+		if override.Name != nil && *override.Name == name {
+			return override.ToolName, true
+		}
+	}
+	if override, ok := o[name]; ok && override.Name != nil {
+		return "", false
+	}
+	return name, true
+}
+
+// apply rewrites an upstream tool's name, description and inputSchema. Fixed
+// arguments are removed from the schema so clients never see them. The returned
+// name is not yet prefixed.
+func (o toolOverrides) apply(tool mcp.Tool) (mcp.Tool, map[string]interface{}, error) {
+	override, ok := o[tool.Name]
+	if !ok {
+		return tool, nil, nil
+	}
+
+	if override.Name != nil {
+		tool.Name = *override.Name
+	}
+	if override.Description != nil {
+		tool.Description = *override.Description
+	}
+	if len(override.InputSchema) > 0 {
+		tool.InputSchema = override.InputSchema
+	}
+	if len(override.FixedArguments) > 0 {
+		schema, err := hideArguments(tool.InputSchema, override.FixedArguments)
+		if err != nil {
+			return tool, nil, err
+		}
+		tool.InputSchema = schema
+	}
+	return tool, override.FixedArguments, nil
+}
+
+// overridesSchema reports whether an override replaces the tool's inputSchema
+func (o toolOverrides) overridesSchema(toolName string) bool {
+	override, ok := o[toolName]
+	return ok && len(override.InputSchema) > 0
+}
+
+// hideArguments drops the given properties from an object schema, along with
+// their entries in "required". Other schema keywords are kept.
+func hideArguments(schema json.RawMessage, hidden map[string]interface{}) (json.RawMessage, error) {
+	if len(schema) == 0 {
+		return schema, nil
+	}
+	var s map[string]interface{}
+	if err := json.Unmarshal(schema, &s); err != nil {
+		return nil, fmt.Errorf("invalid inputSchema: %w", err)
+	}
+
+	if properties, ok := s["properties"].(map[string]interface{}); ok {
+		for name := range hidden {
+			delete(properties, name)
+		}
+	}
+	if required, ok := s["required"].([]interface{}); ok {
+		kept := make([]interface{}, 0, len(required))
+		for _, name := range required {
+			if n, ok := name.(string); ok {
+				if _, isHidden := hidden[n]; isHidden {
+					continue
+				}
+			}
+			kept = append(kept, name)
+		}
+		s["required"] = kept
+	}
+
+	return json.Marshal(s)
+}
+
+// applyFixedArguments returns the client's arguments with every fixed argument
+// set, overriding anything the client sent. ${user.*} placeholders in string
+// values are replaced with the calling user's identity.
+func applyFixedArguments(ctx context.Context, session *Session, args, fixed map[string]interface{}) map[string]interface{} {
+	if len(fixed) == 0 {
+		return args
+	}
+
+	email, _ := auth.GetUserEmail(ctx)
+	vars := map[string]string{
+		"user.id":    session.UserID.String(),
+		"user.email": email,
+		"user.role":  session.Role,
+	}
+
+	merged := make(map[string]interface{}, len(args)+len(fixed))
+	for name, value := range args {
+		merged[name] = value
+	}
+	for name, value := range fixed {
+		if s, ok := value.(string); ok {
+			value = database.ArgumentTemplatePattern.ReplaceAllStringFunc(s, func(match string) string {
+				return vars[match[2:len(match)-1]]
+			})
+		}
+		merged[name] = value
+	}
+	return merged
+}
@@ -0,0 +1,192 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/reflow/gateway/internal/mcp"
+)
+
+// Translation between MCP protocol revisions.
+//
+// Each client session and each upstream negotiate their own revision. Upstreams
+// are always offered the newest revision, so an upstream is never newer than the
+// gateway; a client may be older than the upstream it reaches (results are
+// downgraded on the way out) and an upstream may be older than the client
+// (requests are downgraded on the way in). Fields an older peer does not know
+// are removed or, where they carry meaning, folded into something it does know.
+
+// downgradeServerCapabilities removes capabilities the client's revision does not define
+func downgradeServerCapabilities(version string, caps mcp.ServerCapabilities) mcp.ServerCapabilities {
+	if !mcp.ProtocolAtLeast(version, mcp.ProtocolVersion20250326) {
+		caps.Completions = nil
+	}
+	return caps
+}
+
+// downgradeTools strips tool fields introduced after the client's revision
+func downgradeTools(version string, tools []mcp.Tool) {
+	for i := range tools {
+		if !mcp.ProtocolAtLeast(version, mcp.ProtocolVersion20250618) {
+			tools[i].Title = ""
+			tools[i].OutputSchema = nil
+		}
+		if !mcp.ProtocolAtLeast(version, mcp.ProtocolVersion20250326) {
+			tools[i].Annotations = nil
+		}
+	}
+}
+
+// downgradeToolCallResult adapts a tool result for the client's revision.
+// Clients before 2025-06-18 have no structuredContent; the spec asks servers to
+// mirror it as serialized JSON in a text block, so the gateway adds one when the
+// upstream did not.
+func downgradeToolCallResult(version string, result *mcp.ToolCallResult) {
+	if result == nil {
+		return
+	}
+	if !mcp.ProtocolAtLeast(version, mcp.ProtocolVersion20250618) && len(result.StructuredContent) > 0 {
+		if isEmptyJSONArray(result.Content) {
+			result.Content, _ = json.Marshal([]mcp.Content{{Type: "text", Text: string(result.StructuredContent)}})
+		}
+		result.StructuredContent = nil
+	}
+	result.Content = downgradeContentList(version, result.Content)
+}
+
+// downgradeResources strips resource fields introduced after the client's revision
+func downgradeResources(version string, resources []mcp.Resource) {
+	if mcp.ProtocolAtLeast(version, mcp.ProtocolVersion20250618) {
+		return
+	}
+	for i := range resources {
+		resources[i].Title = ""
+	}
+}
+
+// downgradeResourceTemplates strips template fields introduced after the client's revision
+func downgradeResourceTemplates(version string, templates []mcp.ResourceTemplate) {
+	if mcp.ProtocolAtLeast(version, mcp.ProtocolVersion20250618) {
+		return
+	}
+	for i := range templates {
+		templates[i].Title = ""
+	}
+}
+
+// downgradePrompts strips prompt fields introduced after the client's revision
+func downgradePrompts(version string, prompts []mcp.Prompt) {
+	if mcp.ProtocolAtLeast(version, mcp.ProtocolVersion20250618) {
+		return
+	}
+	for i := range prompts {
+		prompts[i].Title = ""
+	}
+}
+
+// downgradePromptGetResult adapts the content of each prompt message for the client's revision
+func downgradePromptGetResult(version string, result *mcp.PromptGetResult) {
+	if result == nil || mcp.ProtocolAtLeast(version, mcp.ProtocolVersion20250618) {
+		return
+	}
+	var messages []map[string]json.RawMessage
+	if err := json.Unmarshal(result.Messages, &messages); err != nil {
+		return
+	}
+	for _, message := range messages {
+		if content, ok := message["content"]; ok {
+			message["content"] = downgradeContentBlock(version, content)
+		}
+	}
+	if encoded, err := json.Marshal(messages); err == nil {
+		result.Messages = encoded
+	}
+}
+
+// downgradeProgressParams removes the progress message, which 2024-11-05 does not define
+func downgradeProgressParams(version string, params json.RawMessage) json.RawMessage {
+	if mcp.ProtocolAtLeast(version, mcp.ProtocolVersion20250326) {
+		return params
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(params, &fields); err != nil {
+		return params
+	}
+	if _, ok := fields["message"]; !ok {
+		return params
+	}
+	delete(fields, "message")
+	if encoded, err := json.Marshal(fields); err == nil {
+		return encoded
+	}
+	return params
+}
+
+// downgradeCompleteParams removes the completion context, which upstreams before
+// 2025-06-18 do not define
+func downgradeCompleteParams(upstreamVersion string, params *mcp.CompleteParams) {
+	if !mcp.ProtocolAtLeast(upstreamVersion, mcp.ProtocolVersion20250618) {
+		params.Context = nil
+	}
+}
+
+// downgradeContentList adapts every block of a content array
+func downgradeContentList(version string, raw json.RawMessage) json.RawMessage {
+	if mcp.ProtocolAtLeast(version, mcp.ProtocolVersion20250618) {
+		return raw
+	}
+	var blocks []json.RawMessage
+	if err := json.Unmarshal(raw, &blocks); err != nil {
+		return raw
+	}
+	for i, block := range blocks {
+		blocks[i] = downgradeContentBlock(version, block)
+	}
+	encoded, err := json.Marshal(blocks)
+	if err != nil {
+		return raw
+	}
+	return encoded
+}
+
+// downgradeContentBlock replaces content types the client's revision does not
+// define with a text block describing them: resource links (2025-06-18) and
+// audio (2025-03-26).
+func downgradeContentBlock(version string, raw json.RawMessage) json.RawMessage {
+	var block struct {
+		Type     string `json:"type"`
+		URI      string `json:"uri"`
+		Name     string `json:"name"`
+		MimeType string `json:"mimeType"`
+	}
+	if err := json.Unmarshal(raw, &block); err != nil {
+		return raw
+	}
+
+	var text string
+	switch {
+	case block.Type == "resource_link" && !mcp.ProtocolAtLeast(version, mcp.ProtocolVersion20250618):
+		text = fmt.Sprintf("Resource: %s", block.URI)
+		if block.Name != "" {
+			text = fmt.Sprintf("Resource %s: %s", block.Name, block.URI)
+		}
+	case block.Type == "audio" && !mcp.ProtocolAtLeast(version, mcp.ProtocolVersion20250326):
+		text = fmt.Sprintf("[audio content (%s) omitted]", block.MimeType)
+	default:
+		return raw
+	}
+
+	encoded, err := json.Marshal(mcp.Content{Type: "text", Text: text})
+	if err != nil {
+		return raw
+	}
+	return encoded
+}
+
+func isEmptyJSONArray(raw json.RawMessage) bool {
+	var items []json.RawMessage
+	if len(raw) == 0 || string(raw) == "null" {
+		return true
+	}
+	return json.Unmarshal(raw, &items) == nil && len(items) == 0
+}
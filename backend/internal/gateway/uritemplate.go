@@ -0,0 +1,51 @@
+package gateway
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// compileURITemplate builds a matcher for URIs expanded from an RFC 6570 URI template.
+// Matching is deliberately lenient: it only needs to tell which template (and so which
+// target) a concrete URI came from, not recover the variable values.
+func compileURITemplate(template string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+
+	rest := template
+	for rest != "" {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			b.WriteString(regexp.QuoteMeta(rest))
+			break
+		}
+		closing := strings.IndexByte(rest[open:], '}')
+		if closing < 0 {
+			return nil, fmt.Errorf("unterminated expression in URI template: %s", template)
+		}
+		closing += open
+
+		b.WriteString(regexp.QuoteMeta(rest[:open]))
+		b.WriteString(expressionPattern(rest[open+1 : closing]))
+		rest = rest[closing+1:]
+	}
+
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// expressionPattern returns the regexp fragment matching one template expression.
+func expressionPattern(expr string) string {
+	if expr == "" {
+		return ""
+	}
+	switch expr[0] {
+	case '+', '#', '?', '&', '/':
+		// Reserved, fragment, query and path expansions may contain delimiters
+		return ".*"
+	default:
+		// Simple, label and path-parameter expansions stay within one segment
+		return "[^/?#]*"
+	}
+}
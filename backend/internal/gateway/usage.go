@@ -0,0 +1,340 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reflow/gateway/internal/auth"
+	"github.com/reflow/gateway/internal/database"
+	"github.com/reflow/gateway/internal/mcp"
+	"github.com/reflow/gateway/internal/observability"
+	"github.com/rs/zerolog/log"
+)
+
+// usageConfigTTL bounds how long quota and cost weight changes take to reach
+// running sessions
+const usageConfigTTL = 10 * time.Second
+
+// QuotaError is returned when a call would exceed a usage quota
+type QuotaError struct {
+	Quota   string
+	Period  string
+	Used    float64
+	MaxCost float64
+	ResetAt time.Time
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("Usage quota %q exceeded (%g of %g this %s), resets at %s",
+		e.Quota, e.Used, e.MaxCost, e.Period, e.ResetAt.Format(time.RFC3339))
+}
+
+// usageCache holds the enabled usage quotas and the cost weights
+type usageCache struct {
+	mu       sync.Mutex
+	quotas   []*database.UsageQuota
+	weights  []*database.CostWeight
+	loadedAt time.Time
+}
+
+// quotaUsage is a quota that applies to a call, with the running total it
+// counts the call in and the usage counted against it before the call
+type quotaUsage struct {
+	quota       *database.UsageQuota
+	key         string
+	periodStart time.Time
+	used        float64
+}
+
+// usageConfig returns the enabled usage quotas and the cost weights
+func (p *Proxy) usageConfig(ctx context.Context) ([]*database.UsageQuota, []*database.CostWeight, error) {
+	p.usage.mu.Lock()
+	defer p.usage.mu.Unlock()
+
+	if time.Since(p.usage.loadedAt) > usageConfigTTL {
+		quotas, err := p.repo.ListUsageQuotas(ctx, true)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load usage quotas: %w", err)
+		}
+		weights, err := p.repo.ListCostWeights(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load cost weights: %w", err)
+		}
+		p.usage.quotas = quotas
+		p.usage.weights = weights
+		p.usage.loadedAt = time.Now()
+	}
+	return p.usage.quotas, p.usage.weights, nil
+}
+
+// checkQuotas returns a *QuotaError if a session has used up a quota that
+// applies to calls to a target, or else the quotas that apply with their usage
+// so far. Like rate limits, quotas fail open.
+func (p *Proxy) checkQuotas(ctx context.Context, session *Session, targetID uuid.UUID, targetName string) ([]quotaUsage, error) {
+	if p.repo == nil {
+		return nil, nil
+	}
+	quotas, _, err := p.usageConfig(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Usage quotas not enforced")
+		return nil, nil
+	}
+
+	now := time.Now()
+	var applied []quotaUsage
+	for _, quota := range quotas {
+		if !quotaApplies(quota, session, targetID) {
+			continue
+		}
+
+		key := quotaKey(quota, session)
+		periodStart := quota.PeriodStart(now)
+		used, err := p.repo.GetQuotaTotal(ctx, quota.ID, key, periodStart, quotaFilter(quota, session, now))
+		if err != nil {
+			log.Error().Err(err).Str("quota", quota.Name).Msg("Usage quota not enforced")
+			continue
+		}
+		if used >= quota.MaxCost {
+			log.Info().
+				Str("quota", quota.Name).
+				Str("user_id", session.UserID.String()).
+				Str("target", targetName).
+				Float64("used", used).
+				Msg("Call rejected by usage quota")
+			p.emitQuota(ctx, session, quota, used, "exceeded")
+			return nil, &QuotaError{
+				Quota:   quota.Name,
+				Period:  quota.Period,
+				Used:    used,
+				MaxCost: quota.MaxCost,
+				ResetAt: quota.PeriodEnd(now),
+			}
+		}
+		applied = append(applied, quotaUsage{quota: quota, key: key, periodStart: periodStart, used: used})
+	}
+	return applied, nil
+}
+
+// recordUsage meters a call forwarded to an upstream target, adds its cost to
+// the running totals of the quotas that apply, then warns the session about
+// each quota whose warning threshold the call crossed
+func (p *Proxy) recordUsage(ctx context.Context, session *Session, quotas []quotaUsage, start time.Time, method string, targetID uuid.UUID, targetName, name string, request, response interface{}, status string) {
+	if p.repo == nil {
+		return
+	}
+	_, weights, err := p.usageConfig(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("Metering call at the default cost")
+	}
+
+	rec := newUsageRecord(ctx, session, start, method, targetID, targetName, name, request, response)
+	rec.Status = status
+	rec.Cost = callCost(weights, targetID, method, name)
+	totals := make([]database.QuotaTotal, len(quotas))
+	for i, q := range quotas {
+		totals[i] = database.QuotaTotal{QuotaID: q.quota.ID, Key: q.key, PeriodStart: q.periodStart}
+	}
+	if err := p.repo.CreateUsageRecord(context.WithoutCancel(ctx), rec, totals); err != nil {
+		log.Error().Err(err).Str("target", targetName).Str("name", name).Msg("Failed to record usage")
+		return
+	}
+
+	for _, q := range quotas {
+		before := q.used / q.quota.MaxCost * 100
+		after := (q.used + rec.Cost) / q.quota.MaxCost * 100
+		crossed := 0
+		for _, threshold := range q.quota.WarnThresholds {
+			if before < float64(threshold) && after >= float64(threshold) && threshold > crossed {
+				crossed = threshold
+			}
+		}
+		if crossed > 0 {
+			p.warnQuota(ctx, session, q.quota, q.used+rec.Cost, crossed)
+		}
+	}
+}
+
+// recordCacheHit meters a call answered from the response cache. It is marked
+// cached and costs nothing, so it does not count against quotas.
+func (p *Proxy) recordCacheHit(ctx context.Context, session *Session, start time.Time, method string, targetID uuid.UUID, targetName, name string, request, response interface{}) {
+	if p.repo == nil {
+		return
+	}
+	rec := newUsageRecord(ctx, session, start, method, targetID, targetName, name, request, response)
+	rec.Status = "ok"
+	rec.Cached = true
+	if err := p.repo.CreateUsageRecord(context.WithoutCancel(ctx), rec, nil); err != nil {
+		log.Error().Err(err).Str("target", targetName).Str("name", name).Msg("Failed to record usage")
+	}
+}
+
+// newUsageRecord describes a session's call for metering
+func newUsageRecord(ctx context.Context, session *Session, start time.Time, method string, targetID uuid.UUID, targetName, name string, request, response interface{}) *database.UsageRecord {
+	userID := session.UserID
+	userEmail, _ := auth.GetUserEmail(ctx)
+	return &database.UsageRecord{
+		UserID:        &userID,
+		UserEmail:     userEmail,
+		Role:          session.Role,
+		Groups:        session.Groups,
+		TargetID:      &targetID,
+		TargetName:    targetName,
+		Method:        method,
+		Name:          name,
+		RequestBytes:  jsonSize(request),
+		ResponseBytes: jsonSize(response),
+		DurationMS:    int(time.Since(start).Milliseconds()),
+	}
+}
+
+// warnQuota tells the client, through a log notification, that it has used a
+// threshold percentage of a quota
+func (p *Proxy) warnQuota(ctx context.Context, session *Session, quota *database.UsageQuota, used float64, threshold int) {
+	log.Info().
+		Str("quota", quota.Name).
+		Str("user_id", session.UserID.String()).
+		Int("threshold", threshold).
+		Float64("used", used).
+		Msg("Usage quota warning threshold reached")
+	p.emitQuota(ctx, session, quota, used, "warning")
+
+	params, err := json.Marshal(map[string]interface{}{
+		"level":  "warning",
+		"logger": "gateway",
+		"data": map[string]interface{}{
+			"message": fmt.Sprintf("%d%% of usage quota %q used this %s", threshold, quota.Name, quota.Period),
+			"quota":   quota.Name,
+			"used":    used,
+			"maxCost": quota.MaxCost,
+			"resetAt": quota.PeriodEnd(time.Now()),
+		},
+	})
+	if err != nil {
+		return
+	}
+	session.SendNotification(&mcp.JSONRPCNotification{
+		JSONRPC: mcp.JSONRPCVersion,
+		Method:  mcp.MethodNotificationMessage,
+		Params:  params,
+	})
+}
+
+func (p *Proxy) emitQuota(ctx context.Context, session *Session, quota *database.UsageQuota, used float64, status string) {
+	if p.obsHub == nil {
+		return
+	}
+	userEmail, _ := auth.GetUserEmail(ctx)
+	p.obsHub.EmitQuota(observability.QuotaEvent{
+		Timestamp: time.Now(),
+		UserID:    session.UserID.String(),
+		UserEmail: userEmail,
+		Quota:     quota.Name,
+		Period:    quota.Period,
+		Used:      used,
+		MaxCost:   quota.MaxCost,
+		Status:    status,
+	})
+}
+
+// quotaApplies reports whether a quota covers a session's calls to a target
+func quotaApplies(quota *database.UsageQuota, session *Session, targetID uuid.UUID) bool {
+	if quota.TargetID != nil && *quota.TargetID != targetID {
+		return false
+	}
+
+	value := ""
+	if quota.SubjectValue != nil {
+		value = *quota.SubjectValue
+	}
+	switch quota.SubjectType {
+	case "everyone":
+		return true
+	case "user":
+		return value == session.UserID.String()
+	case "role":
+		return value == session.Role
+	case "group":
+		for _, group := range session.Groups {
+			if group == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// quotaKey is the running total a session's usage counts in: the user's own,
+// or "*" when the quota's subject shares one budget
+func quotaKey(quota *database.UsageQuota, session *Session) string {
+	if quota.PerUser || quota.SubjectType == "user" {
+		return session.UserID.String()
+	}
+	return "*"
+}
+
+// quotaFilter selects the usage counted against a quota in the current period:
+// the session user's own, or with per_user off, that of everyone the quota's
+// subject covers
+func quotaFilter(quota *database.UsageQuota, session *Session, now time.Time) database.UsageFilter {
+	filter := database.UsageFilter{
+		From:     quota.PeriodStart(now),
+		TargetID: quota.TargetID,
+	}
+	if quota.PerUser {
+		userID := session.UserID
+		filter.UserID = &userID
+		return filter
+	}
+	switch quota.SubjectType {
+	case "user":
+		userID := session.UserID
+		filter.UserID = &userID
+	case "role":
+		filter.Role = quota.SubjectValue
+	case "group":
+		filter.Group = quota.SubjectValue
+	}
+	return filter
+}
+
+// callCost is the weight for a tool, else for its target, else the default
+func callCost(weights []*database.CostWeight, targetID uuid.UUID, method, name string) float64 {
+	cost := database.DefaultCostWeight
+	for _, w := range weights {
+		if w.TargetID != targetID {
+			continue
+		}
+		if w.ToolName == nil {
+			cost = w.Weight
+			continue
+		}
+		if method == mcp.MethodToolsCall && *w.ToolName == name {
+			return w.Weight
+		}
+	}
+	return cost
+}
+
+// jsonSize is the size of v encoded as JSON, or 0 if it cannot be encoded
+func jsonSize(v interface{}) int {
+	if v == nil {
+		return 0
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return len(raw)
+}
+
+// usageStatus is the status a call is metered with
+func usageStatus(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
@@ -0,0 +1,283 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/reflow/gateway/internal/auth"
+	"github.com/reflow/gateway/internal/mcp"
+	"github.com/reflow/gateway/internal/observability"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var wsUpgrader = websocket.Upgrader{
+	Subprotocols: []string{mcp.WebSocketSubprotocol},
+	CheckOrigin:  func(r *http.Request) bool { return true },
+}
+
+// wsConnection serializes writes to a client WebSocket. It is registered with the
+// session hub as an EventWriter, so notifications and server requests share the
+// connection with responses.
+type wsConnection struct {
+	conn *websocket.Conn
+	mu   sync.Mutex
+}
+
+// WriteEvent sends a hub event's JSON-RPC payload as a text frame
+func (c *wsConnection) WriteEvent(event *mcp.SSEEvent) error {
+	if event.Data == "" {
+		return nil
+	}
+	return c.write([]byte(event.Data))
+}
+
+// WriteJSON sends a JSON-RPC message (or batch) as a text frame
+func (c *wsConnection) WriteJSON(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.write(data)
+}
+
+func (c *wsConnection) write(data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(mcp.WebSocketWriteTimeout))
+	return c.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+// HandleWebSocket serves MCP over a WebSocket (GET /mcp/ws).
+//
+// The connection is the session: the first message must be initialize, every
+// later message is dispatched like a POST to that session, and responses,
+// notifications and server-initiated requests are all written back as text
+// frames. Requests are handled concurrently, so responses may arrive out of
+// order. The session is deleted when the connection closes.
+func (h *Handler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
+	// Auth is enforced by middleware; extract identity
+	userID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("MCP WebSocket upgrade failed")
+		return
+	}
+	defer conn.Close()
+	conn.SetReadLimit(mcp.WebSocketMaxMessageSize)
+
+	// In-flight requests end with the connection
+	ctx, cancel := context.WithCancel(r.Context())
+	ws := &wsConnection{conn: conn}
+
+	var session *Session
+	var hubConn *SSEConnection
+	var inflight sync.WaitGroup
+	connID := uuid.New().String()
+
+	go h.keepWebSocketAlive(ctx, conn)
+
+	defer func() {
+		cancel()
+		inflight.Wait()
+		if session != nil {
+			h.closeWebSocketSession(session, hubConn, connID, userID)
+		}
+	}()
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				log.Debug().Err(err).Str("user_id", userID.String()).Msg("MCP WebSocket reader ended")
+			}
+			return
+		}
+		startTime := time.Now()
+
+		// --- Initialize: the first message creates the session ---
+		if session == nil {
+			session = h.initializeWebSocket(ctx, ws, userID, data, startTime)
+			if session == nil {
+				continue
+			}
+			hubConn = session.SSEHub().AddConnection(connID, ws)
+			go func() {
+				// Session deleted or expired elsewhere; unblock the reader
+				select {
+				case <-hubConn.Done:
+					conn.Close()
+				case <-ctx.Done():
+				}
+			}()
+			continue
+		}
+
+		// Refresh the session's activity; stop if it expired meanwhile
+		if _, err := h.sessionManager.GetSession(ctx, session.ID); err != nil {
+			msg := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "Session not found or expired")
+			conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+			return
+		}
+
+		if isBatch(data) {
+			var elements []json.RawMessage
+			if err := json.Unmarshal(data, &elements); err != nil || len(elements) == 0 {
+				ws.WriteJSON(mcp.NewErrorResponse(nil, mcp.InvalidRequest, "Invalid batch"))
+				continue
+			}
+			if !batchingSupported(session.ProtocolVersion()) {
+				ws.WriteJSON(mcp.NewErrorResponse(nil, mcp.InvalidRequest, "Batches are not supported in protocol version "+session.ProtocolVersion()))
+				continue
+			}
+			inflight.Add(1)
+			go func() {
+				defer inflight.Done()
+				if batch := h.dispatchBatch(ctx, session, userID, elements); len(batch) > 0 {
+					ws.WriteJSON(batch)
+				}
+			}()
+			continue
+		}
+
+		var req mcp.JSONRPCRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			ws.WriteJSON(mcp.NewErrorResponse(nil, mcp.ParseError, "Invalid JSON"))
+			continue
+		}
+		if req.Method == mcp.MethodInitialize {
+			ws.WriteJSON(mcp.NewErrorResponse(req.ID, mcp.InvalidRequest, "Session already initialized"))
+			continue
+		}
+
+		// Client responses and notifications are handled in order; a cancellation
+		// must not overtake the request it cancels
+		if req.Method == "" || req.IsNotification() {
+			h.dispatchMessage(ctx, session, &req, data)
+			continue
+		}
+
+		inflight.Add(1)
+		go func() {
+			defer inflight.Done()
+			h.handleWebSocketRequest(ctx, ws, session, userID, &req, data, startTime)
+		}()
+	}
+}
+
+// initializeWebSocket handles the initialize message that opens a WebSocket
+// session. Returns nil, after answering with the error, if it fails.
+func (h *Handler) initializeWebSocket(ctx context.Context, ws *wsConnection, userID uuid.UUID, data []byte, startTime time.Time) *Session {
+	var req mcp.JSONRPCRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		ws.WriteJSON(mcp.NewErrorResponse(nil, mcp.ParseError, "Invalid JSON"))
+		return nil
+	}
+	if req.Method != mcp.MethodInitialize {
+		ws.WriteJSON(mcp.NewErrorResponse(req.ID, mcp.InvalidRequest, "Session required. Send initialize first."))
+		return nil
+	}
+
+	session, result, errResp := h.initializeSession(ctx, userID, "", &req)
+	if errResp != nil {
+		ws.WriteJSON(errResp)
+		return nil
+	}
+
+	resp, err := mcp.NewSuccessResponse(req.ID, result)
+	if err != nil {
+		resp = mcp.NewErrorResponse(req.ID, mcp.InternalError, "Failed to marshal response")
+	}
+	if err := ws.WriteJSON(resp); err != nil {
+		log.Error().Err(err).Str("session_id", session.ID).Msg("Failed to write initialize response")
+	}
+
+	log.Info().
+		Str("session_id", session.ID).
+		Str("protocol_version", result.ProtocolVersion).
+		Msg("MCP WebSocket session opened")
+
+	h.recordInitialize(ctx, session.ID, userID, data, startTime)
+	return session
+}
+
+// handleWebSocketRequest routes one request through the proxy and writes its response
+func (h *Handler) handleWebSocketRequest(ctx context.Context, ws *wsConnection, session *Session, userID uuid.UUID, req *mcp.JSONRPCRequest, body []byte, startTime time.Time) {
+	ctx, span := tracer.Start(ctx, "handleWebSocketMessage",
+		trace.WithAttributes(
+			attribute.String("mcp.method", req.Method),
+			attribute.String("mcp.user_id", userID.String()),
+			attribute.String("mcp.session_id", session.ID),
+		),
+	)
+	defer span.End()
+
+	injectTraceID(ctx)
+
+	resp, ok := h.dispatchMessage(ctx, session, req, body)
+	if resp != nil {
+		if err := ws.WriteJSON(resp); err != nil {
+			log.Debug().Err(err).Str("session_id", session.ID).Str("method", req.Method).Msg("Failed to write WebSocket response")
+		}
+	}
+
+	if ok {
+		h.recordRequest(ctx, session.ID, userID, req.Method, body, startTime)
+	}
+}
+
+// keepWebSocketAlive pings the client until the connection ends, so idle
+// connections survive proxies that drop silent TCP streams
+func (h *Handler) keepWebSocketAlive(ctx context.Context, conn *websocket.Conn) {
+	ticker := time.NewTicker(mcp.WebSocketPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(mcp.WebSocketWriteTimeout)); err != nil {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// closeWebSocketSession deletes the session a closed WebSocket owned, unless it
+// was already deleted or expired while the connection was open
+func (h *Handler) closeWebSocketSession(session *Session, hubConn *SSEConnection, connID string, userID uuid.UUID) {
+	select {
+	case <-hubConn.Done:
+		log.Info().Str("session_id", session.ID).Msg("MCP WebSocket closed after session ended")
+		return
+	default:
+	}
+
+	session.SSEHub().RemoveConnection(connID)
+
+	ctx := context.Background()
+	if err := h.sessionManager.DeleteSession(ctx, session.ID); err != nil {
+		log.Debug().Err(err).Str("session_id", session.ID).Msg("Failed to delete WebSocket session")
+	}
+	if h.obsHub != nil {
+		h.obsHub.EmitSession(observability.SessionEvent{
+			Event:     "deleted",
+			SessionID: session.ID,
+			UserID:    userID.String(),
+		})
+	}
+
+	log.Info().Str("session_id", session.ID).Msg("MCP WebSocket session closed")
+}
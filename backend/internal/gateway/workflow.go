@@ -0,0 +1,219 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/reflow/gateway/internal/database"
+	"github.com/reflow/gateway/internal/jsonschema"
+	"github.com/reflow/gateway/internal/mcp"
+	"github.com/reflow/gateway/internal/workflow"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// virtualToolOwner is the owner recorded for virtual tools in name collision reports
+const virtualToolOwner = "virtual"
+
+type workflowContextKey struct{}
+
+// listVirtualTools returns the enabled virtual tools the session may call.
+// Virtual tools are not namespace-prefixed; they are authorized as tools of no
+// particular target, so only global tool policies apply.
+func (p *Proxy) listVirtualTools(ctx context.Context, session *Session) ([]*database.VirtualTool, error) {
+	if p.repo == nil {
+		return nil, nil
+	}
+	tools, err := p.repo.ListVirtualTools(ctx, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load virtual tools: %w", err)
+	}
+
+	allowed := tools[:0]
+	for _, vt := range tools {
+		if p.authorizer != nil {
+			canAccess, _, err := p.authorizer.CanAccess(ctx, session.UserID, session.Role, session.Groups, nil, "tool", vt.Name)
+			if err != nil || !canAccess {
+				continue
+			}
+		}
+		allowed = append(allowed, vt)
+	}
+	return allowed, nil
+}
+
+// lookupVirtualTool finds an enabled virtual tool by name, or returns nil
+func (p *Proxy) lookupVirtualTool(ctx context.Context, name string) (*database.VirtualTool, error) {
+	if p.repo == nil {
+		return nil, nil
+	}
+	vt, err := p.repo.GetVirtualToolByName(ctx, name)
+	if err == database.ErrNotFound || (err == nil && !vt.Enabled) {
+		return nil, nil
+	}
+	return vt, err
+}
+
+// callVirtualTool runs a virtual tool's steps in order. Each step is an ordinary
+// CallTool, so tool overrides and per-tool authorization apply to it as if the
+// client had called it directly. A failed step aborts the workflow unless it is
+// marked on_error "continue".
+func (p *Proxy) callVirtualTool(ctx context.Context, session *Session, vt *database.VirtualTool, params *mcp.ToolCallParams) (*mcp.ToolCallResult, error) {
+	ctx, span := tracer.Start(ctx, "Proxy.callVirtualTool",
+		trace.WithAttributes(
+			attribute.String("tool.name", vt.Name),
+			attribute.Int("workflow.steps", len(vt.Steps)),
+		),
+	)
+	defer span.End()
+	start := time.Now()
+
+	if ctx.Value(workflowContextKey{}) != nil {
+		return mcp.NewToolCallError(fmt.Sprintf("Virtual tool %s cannot be called from another virtual tool", vt.Name)), nil
+	}
+	ctx = context.WithValue(ctx, workflowContextKey{}, vt.Name)
+
+	args := params.Arguments
+	if args == nil {
+		args = make(map[string]interface{})
+	}
+
+	mapping := ToolMapping{ToolName: vt.Name, VirtualTool: vt}
+	denied, approvalPolicy := p.authorizeToolCall(ctx, session, mapping, vt.Name, args)
+	if denied != nil {
+		return denied, nil
+	}
+	if raw, err := json.Marshal(args); err == nil {
+		if verr := jsonschema.Validate(vt.InputSchema, raw); verr != nil {
+			return mcp.NewToolCallError(fmt.Sprintf("Invalid arguments for %s: %v", vt.Name, verr)), nil
+		}
+	}
+	if approvalPolicy != nil {
+		if rejected := p.awaitApproval(ctx, session, approvalPolicy, mapping, vt.Name, args); rejected != nil {
+			p.emitActivity(ctx, start, session, "tools/call", virtualToolOwner, vt.Name, "error")
+			return rejected, nil
+		}
+	}
+
+	steps := make(map[string]interface{}, len(vt.Steps))
+	data := map[string]interface{}{"input": args, "steps": steps}
+
+	var last *mcp.ToolCallResult
+	for _, step := range vt.Steps {
+		result, err := p.runWorkflowStep(ctx, session, step, data)
+		steps[step.ID] = stepOutput(result, err)
+
+		if err != nil || result == nil || result.IsError {
+			msg := stepErrorText(result, err)
+			log.Warn().
+				Str("tool", vt.Name).
+				Str("step", step.ID).
+				Str("step_tool", step.Tool).
+				Str("error", msg).
+				Msg("Virtual tool step failed")
+			if step.OnError != "continue" {
+				p.emitActivity(ctx, start, session, "tools/call", virtualToolOwner, vt.Name, "error")
+				return mcp.NewToolCallError(fmt.Sprintf("Step %s (%s) failed: %s", step.ID, step.Tool, msg)), nil
+			}
+			continue
+		}
+		last = result
+	}
+
+	p.emitActivity(ctx, start, session, "tools/call", virtualToolOwner, vt.Name, "ok")
+
+	if len(vt.Output) == 0 {
+		if last == nil {
+			return mcp.NewToolCallError(fmt.Sprintf("Every step of %s failed", vt.Name)), nil
+		}
+		return last, nil
+	}
+	return workflowOutput(vt.Output, data)
+}
+
+// runWorkflowStep resolves a step's arguments and calls its tool
+func (p *Proxy) runWorkflowStep(ctx context.Context, session *Session, step database.WorkflowStep, data map[string]interface{}) (*mcp.ToolCallResult, error) {
+	resolved, err := workflow.Resolve(map[string]interface{}(step.Arguments), data)
+	if err != nil {
+		return nil, err
+	}
+	args, _ := resolved.(map[string]interface{})
+	return p.CallTool(ctx, session, &mcp.ToolCallParams{Name: step.Tool, Arguments: args})
+}
+
+// stepOutput is what later steps see under $.steps.<id>: "result" is the
+// structured content, or the text content parsed as JSON when possible; "text"
+// is the concatenated text content; "isError" reports failure.
+func stepOutput(result *mcp.ToolCallResult, err error) map[string]interface{} {
+	if err != nil || result == nil {
+		return map[string]interface{}{"isError": true, "text": stepErrorText(result, err)}
+	}
+
+	text := resultText(result)
+	output := map[string]interface{}{"isError": result.IsError, "text": text}
+
+	var value interface{}
+	if len(result.StructuredContent) > 0 && json.Unmarshal(result.StructuredContent, &value) == nil {
+		output["result"] = value
+	} else if json.Unmarshal([]byte(text), &value) == nil {
+		output["result"] = value
+	} else {
+		output["result"] = text
+	}
+	return output
+}
+
+// resultText concatenates the text content blocks of a tool result
+func resultText(result *mcp.ToolCallResult) string {
+	var blocks []mcp.Content
+	if err := json.Unmarshal(result.Content, &blocks); err != nil {
+		return ""
+	}
+	var parts []string
+	for _, block := range blocks {
+		if block.Type == "text" {
+			parts = append(parts, block.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+func stepErrorText(result *mcp.ToolCallResult, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	if result == nil {
+		return "no result"
+	}
+	return resultText(result)
+}
+
+// workflowOutput builds the virtual tool's result from its output template, as
+// structured content with a JSON text block for clients that only read text
+func workflowOutput(template json.RawMessage, data map[string]interface{}) (*mcp.ToolCallResult, error) {
+	var t interface{}
+	if err := json.Unmarshal(template, &t); err != nil {
+		return nil, fmt.Errorf("invalid output template: %w", err)
+	}
+	value, err := workflow.Resolve(t, data)
+	if err != nil {
+		return mcp.NewToolCallError(fmt.Sprintf("Failed to build result: %v", err)), nil
+	}
+
+	structured, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	content, _ := json.Marshal([]mcp.Content{{Type: "text", Text: string(structured)}})
+
+	result := &mcp.ToolCallResult{Content: content}
+	// structuredContent must be an object
+	if _, ok := value.(map[string]interface{}); ok {
+		result.StructuredContent = structured
+	}
+	return result, nil
+}
@@ -0,0 +1,384 @@
+// Package jsonschema validates JSON documents against the subset of JSON Schema
+// that MCP servers use for tool input and output schemas.
+//
+// Supported keywords: type, enum, const, properties, required,
+// additionalProperties, patternProperties, items, minItems, maxItems, minLength, maxLength,
+// pattern, minimum, maximum, exclusiveMinimum, exclusiveMaximum, allOf, anyOf,
+// oneOf and not. Unknown keywords (including $ref and format) are ignored, so a
+// schema using them is validated leniently rather than rejected.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidationError lists every way a document violates its schema
+type ValidationError struct {
+	Violations []string
+}
+
+func (e *ValidationError) Error() string {
+	return strings.Join(e.Violations, "; ")
+}
+
+// Validate checks a JSON document against a JSON Schema. It returns nil when the
+// document conforms, a *ValidationError when it does not, and a plain error when
+// either input is not valid JSON.
+func Validate(schema, document json.RawMessage) error {
+	if len(bytes.TrimSpace(schema)) == 0 {
+		return nil
+	}
+
+	var s interface{}
+	if err := decode(schema, &s); err != nil {
+		return fmt.Errorf("invalid schema: %w", err)
+	}
+	var doc interface{}
+	if err := decode(document, &doc); err != nil {
+		return fmt.Errorf("invalid document: %w", err)
+	}
+
+	v := &validator{}
+	v.validate("$", s, doc)
+	if len(v.violations) > 0 {
+		return &ValidationError{Violations: v.violations}
+	}
+	return nil
+}
+
+// decode unmarshals keeping numbers exact so integers can be told apart from floats
+func decode(data []byte, out *interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(out)
+}
+
+type validator struct {
+	violations []string
+}
+
+func (v *validator) fail(path, format string, args ...interface{}) {
+	v.violations = append(v.violations, path+": "+fmt.Sprintf(format, args...))
+}
+
+// matches reports whether value satisfies schema without recording violations
+func matches(schema, value interface{}) bool {
+	sub := &validator{}
+	sub.validate("$", schema, value)
+	return len(sub.violations) == 0
+}
+
+func (v *validator) validate(path string, schema, value interface{}) {
+	switch s := schema.(type) {
+	case bool:
+		if !s {
+			v.fail(path, "no value is allowed")
+		}
+		return
+	case map[string]interface{}:
+		v.validateObjectSchema(path, s, value)
+	}
+}
+
+func (v *validator) validateObjectSchema(path string, s map[string]interface{}, value interface{}) {
+	if t, ok := s["type"]; ok && !matchesType(t, value) {
+		v.fail(path, "expected %s, got %s", describeType(t), typeOf(value))
+		return
+	}
+
+	if enum, ok := s["enum"].([]interface{}); ok {
+		found := false
+		for _, candidate := range enum {
+			if equal(candidate, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.fail(path, "value is not one of the allowed values")
+		}
+	}
+	if c, ok := s["const"]; ok && !equal(c, value) {
+		v.fail(path, "value does not match the required constant")
+	}
+
+	switch val := value.(type) {
+	case map[string]interface{}:
+		v.validateObject(path, s, val)
+	case []interface{}:
+		v.validateArray(path, s, val)
+	case string:
+		v.validateString(path, s, val)
+	case json.Number:
+		v.validateNumber(path, s, val)
+	}
+
+	if all, ok := s["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			v.validate(path, sub, value)
+		}
+	}
+	if anyOf, ok := s["anyOf"].([]interface{}); ok {
+		matched := false
+		for _, sub := range anyOf {
+			if matches(sub, value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.fail(path, "value does not match any of the allowed schemas")
+		}
+	}
+	if oneOf, ok := s["oneOf"].([]interface{}); ok {
+		count := 0
+		for _, sub := range oneOf {
+			if matches(sub, value) {
+				count++
+			}
+		}
+		if count != 1 {
+			v.fail(path, "value must match exactly one schema, matched %d", count)
+		}
+	}
+	if not, ok := s["not"]; ok && matches(not, value) {
+		v.fail(path, "value matches a disallowed schema")
+	}
+}
+
+func (v *validator) validateObject(path string, s map[string]interface{}, obj map[string]interface{}) {
+	if required, ok := s["required"].([]interface{}); ok {
+		for _, r := range required {
+			name, _ := r.(string)
+			if _, present := obj[name]; !present {
+				v.fail(path, "missing required property %q", name)
+			}
+		}
+	}
+
+	properties, _ := s["properties"].(map[string]interface{})
+	patterns, patternsOK := compilePatternProperties(s["patternProperties"])
+
+	// Visit properties in a stable order so error messages are deterministic
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		propPath := path + "." + name
+		propSchema, named := properties[name]
+		if named {
+			v.validate(propPath, propSchema, obj[name])
+		}
+		// A property matching a pattern is checked against every such pattern's schema
+		matched := false
+		for _, p := range patterns {
+			if p.re.MatchString(name) {
+				v.validate(propPath, p.schema, obj[name])
+				matched = true
+			}
+		}
+		if named || matched || !patternsOK {
+			// Without every pattern no property can be called additional
+			continue
+		}
+		switch additional := s["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				v.fail(propPath, "property is not allowed")
+			}
+		case map[string]interface{}:
+			v.validate(propPath, additional, obj[name])
+		}
+	}
+}
+
+type patternProperty struct {
+	re     *regexp.Regexp
+	schema interface{}
+}
+
+// compilePatternProperties compiles the "patternProperties" keyword in a stable
+// order. It reports false if a pattern does not compile; that pattern's schema
+// is skipped, like other unreadable keywords.
+func compilePatternProperties(raw interface{}) ([]patternProperty, bool) {
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, true
+	}
+	sources := make([]string, 0, len(m))
+	for source := range m {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	patterns := make([]patternProperty, 0, len(sources))
+	compiled := true
+	for _, source := range sources {
+		re, err := regexp.Compile(source)
+		if err != nil {
+			compiled = false
+			continue
+		}
+		patterns = append(patterns, patternProperty{re: re, schema: m[source]})
+	}
+	return patterns, compiled
+}
+
+func (v *validator) validateArray(path string, s map[string]interface{}, arr []interface{}) {
+	if min, ok := number(s["minItems"]); ok && float64(len(arr)) < min {
+		v.fail(path, "expected at least %v items, got %d", min, len(arr))
+	}
+	if max, ok := number(s["maxItems"]); ok && float64(len(arr)) > max {
+		v.fail(path, "expected at most %v items, got %d", max, len(arr))
+	}
+	if items, ok := s["items"]; ok {
+		for i, item := range arr {
+			v.validate(fmt.Sprintf("%s[%d]", path, i), items, item)
+		}
+	}
+}
+
+func (v *validator) validateString(path string, s map[string]interface{}, str string) {
+	length := float64(utf8.RuneCountInString(str))
+	if min, ok := number(s["minLength"]); ok && length < min {
+		v.fail(path, "expected at least %v characters", min)
+	}
+	if max, ok := number(s["maxLength"]); ok && length > max {
+		v.fail(path, "expected at most %v characters", max)
+	}
+	if pattern, ok := s["pattern"].(string); ok {
+		re, err := regexp.Compile(pattern)
+		if err == nil && !re.MatchString(str) {
+			v.fail(path, "value does not match pattern %q", pattern)
+		}
+	}
+}
+
+func (v *validator) validateNumber(path string, s map[string]interface{}, n json.Number) {
+	f, err := n.Float64()
+	if err != nil {
+		return
+	}
+	if min, ok := number(s["minimum"]); ok && f < min {
+		v.fail(path, "value must be >= %v", min)
+	}
+	if max, ok := number(s["maximum"]); ok && f > max {
+		v.fail(path, "value must be <= %v", max)
+	}
+	if min, ok := number(s["exclusiveMinimum"]); ok && f <= min {
+		v.fail(path, "value must be > %v", min)
+	}
+	if max, ok := number(s["exclusiveMaximum"]); ok && f >= max {
+		v.fail(path, "value must be < %v", max)
+	}
+}
+
+// matchesType checks the "type" keyword, which is a type name or a list of them
+func matchesType(t interface{}, value interface{}) bool {
+	switch tt := t.(type) {
+	case string:
+		return isType(tt, value)
+	case []interface{}:
+		for _, name := range tt {
+			if s, ok := name.(string); ok && isType(s, value) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func isType(name string, value interface{}) bool {
+	switch name {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		f, err := n.Float64()
+		return err == nil && f == math.Trunc(f)
+	}
+	return true
+}
+
+func describeType(t interface{}) string {
+	if list, ok := t.([]interface{}); ok {
+		names := make([]string, 0, len(list))
+		for _, name := range list {
+			names = append(names, fmt.Sprint(name))
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(t)
+}
+
+func typeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	}
+	return "unknown"
+}
+
+func number(v interface{}) (float64, bool) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	f, err := n.Float64()
+	return f, err == nil
+}
+
+// equal compares two decoded JSON values structurally
+func equal(a, b interface{}) bool {
+	if na, ok := a.(json.Number); ok {
+		nb, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		fa, errA := na.Float64()
+		fb, errB := nb.Float64()
+		return errA == nil && errB == nil && fa == fb
+	}
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
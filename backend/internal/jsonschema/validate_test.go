@@ -0,0 +1,268 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const issueSchema = `{
+	"type": "object",
+	"required": ["title", "repo"],
+	"additionalProperties": false,
+	"properties": {
+		"title": {"type": "string", "minLength": 1, "maxLength": 20},
+		"repo": {
+			"type": "object",
+			"required": ["owner", "name"],
+			"properties": {
+				"owner": {"type": "string", "pattern": "^[a-z]+$"},
+				"name": {"type": "string"}
+			}
+		},
+		"priority": {"enum": ["low", "high", 3]},
+		"labels": {"type": "array", "maxItems": 2, "items": {"type": "string"}},
+		"assignees": {
+			"type": "array",
+			"items": {
+				"type": "object",
+				"required": ["login"],
+				"properties": {"login": {"type": "string"}, "weight": {"type": "integer", "minimum": 1}}
+			}
+		},
+		"estimate": {"type": ["number", "null"], "exclusiveMinimum": 0}
+	}
+}`
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		schema     string
+		document   string
+		violations []string // nil = valid
+	}{
+		{
+			name:     "valid",
+			schema:   issueSchema,
+			document: `{"title": "Bug", "repo": {"owner": "acme", "name": "api"}, "priority": "high", "labels": ["bug"], "assignees": [{"login": "dev", "weight": 2}], "estimate": 1.5}`,
+		},
+		{
+			name:     "null in type list",
+			schema:   issueSchema,
+			document: `{"title": "Bug", "repo": {"owner": "acme", "name": "api"}, "estimate": null}`,
+		},
+		{
+			name:       "missing required",
+			schema:     issueSchema,
+			document:   `{}`,
+			violations: []string{`$: missing required property "title"`, `$: missing required property "repo"`},
+		},
+		{
+			name:       "missing nested required",
+			schema:     issueSchema,
+			document:   `{"title": "Bug", "repo": {"owner": "acme"}}`,
+			violations: []string{`$.repo: missing required property "name"`},
+		},
+		{
+			name:       "wrong root type",
+			schema:     issueSchema,
+			document:   `["Bug"]`,
+			violations: []string{`$: expected object, got array`},
+		},
+		{
+			name:       "wrong property type",
+			schema:     issueSchema,
+			document:   `{"title": 7, "repo": {"owner": "acme", "name": "api"}}`,
+			violations: []string{`$.title: expected string, got number`},
+		},
+		{
+			name:       "wrong nested type",
+			schema:     issueSchema,
+			document:   `{"title": "Bug", "repo": {"owner": "acme", "name": false}}`,
+			violations: []string{`$.repo.name: expected string, got boolean`},
+		},
+		{
+			name:       "type list",
+			schema:     issueSchema,
+			document:   `{"title": "Bug", "repo": {"owner": "acme", "name": "api"}, "estimate": "1"}`,
+			violations: []string{`$.estimate: expected number or null, got string`},
+		},
+		{
+			name:       "enum",
+			schema:     issueSchema,
+			document:   `{"title": "Bug", "repo": {"owner": "acme", "name": "api"}, "priority": "urgent"}`,
+			violations: []string{`$.priority: value is not one of the allowed values`},
+		},
+		{
+			name:     "enum number",
+			schema:   issueSchema,
+			document: `{"title": "Bug", "repo": {"owner": "acme", "name": "api"}, "priority": 3.0}`,
+		},
+		{
+			name:       "array item",
+			schema:     issueSchema,
+			document:   `{"title": "Bug", "repo": {"owner": "acme", "name": "api"}, "labels": ["bug", 2]}`,
+			violations: []string{`$.labels[1]: expected string, got number`},
+		},
+		{
+			name:       "array length",
+			schema:     issueSchema,
+			document:   `{"title": "Bug", "repo": {"owner": "acme", "name": "api"}, "labels": ["a", "b", "c"]}`,
+			violations: []string{`$.labels: expected at most 2 items, got 3`},
+		},
+		{
+			name:     "objects in array",
+			schema:   issueSchema,
+			document: `{"title": "Bug", "repo": {"owner": "acme", "name": "api"}, "assignees": [{"login": "a"}, {"weight": 1.5}, {"login": "c", "weight": 0}]}`,
+			violations: []string{
+				`$.assignees[1]: missing required property "login"`,
+				`$.assignees[1].weight: expected integer, got number`,
+				`$.assignees[2].weight: value must be >= 1`,
+			},
+		},
+		{
+			name:       "additional property",
+			schema:     issueSchema,
+			document:   `{"title": "Bug", "repo": {"owner": "acme", "name": "api"}, "body": "text"}`,
+			violations: []string{`$.body: property is not allowed`},
+		},
+		{
+			name:     "string constraints",
+			schema:   issueSchema,
+			document: `{"title": "", "repo": {"owner": "Acme", "name": "api"}}`,
+			violations: []string{
+				`$.repo.owner: value does not match pattern "^[a-z]+$"`,
+				`$.title: expected at least 1 characters`,
+			},
+		},
+		{
+			name:       "exclusive minimum",
+			schema:     issueSchema,
+			document:   `{"title": "Bug", "repo": {"owner": "acme", "name": "api"}, "estimate": 0}`,
+			violations: []string{`$.estimate: value must be > 0`},
+		},
+		{
+			name:     "every violation is reported",
+			schema:   issueSchema,
+			document: `{"repo": {"owner": 1, "name": "api"}, "labels": [true], "priority": "none"}`,
+			violations: []string{
+				`$: missing required property "title"`,
+				`$.labels[0]: expected string, got boolean`,
+				`$.priority: value is not one of the allowed values`,
+				`$.repo.owner: expected string, got number`,
+			},
+		},
+		{
+			name:       "typed additional properties",
+			schema:     `{"type": "object", "additionalProperties": {"type": "integer"}}`,
+			document:   `{"a": 1, "b": "2"}`,
+			violations: []string{`$.b: expected integer, got string`},
+		},
+		{
+			name:     "pattern properties",
+			schema:   `{"type": "object", "properties": {"name": {"type": "string"}}, "patternProperties": {"^x-": {"type": "string"}, "^x-team": {"minLength": 2}, "^n_": {"type": "integer"}}, "additionalProperties": false}`,
+			document: `{"name": "api", "x-team": "platform", "n_replicas": 3}`,
+		},
+		{
+			name:     "pattern property violations",
+			schema:   `{"type": "object", "patternProperties": {"^x-": {"type": "string"}, "^x-team": {"minLength": 2}, "^n_": {"type": "integer"}}, "additionalProperties": false}`,
+			document: `{"n_replicas": "3", "x-team": "a", "x-owner": 1, "other": true}`,
+			violations: []string{
+				`$.n_replicas: expected integer, got string`,
+				`$.other: property is not allowed`,
+				`$.x-owner: expected string, got number`,
+				`$.x-team: expected at least 2 characters`,
+			},
+		},
+		{
+			name:     "invalid pattern disables additionalProperties",
+			schema:   `{"type": "object", "patternProperties": {"(": {"type": "string"}}, "additionalProperties": false}`,
+			document: `{"anything": 1}`,
+		},
+		{
+			name:       "const",
+			schema:     `{"properties": {"version": {"const": 2}}}`,
+			document:   `{"version": 1}`,
+			violations: []string{`$.version: value does not match the required constant`},
+		},
+		{
+			name:       "anyOf",
+			schema:     `{"anyOf": [{"type": "string"}, {"type": "integer"}]}`,
+			document:   `1.5`,
+			violations: []string{`$: value does not match any of the allowed schemas`},
+		},
+		{
+			name:       "oneOf",
+			schema:     `{"oneOf": [{"type": "number"}, {"type": "integer"}]}`,
+			document:   `1`,
+			violations: []string{`$: value must match exactly one schema, matched 2`},
+		},
+		{
+			name:       "not",
+			schema:     `{"not": {"type": "null"}}`,
+			document:   `null`,
+			violations: []string{`$: value matches a disallowed schema`},
+		},
+		{
+			name:       "false schema",
+			schema:     `{"properties": {"secret": false}}`,
+			document:   `{"secret": 1}`,
+			violations: []string{`$.secret: no value is allowed`},
+		},
+		{
+			name:     "unknown keywords are ignored",
+			schema:   `{"$ref": "#/definitions/x", "properties": {"at": {"type": "string", "format": "date-time"}}}`,
+			document: `{"at": "yesterday"}`,
+		},
+		{
+			name:     "empty schema",
+			schema:   ``,
+			document: `{"anything": true}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(json.RawMessage(tt.schema), json.RawMessage(tt.document))
+			if tt.violations == nil {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Validate() = %v, want a *ValidationError", err)
+			}
+			if !reflect.DeepEqual(verr.Violations, tt.violations) {
+				t.Errorf("violations = %q, want %q", verr.Violations, tt.violations)
+			}
+		})
+	}
+}
+
+func TestValidateInvalidJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		document string
+	}{
+		{"invalid schema", `{"type":`, `{}`},
+		{"invalid document", `{"type": "object"}`, `{"a":`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(json.RawMessage(tt.schema), json.RawMessage(tt.document))
+			if err == nil {
+				t.Fatal("Validate() = nil, want an error")
+			}
+			var verr *ValidationError
+			if errors.As(err, &verr) {
+				t.Errorf("Validate() = %v, want a plain error, not a *ValidationError", err)
+			}
+		})
+	}
+}
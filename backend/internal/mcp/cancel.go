@@ -0,0 +1,42 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// cancelNotifyTimeout bounds the best-effort notifications/cancelled sent upstream
+const cancelNotifyTimeout = 5 * time.Second
+
+// CancelledParams represents parameters for notifications/cancelled
+type CancelledParams struct {
+	RequestID json.RawMessage `json:"requestId"`
+	Reason    string          `json:"reason,omitempty"`
+}
+
+// NewCancelledNotification creates a notifications/cancelled for the given request ID
+func NewCancelledNotification(requestID json.RawMessage, reason string) *JSONRPCNotification {
+	params, _ := json.Marshal(CancelledParams{RequestID: requestID, Reason: reason})
+	return &JSONRPCNotification{
+		JSONRPC: JSONRPCVersion,
+		Method:  MethodNotificationCancelled,
+		Params:  params,
+	}
+}
+
+// CancelReason describes why a request context ended, preferring an explicit cause
+func CancelReason(ctx context.Context) string {
+	cause := context.Cause(ctx)
+	switch {
+	case cause == nil:
+		return ""
+	case errors.Is(cause, context.DeadlineExceeded):
+		return "Request timed out"
+	case errors.Is(cause, context.Canceled):
+		return "Request cancelled"
+	default:
+		return cause.Error()
+	}
+}
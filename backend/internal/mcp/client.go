@@ -12,6 +12,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/gorilla/websocket"
 	"github.com/reflow/gateway/internal/telemetry"
 	"github.com/rs/zerolog/log"
 	"go.opentelemetry.io/otel"
@@ -29,10 +30,12 @@ type TransportType string
 const (
 	TransportStreamableHTTP TransportType = "streamable-http"
 	TransportSSE            TransportType = "sse"
+	TransportWebSocket      TransportType = "websocket"
 )
 
 // Client represents an MCP client that connects to upstream servers.
-// Supports both Streamable HTTP and legacy SSE transports, with auto-detection.
+// Supports Streamable HTTP and legacy SSE transports, with auto-detection, and
+// WebSocket when configured explicitly.
 type Client struct {
 	httpClient    *http.Client
 	url           string
@@ -41,17 +44,32 @@ type Client struct {
 	customHeaders map[string]string
 	transportType TransportType
 
-	mu           sync.RWMutex
-	sessionID    string
-	initialized  bool
-	capabilities *ServerCapabilities
-	serverInfo   *ServerInfo
+	mu              sync.RWMutex
+	sessionID       string
+	initialized     bool
+	capabilities    *ServerCapabilities
+	serverInfo      *ServerInfo
+	protocolVersion string // revision negotiated with the server
 
 	// SSE transport state
 	messageEndpoint string
 	sseResponses    map[string]chan *JSONRPCResponse
 	sseCancel       context.CancelFunc
-	sseDone         chan struct{} // closed when SSE reader exits
+	sseDone         chan struct{} // closed when the SSE or WebSocket reader exits
+
+	// WebSocket transport state
+	wsConn    *websocket.Conn
+	wsWriteMu sync.Mutex // gorilla allows one concurrent writer
+
+	// Streamable HTTP notification stream (GET), cancelled on Close
+	streamCancel context.CancelFunc
+
+	// Server-to-client notifications and requests
+	NotificationDispatcher
+	RequestDispatcher
+
+	// Resource subscriptions held on behalf of gateway sessions
+	SubscriptionSet
 
 	// Request ID counter
 	nextID int64
@@ -122,6 +140,14 @@ func (c *Client) Initialize(ctx context.Context, params *InitializeParams) (*Ini
 		return c.doInitialize(ctx, params)
 	}
 
+	// WebSocket is never auto-detected; it must be configured
+	if c.transportType == TransportWebSocket {
+		if err := c.connectWebSocket(ctx); err != nil {
+			return nil, fmt.Errorf("WebSocket connect failed: %w", err)
+		}
+		return c.doInitialize(ctx, params)
+	}
+
 	// Try Streamable HTTP first
 	result, err := c.doInitialize(ctx, params)
 	if err == nil {
@@ -188,18 +214,22 @@ func (c *Client) doInitialize(ctx context.Context, params *InitializeParams) (*I
 	}
 
 	if resp.Error != nil {
-		return nil, fmt.Errorf("initialize error: %s (code: %d)", resp.Error.Message, resp.Error.Code)
+		return nil, NewUpstreamError("initialize", resp.Error)
 	}
 
 	var result InitializeResult
 	if err := json.Unmarshal(resp.Result, &result); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal initialize result: %w", err)
 	}
+	if !IsSupportedProtocolVersion(result.ProtocolVersion) {
+		return nil, fmt.Errorf("server negotiated unsupported protocol version %q", result.ProtocolVersion)
+	}
 
 	c.mu.Lock()
 	c.initialized = true
 	c.capabilities = &result.Capabilities
 	c.serverInfo = &result.ServerInfo
+	c.protocolVersion = result.ProtocolVersion
 	c.mu.Unlock()
 
 	// Send initialized notification
@@ -211,9 +241,74 @@ func (c *Client) doInitialize(ctx context.Context, params *InitializeParams) (*I
 		log.Warn().Err(err).Msg("Failed to send initialized notification")
 	}
 
+	// Streamable HTTP servers push notifications over a separate GET stream;
+	// legacy SSE and WebSocket servers already use the connection opened earlier.
+	c.mu.RLock()
+	isStreamableHTTP := c.transportType == TransportStreamableHTTP
+	c.mu.RUnlock()
+	if isStreamableHTTP {
+		c.openNotificationStream()
+	}
+
 	return &result, nil
 }
 
+// openNotificationStream opens the optional Streamable HTTP GET stream used by
+// the server for notifications. Servers that do not offer one answer 405.
+func (c *Client) openNotificationStream() {
+	streamCtx, cancel := context.WithCancel(context.Background())
+
+	httpReq, err := http.NewRequestWithContext(streamCtx, http.MethodGet, c.url, nil)
+	if err != nil {
+		cancel()
+		return
+	}
+	httpReq.Header.Set("Accept", "text/event-stream")
+	httpReq.Header.Set("MCP-Protocol-Version", c.ProtocolVersion())
+	c.mu.RLock()
+	if c.sessionID != "" {
+		httpReq.Header.Set("Mcp-Session-Id", c.sessionID)
+	}
+	c.mu.RUnlock()
+	c.applyAuthHeaders(httpReq)
+	c.applyCustomHeaders(httpReq)
+
+	// Use a client without timeout for the long-lived stream
+	resp, err := (&http.Client{}).Do(httpReq)
+	if err != nil {
+		cancel()
+		log.Debug().Err(err).Str("url", c.url).Msg("Upstream notification stream unavailable")
+		return
+	}
+	if resp.StatusCode != http.StatusOK || !strings.Contains(resp.Header.Get("Content-Type"), "text/event-stream") {
+		resp.Body.Close()
+		cancel()
+		log.Debug().Int("status", resp.StatusCode).Str("url", c.url).Msg("Upstream does not offer a notification stream")
+		return
+	}
+
+	c.mu.Lock()
+	c.streamCancel = cancel
+	c.mu.Unlock()
+
+	go func() {
+		defer resp.Body.Close()
+		reader := NewSSEReader(resp.Body)
+		for {
+			event, err := reader.ReadEvent()
+			if err != nil {
+				if err != io.EOF && streamCtx.Err() == nil {
+					log.Debug().Err(err).Str("url", c.url).Msg("Upstream notification stream ended")
+				}
+				return
+			}
+			if event.Data != "" {
+				c.handleMessage([]byte(event.Data))
+			}
+		}
+	}()
+}
+
 // connectSSE connects to the SSE endpoint, retrieves the message endpoint,
 // and starts a background goroutine to read events.
 func (c *Client) connectSSE(ctx context.Context) error {
@@ -335,23 +430,45 @@ func (c *Client) readSSEEvents(body io.ReadCloser, reader *SSEReader) {
 		}
 
 		if event.Event == "message" && event.Data != "" {
-			var resp JSONRPCResponse
-			if err := json.Unmarshal([]byte(event.Data), &resp); err != nil {
-				log.Debug().Err(err).Str("data", event.Data).Msg("Failed to parse SSE message")
-				continue
-			}
+			c.handleMessage([]byte(event.Data))
+		}
+	}
+}
 
-			if resp.ID != nil {
-				reqID := string(resp.ID)
-				c.mu.Lock()
-				if ch, ok := c.sseResponses[reqID]; ok {
-					ch <- &resp
-					delete(c.sseResponses, reqID)
-				}
-				c.mu.Unlock()
-			}
+// handleMessage classifies a message received on an SSE stream: responses are
+// routed to the waiting request channel, notifications go to the dispatcher and
+// server-initiated requests are answered asynchronously.
+func (c *Client) handleMessage(data []byte) {
+	var msg JSONRPCMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		log.Debug().Err(err).Str("data", string(data)).Msg("Failed to parse SSE message")
+		return
+	}
+
+	switch {
+	case msg.IsNotification():
+		c.handleNotification(msg.ToNotification())
+	case msg.IsRequest():
+		go c.serveRequest(msg.ToRequest())
+	case msg.IsResponse():
+		reqID := string(msg.ID)
+		c.mu.Lock()
+		if ch, ok := c.sseResponses[reqID]; ok {
+			ch <- msg.ToResponse()
+			delete(c.sseResponses, reqID)
 		}
+		c.mu.Unlock()
+	}
+}
+
+// handleNotification routes an upstream notification. Cancellations of requests the
+// server sent us are handled here; everything else goes to the subscribers.
+func (c *Client) handleNotification(notification *JSONRPCNotification) {
+	if notification.Method == MethodNotificationCancelled {
+		c.CancelServerRequest(notification)
+		return
 	}
+	c.Dispatch(notification)
 }
 
 // resolveEndpoint resolves a potentially relative endpoint URL to an absolute URL
@@ -375,16 +492,42 @@ func (c *Client) resolveEndpoint(endpoint string) string {
 	return base.ResolveReference(ref).String()
 }
 
-// sendRequest sends a JSON-RPC request and returns the response.
+// sendRequest sends a JSON-RPC request and returns the response. If ctx ends
+// before the response arrives, the server is told to stop working on the request.
+func (c *Client) sendRequest(ctx context.Context, req *JSONRPCRequest) (*JSONRPCResponse, error) {
+	resp, err := c.doSendRequest(ctx, req)
+	if err != nil && ctx.Err() != nil && req.Method != MethodInitialize {
+		c.notifyCancelled(req, CancelReason(ctx))
+	}
+	return resp, err
+}
+
+// notifyCancelled sends a best-effort notifications/cancelled for an abandoned request
+func (c *Client) notifyCancelled(req *JSONRPCRequest, reason string) {
+	ctx, cancel := context.WithTimeout(context.Background(), cancelNotifyTimeout)
+	defer cancel()
+	if err := c.sendNotification(ctx, NewCancelledNotification(req.ID, reason)); err != nil {
+		log.Debug().Err(err).Str("url", c.url).Str("method", req.Method).Msg("Failed to send cancellation upstream")
+	}
+}
+
+// doSendRequest performs the request exchange.
 // For Streamable HTTP: POST and read JSON response.
 // For SSE: POST and read response from either POST body (200) or SSE stream (202).
-func (c *Client) sendRequest(ctx context.Context, req *JSONRPCRequest) (*JSONRPCResponse, error) {
+func (c *Client) doSendRequest(ctx context.Context, req *JSONRPCRequest) (*JSONRPCResponse, error) {
 	ctx, span := mcpTracer.Start(ctx, "mcp.upstream.sendRequest",
 		trace.WithAttributes(attribute.String("mcp.method", req.Method)),
 	)
 	defer span.End()
 	reqStart := time.Now()
 
+	c.mu.RLock()
+	isWebSocket := c.transportType == TransportWebSocket
+	c.mu.RUnlock()
+	if isWebSocket {
+		return c.sendWebSocketRequest(ctx, req, reqStart)
+	}
+
 	body, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
@@ -422,7 +565,7 @@ func (c *Client) sendRequest(ctx context.Context, req *JSONRPCRequest) (*JSONRPC
 
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("Accept", "application/json, text/event-stream")
-	httpReq.Header.Set("MCP-Protocol-Version", MCPProtocolVersion)
+	httpReq.Header.Set("MCP-Protocol-Version", c.ProtocolVersion())
 
 	c.mu.RLock()
 	if c.sessionID != "" {
@@ -452,15 +595,22 @@ func (c *Client) sendRequest(ctx context.Context, req *JSONRPCRequest) (*JSONRPC
 
 	switch httpResp.StatusCode {
 	case http.StatusOK:
-		respBody, err := io.ReadAll(httpResp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read response: %w", err)
-		}
-
 		ct := httpResp.Header.Get("Content-Type")
 		if strings.Contains(ct, "text/event-stream") {
+			// Read events as they arrive: the server may send its own requests
+			// and wait for our answers before it writes the response
+			resp, err := c.readSSEResponse(httpResp.Body)
+			if err != nil {
+				c.recordUpstreamMetrics(ctx, req.Method, "error", reqStart)
+				return nil, err
+			}
 			c.recordUpstreamMetrics(ctx, req.Method, "ok", reqStart)
-			return c.parseSSEResponseBody(respBody)
+			return resp, nil
+		}
+
+		respBody, err := io.ReadAll(httpResp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
 		}
 
 		var resp JSONRPCResponse
@@ -492,7 +642,7 @@ func (c *Client) sendRequest(ctx context.Context, req *JSONRPCRequest) (*JSONRPC
 		respBody, _ := io.ReadAll(httpResp.Body)
 		span.SetStatus(codes.Error, fmt.Sprintf("upstream status %d", httpResp.StatusCode))
 		c.recordUpstreamMetrics(ctx, req.Method, "error", reqStart)
-		return nil, fmt.Errorf("upstream status %d: %s", httpResp.StatusCode, string(respBody))
+		return nil, &HTTPStatusError{StatusCode: httpResp.StatusCode, Body: string(respBody)}
 	}
 }
 
@@ -509,28 +659,59 @@ func (c *Client) recordUpstreamMetrics(ctx context.Context, method, status strin
 	)
 }
 
-// parseSSEResponseBody extracts a JSON-RPC response from an SSE-formatted response body
-func (c *Client) parseSSEResponseBody(body []byte) (*JSONRPCResponse, error) {
-	reader := NewSSEReader(bytes.NewReader(body))
+// readSSEResponse reads an SSE-formatted response stream until the JSON-RPC response arrives
+func (c *Client) readSSEResponse(body io.Reader) (*JSONRPCResponse, error) {
+	reader := NewSSEReader(body)
 	for {
 		event, err := reader.ReadEvent()
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse SSE response: %w", err)
 		}
 		if event.Data != "" {
-			var resp JSONRPCResponse
-			if err := json.Unmarshal([]byte(event.Data), &resp); err != nil {
+			var msg JSONRPCMessage
+			if err := json.Unmarshal([]byte(event.Data), &msg); err != nil {
 				continue // try next event
 			}
-			return &resp, nil
+			// Servers may interleave notifications (e.g. progress) before the response
+			if msg.IsNotification() {
+				c.handleNotification(msg.ToNotification())
+				continue
+			}
+			if msg.IsRequest() {
+				go c.serveRequest(msg.ToRequest())
+				continue
+			}
+			return msg.ToResponse(), nil
 		}
 	}
 }
 
+// serveRequest answers a server-initiated request and posts the response back upstream
+func (c *Client) serveRequest(req *JSONRPCRequest) {
+	ctx := context.Background()
+	resp := c.HandleServerRequest(ctx, req)
+	if err := c.postMessage(ctx, resp); err != nil {
+		log.Warn().Err(err).Str("url", c.url).Str("method", req.Method).Msg("Failed to answer server request")
+	}
+}
+
 func (c *Client) sendNotification(ctx context.Context, notification *JSONRPCNotification) error {
-	body, err := json.Marshal(notification)
+	return c.postMessage(ctx, notification)
+}
+
+// postMessage posts a message that expects no JSON-RPC response (a notification
+// or our answer to a server-initiated request)
+func (c *Client) postMessage(ctx context.Context, msg interface{}) error {
+	c.mu.RLock()
+	isWebSocket := c.transportType == TransportWebSocket
+	c.mu.RUnlock()
+	if isWebSocket {
+		return c.writeWebSocket(msg)
+	}
+
+	body, err := json.Marshal(msg)
 	if err != nil {
-		return fmt.Errorf("failed to marshal notification: %w", err)
+		return fmt.Errorf("failed to marshal message: %w", err)
 	}
 
 	c.mu.RLock()
@@ -546,7 +727,7 @@ func (c *Client) sendNotification(ctx context.Context, notification *JSONRPCNoti
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("MCP-Protocol-Version", MCPProtocolVersion)
+	httpReq.Header.Set("MCP-Protocol-Version", c.ProtocolVersion())
 
 	c.mu.RLock()
 	if c.sessionID != "" {
@@ -559,7 +740,7 @@ func (c *Client) sendNotification(ctx context.Context, notification *JSONRPCNoti
 
 	httpResp, err := c.httpClient.Do(httpReq)
 	if err != nil {
-		return fmt.Errorf("notification failed: %w", err)
+		return fmt.Errorf("post failed: %w", err)
 	}
 	defer httpResp.Body.Close()
 
@@ -567,7 +748,7 @@ func (c *Client) sendNotification(ctx context.Context, notification *JSONRPCNoti
 		httpResp.StatusCode != http.StatusAccepted &&
 		httpResp.StatusCode != http.StatusNoContent {
 		respBody, _ := io.ReadAll(httpResp.Body)
-		return fmt.Errorf("notification status %d: %s", httpResp.StatusCode, string(respBody))
+		return fmt.Errorf("post status %d: %s", httpResp.StatusCode, string(respBody))
 	}
 
 	return nil
@@ -593,7 +774,7 @@ func (c *Client) ListTools(ctx context.Context, cursor *string) (*ToolsListResul
 		return nil, err
 	}
 	if resp.Error != nil {
-		return nil, fmt.Errorf("tools/list error: %s (code: %d)", resp.Error.Message, resp.Error.Code)
+		return nil, NewUpstreamError("tools/list", resp.Error)
 	}
 
 	var result ToolsListResult
@@ -621,7 +802,7 @@ func (c *Client) CallTool(ctx context.Context, params *ToolCallParams) (*ToolCal
 		return nil, err
 	}
 	if resp.Error != nil {
-		return nil, fmt.Errorf("tools/call error: %s (code: %d)", resp.Error.Message, resp.Error.Code)
+		return nil, NewUpstreamError("tools/call", resp.Error)
 	}
 
 	var result ToolCallResult
@@ -649,7 +830,7 @@ func (c *Client) ListResources(ctx context.Context, cursor *string) (*ResourcesL
 		return nil, err
 	}
 	if resp.Error != nil {
-		return nil, fmt.Errorf("resources/list error: %s (code: %d)", resp.Error.Message, resp.Error.Code)
+		return nil, NewUpstreamError("resources/list", resp.Error)
 	}
 
 	var result ResourcesListResult
@@ -675,7 +856,7 @@ func (c *Client) ReadResource(ctx context.Context, uri string) (*ResourceReadRes
 		return nil, err
 	}
 	if resp.Error != nil {
-		return nil, fmt.Errorf("resources/read error: %s (code: %d)", resp.Error.Message, resp.Error.Code)
+		return nil, NewUpstreamError("resources/read", resp.Error)
 	}
 
 	var result ResourceReadResult
@@ -685,6 +866,92 @@ func (c *Client) ReadResource(ctx context.Context, uri string) (*ResourceReadRes
 	return &result, nil
 }
 
+// ListResourceTemplates retrieves the list of resource templates from the upstream server
+func (c *Client) ListResourceTemplates(ctx context.Context, cursor *string) (*ResourceTemplatesListResult, error) {
+	req := &JSONRPCRequest{
+		JSONRPC: JSONRPCVersion,
+		ID:      c.allocID(),
+		Method:  MethodResourcesTemplates,
+	}
+	if cursor != nil {
+		params := map[string]string{"cursor": *cursor}
+		paramsJSON, _ := json.Marshal(params)
+		req.Params = paramsJSON
+	}
+
+	resp, err := c.sendRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, NewUpstreamError("resources/templates/list", resp.Error)
+	}
+
+	var result ResourceTemplatesListResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal resources/templates/list result: %w", err)
+	}
+	return &result, nil
+}
+
+// SubscribeResource subscribes to updates of a resource on behalf of a subscriber.
+// Only the first subscriber for a URI causes a resources/subscribe upstream.
+func (c *Client) SubscribeResource(ctx context.Context, subscriberID, uri string) error {
+	if !c.AddSubscriber(uri, subscriberID) {
+		return nil
+	}
+	if err := c.sendSubscription(ctx, MethodResourcesSubscribe, uri); err != nil {
+		c.RemoveSubscriber(uri, subscriberID)
+		return err
+	}
+	return nil
+}
+
+// UnsubscribeResource drops a subscriber's subscription to a resource.
+// Only the last subscriber for a URI causes a resources/unsubscribe upstream.
+func (c *Client) UnsubscribeResource(ctx context.Context, subscriberID, uri string) error {
+	if !c.RemoveSubscriber(uri, subscriberID) {
+		return nil
+	}
+	return c.sendSubscription(ctx, MethodResourcesUnsubscribe, uri)
+}
+
+// ReleaseSubscriptions drops every subscription of a subscriber, unsubscribing
+// upstream in the background from URIs nobody else is subscribed to.
+func (c *Client) ReleaseSubscriptions(subscriberID string) {
+	orphaned := c.RemoveAllSubscriptions(subscriberID)
+	if len(orphaned) == 0 {
+		return
+	}
+	go func() {
+		for _, uri := range orphaned {
+			if err := c.sendSubscription(context.Background(), MethodResourcesUnsubscribe, uri); err != nil {
+				log.Debug().Err(err).Str("url", c.url).Str("uri", uri).Msg("Failed to unsubscribe resource")
+			}
+		}
+	}()
+}
+
+// sendSubscription sends resources/subscribe or resources/unsubscribe for a URI
+func (c *Client) sendSubscription(ctx context.Context, method, uri string) error {
+	req := &JSONRPCRequest{
+		JSONRPC: JSONRPCVersion,
+		ID:      c.allocID(),
+		Method:  method,
+	}
+	paramsJSON, _ := json.Marshal(ResourceSubscribeParams{URI: uri})
+	req.Params = paramsJSON
+
+	resp, err := c.sendRequest(ctx, req)
+	if err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return NewUpstreamError(method, resp.Error)
+	}
+	return nil
+}
+
 // ListPrompts retrieves the list of prompts from the upstream server
 func (c *Client) ListPrompts(ctx context.Context, cursor *string) (*PromptsListResult, error) {
 	req := &JSONRPCRequest{
@@ -703,7 +970,7 @@ func (c *Client) ListPrompts(ctx context.Context, cursor *string) (*PromptsListR
 		return nil, err
 	}
 	if resp.Error != nil {
-		return nil, fmt.Errorf("prompts/list error: %s (code: %d)", resp.Error.Message, resp.Error.Code)
+		return nil, NewUpstreamError("prompts/list", resp.Error)
 	}
 
 	var result PromptsListResult
@@ -731,7 +998,7 @@ func (c *Client) GetPrompt(ctx context.Context, params *PromptGetParams) (*Promp
 		return nil, err
 	}
 	if resp.Error != nil {
-		return nil, fmt.Errorf("prompts/get error: %s (code: %d)", resp.Error.Message, resp.Error.Code)
+		return nil, NewUpstreamError("prompts/get", resp.Error)
 	}
 
 	var result PromptGetResult
@@ -741,11 +1008,64 @@ func (c *Client) GetPrompt(ctx context.Context, params *PromptGetParams) (*Promp
 	return &result, nil
 }
 
+// Complete requests argument completions from the upstream server
+func (c *Client) Complete(ctx context.Context, params *CompleteParams) (*CompleteResult, error) {
+	req := &JSONRPCRequest{
+		JSONRPC: JSONRPCVersion,
+		ID:      c.allocID(),
+		Method:  MethodCompletionComplete,
+	}
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal params: %w", err)
+	}
+	req.Params = paramsJSON
+
+	resp, err := c.sendRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, NewUpstreamError("completion/complete", resp.Error)
+	}
+
+	var result CompleteResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal completion/complete result: %w", err)
+	}
+	return &result, nil
+}
+
+// SetLoggingLevel sets the minimum level of log notifications sent by the upstream server
+func (c *Client) SetLoggingLevel(ctx context.Context, level string) error {
+	req := &JSONRPCRequest{
+		JSONRPC: JSONRPCVersion,
+		ID:      c.allocID(),
+		Method:  MethodLoggingSetLevel,
+	}
+	paramsJSON, _ := json.Marshal(SetLevelParams{Level: level})
+	req.Params = paramsJSON
+
+	resp, err := c.sendRequest(ctx, req)
+	if err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return NewUpstreamError("logging/setLevel", resp.Error)
+	}
+	return nil
+}
+
 // SendRawRequest sends a raw JSON-RPC request to the upstream server
 func (c *Client) SendRawRequest(ctx context.Context, req *JSONRPCRequest) (*JSONRPCResponse, error) {
 	return c.sendRequest(ctx, req)
 }
 
+// SendNotification sends a client-to-server notification to the upstream server
+func (c *Client) SendNotification(ctx context.Context, notification *JSONRPCNotification) error {
+	return c.sendNotification(ctx, notification)
+}
+
 // --- Accessors ---
 
 func (c *Client) SetSessionID(sessionID string) {
@@ -778,13 +1098,31 @@ func (c *Client) GetServerInfo() *ServerInfo {
 	return c.serverInfo
 }
 
+// ProtocolVersion returns the revision negotiated with the server, or the
+// gateway's latest before initialization completes.
+func (c *Client) ProtocolVersion() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.protocolVersion == "" {
+		return MCPProtocolVersion
+	}
+	return c.protocolVersion
+}
+
 // Close closes the client and releases resources
 func (c *Client) Close() error {
 	c.mu.Lock()
 	if c.sseCancel != nil {
 		c.sseCancel()
 	}
+	if c.streamCancel != nil {
+		c.streamCancel()
+	}
+	wsConn := c.wsConn
 	c.mu.Unlock()
+	if wsConn != nil {
+		c.closeWebSocket(wsConn)
+	}
 	c.httpClient.CloseIdleConnections()
 	return nil
 }
@@ -0,0 +1,30 @@
+package mcp
+
+import "fmt"
+
+// UpstreamError is a JSON-RPC error response from an upstream server. The
+// server answered, so unlike transport errors it says nothing about whether the
+// upstream is healthy.
+type UpstreamError struct {
+	Method string
+	Err    *JSONRPCError
+}
+
+// NewUpstreamError wraps the JSON-RPC error an upstream returned for a method
+func NewUpstreamError(method string, err *JSONRPCError) *UpstreamError {
+	return &UpstreamError{Method: method, Err: err}
+}
+
+func (e *UpstreamError) Error() string {
+	return fmt.Sprintf("%s error: %s (code: %d)", e.Method, e.Err.Message, e.Err.Code)
+}
+
+// HTTPStatusError is an unexpected HTTP status from an upstream server
+type HTTPStatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("upstream status %d: %s", e.StatusCode, e.Body)
+}
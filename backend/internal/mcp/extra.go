@@ -0,0 +1,201 @@
+package mcp
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Extra holds object fields a type does not model. Keeping them lets newer
+// spec fields and vendor extensions round-trip through the gateway unchanged.
+type Extra map[string]json.RawMessage
+
+// knownFields caches the JSON field names declared by each struct type
+var knownFields sync.Map // reflect.Type -> []string
+
+// decodeWithExtra unmarshals data into v (a pointer to a struct without custom
+// unmarshaling) and returns the fields v does not declare.
+func decodeWithExtra(data []byte, v interface{}) (Extra, error) {
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	for _, name := range jsonFieldNames(reflect.TypeOf(v).Elem()) {
+		delete(all, name)
+	}
+	if len(all) == 0 {
+		return nil, nil
+	}
+	return Extra(all), nil
+}
+
+// encodeWithExtra marshals v (a struct without custom marshaling) and merges in
+// the extra fields. Declared fields win over extras with the same name.
+func encodeWithExtra(v interface{}, extra Extra) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return data, err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	for name, raw := range extra {
+		if _, declared := all[name]; !declared {
+			all[name] = raw
+		}
+	}
+	return json.Marshal(all)
+}
+
+// jsonFieldNames returns the JSON names of a struct's fields, including omitted ones
+func jsonFieldNames(t reflect.Type) []string {
+	if cached, ok := knownFields.Load(t); ok {
+		return cached.([]string)
+	}
+
+	names := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if name == "" {
+			name = field.Name
+		}
+		names = append(names, name)
+	}
+
+	knownFields.Store(t, names)
+	return names
+}
+
+// The types below carry Extra so fields from newer protocol revisions survive a
+// decode/encode round trip. Each marshals through a plain alias to avoid recursion.
+
+func (t *Tool) UnmarshalJSON(data []byte) error {
+	type plain Tool
+	var v plain
+	extra, err := decodeWithExtra(data, &v)
+	if err != nil {
+		return err
+	}
+	*t = Tool(v)
+	t.Extra = extra
+	return nil
+}
+
+func (t Tool) MarshalJSON() ([]byte, error) {
+	type plain Tool
+	return encodeWithExtra(plain(t), t.Extra)
+}
+
+func (r *ToolCallResult) UnmarshalJSON(data []byte) error {
+	type plain ToolCallResult
+	var v plain
+	extra, err := decodeWithExtra(data, &v)
+	if err != nil {
+		return err
+	}
+	*r = ToolCallResult(v)
+	r.Extra = extra
+	return nil
+}
+
+func (r ToolCallResult) MarshalJSON() ([]byte, error) {
+	type plain ToolCallResult
+	return encodeWithExtra(plain(r), r.Extra)
+}
+
+func (r *Resource) UnmarshalJSON(data []byte) error {
+	type plain Resource
+	var v plain
+	extra, err := decodeWithExtra(data, &v)
+	if err != nil {
+		return err
+	}
+	*r = Resource(v)
+	r.Extra = extra
+	return nil
+}
+
+func (r Resource) MarshalJSON() ([]byte, error) {
+	type plain Resource
+	return encodeWithExtra(plain(r), r.Extra)
+}
+
+func (r *ResourceReadResult) UnmarshalJSON(data []byte) error {
+	type plain ResourceReadResult
+	var v plain
+	extra, err := decodeWithExtra(data, &v)
+	if err != nil {
+		return err
+	}
+	*r = ResourceReadResult(v)
+	r.Extra = extra
+	return nil
+}
+
+func (r ResourceReadResult) MarshalJSON() ([]byte, error) {
+	type plain ResourceReadResult
+	return encodeWithExtra(plain(r), r.Extra)
+}
+
+func (t *ResourceTemplate) UnmarshalJSON(data []byte) error {
+	type plain ResourceTemplate
+	var v plain
+	extra, err := decodeWithExtra(data, &v)
+	if err != nil {
+		return err
+	}
+	*t = ResourceTemplate(v)
+	t.Extra = extra
+	return nil
+}
+
+func (t ResourceTemplate) MarshalJSON() ([]byte, error) {
+	type plain ResourceTemplate
+	return encodeWithExtra(plain(t), t.Extra)
+}
+
+func (p *Prompt) UnmarshalJSON(data []byte) error {
+	type plain Prompt
+	var v plain
+	extra, err := decodeWithExtra(data, &v)
+	if err != nil {
+		return err
+	}
+	*p = Prompt(v)
+	p.Extra = extra
+	return nil
+}
+
+func (p Prompt) MarshalJSON() ([]byte, error) {
+	type plain Prompt
+	return encodeWithExtra(plain(p), p.Extra)
+}
+
+func (r *PromptGetResult) UnmarshalJSON(data []byte) error {
+	type plain PromptGetResult
+	var v plain
+	extra, err := decodeWithExtra(data, &v)
+	if err != nil {
+		return err
+	}
+	*r = PromptGetResult(v)
+	r.Extra = extra
+	return nil
+}
+
+func (r PromptGetResult) MarshalJSON() ([]byte, error) {
+	type plain PromptGetResult
+	return encodeWithExtra(plain(r), r.Extra)
+}
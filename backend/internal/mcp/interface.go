@@ -10,11 +10,23 @@ type MCPClient interface {
 	CallTool(ctx context.Context, params *ToolCallParams) (*ToolCallResult, error)
 	ListResources(ctx context.Context, cursor *string) (*ResourcesListResult, error)
 	ReadResource(ctx context.Context, uri string) (*ResourceReadResult, error)
+	ListResourceTemplates(ctx context.Context, cursor *string) (*ResourceTemplatesListResult, error)
+	SubscribeResource(ctx context.Context, subscriberID, uri string) error
+	UnsubscribeResource(ctx context.Context, subscriberID, uri string) error
+	ReleaseSubscriptions(subscriberID string)
 	ListPrompts(ctx context.Context, cursor *string) (*PromptsListResult, error)
 	GetPrompt(ctx context.Context, params *PromptGetParams) (*PromptGetResult, error)
+	Complete(ctx context.Context, params *CompleteParams) (*CompleteResult, error)
+	SetLoggingLevel(ctx context.Context, level string) error
 	SendRawRequest(ctx context.Context, req *JSONRPCRequest) (*JSONRPCResponse, error)
 	IsInitialized() bool
 	GetCapabilities() *ServerCapabilities
 	GetServerInfo() *ServerInfo
+	ProtocolVersion() string
+	OnNotification(subscriberID string, handler NotificationHandler)
+	RemoveNotificationHandler(subscriberID string)
+	OnRequest(subscriberID string, handler RequestHandler)
+	RemoveRequestHandler(subscriberID string)
+	SendNotification(ctx context.Context, notification *JSONRPCNotification) error
 	Close() error
 }
@@ -0,0 +1,47 @@
+package mcp
+
+import (
+	"sync"
+)
+
+// NotificationHandler receives notifications sent by an upstream server.
+type NotificationHandler func(notification *JSONRPCNotification)
+
+// NotificationDispatcher fans upstream notifications out to registered handlers.
+// A single upstream connection can be shared by several gateway sessions
+// (e.g. a shared STDIO process), so handlers are keyed by subscriber ID.
+type NotificationDispatcher struct {
+	mu       sync.RWMutex
+	handlers map[string]NotificationHandler
+}
+
+// OnNotification registers a handler for the given subscriber, replacing any previous one.
+func (d *NotificationDispatcher) OnNotification(subscriberID string, handler NotificationHandler) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.handlers == nil {
+		d.handlers = make(map[string]NotificationHandler)
+	}
+	d.handlers[subscriberID] = handler
+}
+
+// RemoveNotificationHandler unregisters the handler for the given subscriber.
+func (d *NotificationDispatcher) RemoveNotificationHandler(subscriberID string) {
+	d.mu.Lock()
+	delete(d.handlers, subscriberID)
+	d.mu.Unlock()
+}
+
+// Dispatch delivers a notification to every registered handler.
+func (d *NotificationDispatcher) Dispatch(notification *JSONRPCNotification) {
+	d.mu.RLock()
+	handlers := make([]NotificationHandler, 0, len(d.handlers))
+	for _, h := range d.handlers {
+		handlers = append(handlers, h)
+	}
+	d.mu.RUnlock()
+
+	for _, h := range handlers {
+		h(notification)
+	}
+}
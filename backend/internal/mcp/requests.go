@@ -0,0 +1,95 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// RequestHandler answers a request initiated by an upstream server
+// (e.g. sampling/createMessage). It must always return a response.
+type RequestHandler func(ctx context.Context, req *JSONRPCRequest) *JSONRPCResponse
+
+// RequestDispatcher routes server-initiated requests to the subscriber that should answer them.
+// Unlike notifications, a request needs exactly one answer, so it is only routed
+// when a single subscriber is attached to the connection.
+type RequestDispatcher struct {
+	mu       sync.RWMutex
+	handlers map[string]RequestHandler
+	inflight map[string]context.CancelCauseFunc // server request ID -> cancel
+}
+
+// OnRequest registers a handler for the given subscriber, replacing any previous one.
+func (d *RequestDispatcher) OnRequest(subscriberID string, handler RequestHandler) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.handlers == nil {
+		d.handlers = make(map[string]RequestHandler)
+	}
+	d.handlers[subscriberID] = handler
+}
+
+// RemoveRequestHandler unregisters the handler for the given subscriber.
+func (d *RequestDispatcher) RemoveRequestHandler(subscriberID string) {
+	d.mu.Lock()
+	delete(d.handlers, subscriberID)
+	d.mu.Unlock()
+}
+
+// HandleServerRequest answers a server-initiated request through the registered handler.
+func (d *RequestDispatcher) HandleServerRequest(ctx context.Context, req *JSONRPCRequest) *JSONRPCResponse {
+	d.mu.RLock()
+	var handler RequestHandler
+	count := len(d.handlers)
+	for _, h := range d.handlers {
+		handler = h
+	}
+	d.mu.RUnlock()
+
+	switch count {
+	case 0:
+		return NewErrorResponse(req.ID, MethodNotFound, "No client attached to handle "+req.Method)
+	case 1:
+		ctx, cancel := context.WithCancelCause(ctx)
+		reqID := string(req.ID)
+		d.mu.Lock()
+		if d.inflight == nil {
+			d.inflight = make(map[string]context.CancelCauseFunc)
+		}
+		d.inflight[reqID] = cancel
+		d.mu.Unlock()
+		defer func() {
+			d.mu.Lock()
+			delete(d.inflight, reqID)
+			d.mu.Unlock()
+			cancel(nil)
+		}()
+		return handler(ctx, req)
+	default:
+		// A shared connection cannot tell which session the request belongs to
+		return NewErrorResponse(req.ID, InvalidRequest, "Server-initiated requests are not supported on connections shared by multiple sessions")
+	}
+}
+
+// CancelServerRequest aborts an in-flight server-initiated request after the
+// server sent notifications/cancelled for it.
+func (d *RequestDispatcher) CancelServerRequest(notification *JSONRPCNotification) {
+	var params CancelledParams
+	if err := json.Unmarshal(notification.Params, &params); err != nil {
+		return
+	}
+
+	d.mu.RLock()
+	cancel, ok := d.inflight[string(params.RequestID)]
+	d.mu.RUnlock()
+	if !ok {
+		return
+	}
+
+	reason := params.Reason
+	if reason == "" {
+		reason = "Request cancelled by server"
+	}
+	cancel(errors.New(reason))
+}
@@ -0,0 +1,72 @@
+package mcp
+
+import (
+	"sync"
+)
+
+// SubscriptionSet reference-counts resource subscriptions on one upstream
+// connection. A connection can be shared by several gateway sessions (e.g. a
+// shared STDIO process), so the server is only subscribed when the first
+// subscriber arrives and unsubscribed when the last one leaves.
+type SubscriptionSet struct {
+	mu          sync.Mutex
+	subscribers map[string]map[string]struct{} // uri -> subscriber IDs
+}
+
+// AddSubscriber records a subscriber for uri. Returns true if it is the first one
+// (and so the server still needs to be subscribed).
+func (s *SubscriptionSet) AddSubscriber(uri, subscriberID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.subscribers == nil {
+		s.subscribers = make(map[string]map[string]struct{})
+	}
+	subs, ok := s.subscribers[uri]
+	if !ok {
+		subs = make(map[string]struct{})
+		s.subscribers[uri] = subs
+	}
+	if _, ok := subs[subscriberID]; ok {
+		return false
+	}
+	subs[subscriberID] = struct{}{}
+	return len(subs) == 1
+}
+
+// RemoveSubscriber forgets a subscriber for uri. Returns true if it was the last one.
+func (s *SubscriptionSet) RemoveSubscriber(uri, subscriberID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subs, ok := s.subscribers[uri]
+	if !ok {
+		return false
+	}
+	if _, ok := subs[subscriberID]; !ok {
+		return false
+	}
+	delete(subs, subscriberID)
+	if len(subs) > 0 {
+		return false
+	}
+	delete(s.subscribers, uri)
+	return true
+}
+
+// RemoveAllSubscriptions forgets every subscription of a subscriber and returns the URIs
+// nobody is subscribed to anymore.
+func (s *SubscriptionSet) RemoveAllSubscriptions(subscriberID string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var orphaned []string
+	for uri, subs := range s.subscribers {
+		if _, ok := subs[subscriberID]; !ok {
+			continue
+		}
+		delete(subs, subscriberID)
+		if len(subs) == 0 {
+			delete(s.subscribers, uri)
+			orphaned = append(orphaned, uri)
+		}
+	}
+	return orphaned
+}
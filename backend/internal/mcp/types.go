@@ -8,8 +8,8 @@ const (
 	// JSON-RPC version
 	JSONRPCVersion = "2.0"
 
-	// MCP Protocol version
-	MCPProtocolVersion = "2025-06-18"
+	// MCP Protocol version (the newest revision the gateway speaks; see version.go)
+	MCPProtocolVersion = ProtocolVersion20250618
 
 	// MCP methods
 	MethodInitialize        = "initialize"
@@ -20,10 +20,31 @@ const (
 	MethodResourcesList     = "resources/list"
 	MethodResourcesRead     = "resources/read"
 	MethodResourcesTemplates = "resources/templates/list"
+	MethodResourcesSubscribe = "resources/subscribe"
+	MethodResourcesUnsubscribe = "resources/unsubscribe"
 	MethodPromptsList       = "prompts/list"
 	MethodPromptsGet        = "prompts/get"
 	MethodLoggingSetLevel   = "logging/setLevel"
 	MethodCompletionComplete = "completion/complete"
+
+	// MCP server-to-client notifications
+	MethodNotificationToolsListChanged     = "notifications/tools/list_changed"
+	MethodNotificationResourcesListChanged = "notifications/resources/list_changed"
+	MethodNotificationPromptsListChanged   = "notifications/prompts/list_changed"
+	MethodNotificationResourcesUpdated     = "notifications/resources/updated"
+	MethodNotificationMessage              = "notifications/message"
+	MethodNotificationProgress             = "notifications/progress"
+
+	// MCP server-to-client requests
+	MethodSamplingCreateMessage = "sampling/createMessage"
+	MethodElicitationCreate     = "elicitation/create"
+	MethodRootsList             = "roots/list"
+
+	// MCP client-to-server notifications
+	MethodNotificationRootsListChanged = "notifications/roots/list_changed"
+
+	// MCP notifications sent in either direction
+	MethodNotificationCancelled = "notifications/cancelled"
 )
 
 // JSONRPCRequest represents a JSON-RPC 2.0 request
@@ -56,6 +77,48 @@ type JSONRPCNotification struct {
 	Params  json.RawMessage `json:"params,omitempty"`
 }
 
+// JSONRPCMessage is a generic envelope for any inbound JSON-RPC message.
+// Upstream streams interleave responses, notifications and server-initiated
+// requests, so readers decode into this type first and classify afterwards.
+type JSONRPCMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *JSONRPCError   `json:"error,omitempty"`
+}
+
+// IsResponse reports whether the message is a response to an earlier request
+func (m *JSONRPCMessage) IsResponse() bool {
+	return m.Method == "" && m.ID != nil
+}
+
+// IsNotification reports whether the message is a notification (method, no id)
+func (m *JSONRPCMessage) IsNotification() bool {
+	return m.Method != "" && (m.ID == nil || string(m.ID) == "null")
+}
+
+// IsRequest reports whether the message is a request (method and id)
+func (m *JSONRPCMessage) IsRequest() bool {
+	return m.Method != "" && m.ID != nil && string(m.ID) != "null"
+}
+
+// ToRequest converts the envelope to a JSONRPCRequest
+func (m *JSONRPCMessage) ToRequest() *JSONRPCRequest {
+	return &JSONRPCRequest{JSONRPC: m.JSONRPC, ID: m.ID, Method: m.Method, Params: m.Params}
+}
+
+// ToResponse converts the envelope to a JSONRPCResponse
+func (m *JSONRPCMessage) ToResponse() *JSONRPCResponse {
+	return &JSONRPCResponse{JSONRPC: m.JSONRPC, ID: m.ID, Result: m.Result, Error: m.Error}
+}
+
+// ToNotification converts the envelope to a JSONRPCNotification
+func (m *JSONRPCMessage) ToNotification() *JSONRPCNotification {
+	return &JSONRPCNotification{JSONRPC: m.JSONRPC, Method: m.Method, Params: m.Params}
+}
+
 // Standard JSON-RPC error codes
 const (
 	ParseError     = -32700
@@ -65,6 +128,19 @@ const (
 	InternalError  = -32603
 )
 
+// Gateway error codes, in the JSON-RPC server error range
+const (
+	// RateLimited: a rate limit was exceeded; data carries "retryAfter" in
+	// seconds and the "limit" name
+	RateLimited = -32029
+	// QuotaExceeded: a usage quota is used up; data carries the "quota" name,
+	// its "period" and "resetAt"
+	QuotaExceeded = -32030
+	// TargetUnavailable: the target's circuit breaker is open; data carries
+	// the "target" name and "retryAfter" in seconds
+	TargetUnavailable = -32031
+)
+
 // InitializeParams represents the parameters for initialize request
 type InitializeParams struct {
 	ProtocolVersion string           `json:"protocolVersion"`
@@ -74,8 +150,9 @@ type InitializeParams struct {
 
 // ClientCapabilities represents client capabilities
 type ClientCapabilities struct {
-	Roots    *RootsCapability    `json:"roots,omitempty"`
-	Sampling *SamplingCapability `json:"sampling,omitempty"`
+	Roots       *RootsCapability       `json:"roots,omitempty"`
+	Sampling    *SamplingCapability    `json:"sampling,omitempty"`
+	Elicitation *ElicitationCapability `json:"elicitation,omitempty"`
 }
 
 // RootsCapability represents roots capability
@@ -86,6 +163,9 @@ type RootsCapability struct {
 // SamplingCapability represents sampling capability
 type SamplingCapability struct{}
 
+// ElicitationCapability represents elicitation capability
+type ElicitationCapability struct{}
+
 // ClientInfo represents client information
 type ClientInfo struct {
 	Name    string `json:"name"`
@@ -102,10 +182,11 @@ type InitializeResult struct {
 
 // ServerCapabilities represents server capabilities
 type ServerCapabilities struct {
-	Tools     *ToolsCapability     `json:"tools,omitempty"`
-	Resources *ResourcesCapability `json:"resources,omitempty"`
-	Prompts   *PromptsCapability   `json:"prompts,omitempty"`
-	Logging   *LoggingCapability   `json:"logging,omitempty"`
+	Tools       *ToolsCapability       `json:"tools,omitempty"`
+	Resources   *ResourcesCapability   `json:"resources,omitempty"`
+	Prompts     *PromptsCapability     `json:"prompts,omitempty"`
+	Logging     *LoggingCapability     `json:"logging,omitempty"`
+	Completions *CompletionsCapability `json:"completions,omitempty"`
 }
 
 // ToolsCapability represents tools capability
@@ -127,20 +208,33 @@ type PromptsCapability struct {
 // LoggingCapability represents logging capability
 type LoggingCapability struct{}
 
+// CompletionsCapability represents argument completion capability
+type CompletionsCapability struct{}
+
 // ServerInfo represents server information
 type ServerInfo struct {
 	Name    string `json:"name"`
+	Title   string `json:"title,omitempty"`
 	Version string `json:"version"`
 }
 
 // Tool represents an MCP tool.
-// InputSchema and Annotations use json.RawMessage to preserve all upstream fields
-// (JSON Schema has many fields beyond type/properties/required).
+// InputSchema, OutputSchema and Annotations use json.RawMessage to preserve all upstream
+// fields (JSON Schema has many fields beyond type/properties/required).
 type Tool struct {
-	Name        string          `json:"name"`
-	Description string          `json:"description,omitempty"`
-	InputSchema json.RawMessage `json:"inputSchema"`
-	Annotations json.RawMessage `json:"annotations,omitempty"`
+	Name         string          `json:"name"`
+	Title        string          `json:"title,omitempty"`
+	Description  string          `json:"description,omitempty"`
+	InputSchema  json.RawMessage `json:"inputSchema"`
+	OutputSchema json.RawMessage `json:"outputSchema,omitempty"`
+	Annotations  json.RawMessage `json:"annotations,omitempty"`
+	Meta         json.RawMessage `json:"_meta,omitempty"`
+	Extra        Extra           `json:"-"`
+}
+
+// PaginatedParams represents the parameters of a paginated list request
+type PaginatedParams struct {
+	Cursor *string `json:"cursor,omitempty"`
 }
 
 // ToolsListResult represents the result of tools/list
@@ -153,14 +247,18 @@ type ToolsListResult struct {
 type ToolCallParams struct {
 	Name      string                 `json:"name"`
 	Arguments map[string]interface{} `json:"arguments"`
+	Meta      json.RawMessage        `json:"_meta,omitempty"` // e.g. progressToken
 }
 
 // ToolCallResult represents the result of tools/call.
 // Content uses json.RawMessage to preserve all upstream fields without data loss
 // (MCP content is a union type: TextContent | ImageContent | EmbeddedResource).
 type ToolCallResult struct {
-	Content json.RawMessage `json:"content"`
-	IsError bool            `json:"isError,omitempty"`
+	Content           json.RawMessage `json:"content"`
+	StructuredContent json.RawMessage `json:"structuredContent,omitempty"`
+	IsError           bool            `json:"isError,omitempty"`
+	Meta              json.RawMessage `json:"_meta,omitempty"`
+	Extra             Extra           `json:"-"`
 }
 
 // Content represents content in a tool result (used for gateway-generated responses)
@@ -181,9 +279,13 @@ func NewToolCallError(text string) *ToolCallResult {
 type Resource struct {
 	URI         string          `json:"uri"`
 	Name        string          `json:"name"`
+	Title       string          `json:"title,omitempty"`
 	Description string          `json:"description,omitempty"`
 	MimeType    string          `json:"mimeType,omitempty"`
+	Size        *int64          `json:"size,omitempty"`
 	Annotations json.RawMessage `json:"annotations,omitempty"`
+	Meta        json.RawMessage `json:"_meta,omitempty"`
+	Extra       Extra           `json:"-"`
 }
 
 // ResourcesListResult represents the result of resources/list
@@ -202,14 +304,25 @@ type ResourceReadParams struct {
 // (union type: TextResourceContents | BlobResourceContents).
 type ResourceReadResult struct {
 	Contents json.RawMessage `json:"contents"`
+	Meta     json.RawMessage `json:"_meta,omitempty"`
+	Extra    Extra           `json:"-"`
+}
+
+// ResourceSubscribeParams represents parameters for resources/subscribe and resources/unsubscribe
+type ResourceSubscribeParams struct {
+	URI string `json:"uri"`
 }
 
 // ResourceTemplate represents a resource template
 type ResourceTemplate struct {
-	URITemplate string `json:"uriTemplate"`
-	Name        string `json:"name"`
-	Description string `json:"description,omitempty"`
-	MimeType    string `json:"mimeType,omitempty"`
+	URITemplate string          `json:"uriTemplate"`
+	Name        string          `json:"name"`
+	Title       string          `json:"title,omitempty"`
+	Description string          `json:"description,omitempty"`
+	MimeType    string          `json:"mimeType,omitempty"`
+	Annotations json.RawMessage `json:"annotations,omitempty"`
+	Meta        json.RawMessage `json:"_meta,omitempty"`
+	Extra       Extra           `json:"-"`
 }
 
 // ResourceTemplatesListResult represents the result of resources/templates/list
@@ -221,8 +334,11 @@ type ResourceTemplatesListResult struct {
 // Prompt represents an MCP prompt
 type Prompt struct {
 	Name        string          `json:"name"`
+	Title       string          `json:"title,omitempty"`
 	Description string          `json:"description,omitempty"`
 	Arguments   json.RawMessage `json:"arguments,omitempty"`
+	Meta        json.RawMessage `json:"_meta,omitempty"`
+	Extra       Extra           `json:"-"`
 }
 
 // PromptsListResult represents the result of prompts/list
@@ -235,6 +351,7 @@ type PromptsListResult struct {
 type PromptGetParams struct {
 	Name      string            `json:"name"`
 	Arguments map[string]string `json:"arguments,omitempty"`
+	Meta      json.RawMessage   `json:"_meta,omitempty"`
 }
 
 // PromptGetResult represents the result of prompts/get.
@@ -243,6 +360,40 @@ type PromptGetParams struct {
 type PromptGetResult struct {
 	Description string          `json:"description,omitempty"`
 	Messages    json.RawMessage `json:"messages"`
+	Meta        json.RawMessage `json:"_meta,omitempty"`
+	Extra       Extra           `json:"-"`
+}
+
+// Completion reference types
+const (
+	CompletionRefPrompt   = "ref/prompt"
+	CompletionRefResource = "ref/resource"
+)
+
+// CompletionRef identifies what a completion request is for: a prompt by name
+// or a resource template by URI.
+type CompletionRef struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+	URI  string `json:"uri,omitempty"`
+}
+
+// CompleteParams represents parameters for completion/complete
+type CompleteParams struct {
+	Ref      CompletionRef   `json:"ref"`
+	Argument json.RawMessage `json:"argument"`
+	Context  json.RawMessage `json:"context,omitempty"`
+}
+
+// CompleteResult represents the result of completion/complete.
+// Completion uses json.RawMessage to preserve all upstream fields.
+type CompleteResult struct {
+	Completion json.RawMessage `json:"completion"`
+}
+
+// SetLevelParams represents parameters for logging/setLevel
+type SetLevelParams struct {
+	Level string `json:"level"`
 }
 
 // Helper functions
@@ -259,6 +410,15 @@ func NewErrorResponse(id json.RawMessage, code int, message string) *JSONRPCResp
 	}
 }
 
+// NewErrorResponseWithData creates a JSON-RPC error response carrying data
+func NewErrorResponseWithData(id json.RawMessage, code int, message string, data interface{}) *JSONRPCResponse {
+	resp := NewErrorResponse(id, code, message)
+	if raw, err := json.Marshal(data); err == nil {
+		resp.Error.Data = raw
+	}
+	return resp
+}
+
 // NewSuccessResponse creates a JSON-RPC success response
 func NewSuccessResponse(id json.RawMessage, result interface{}) (*JSONRPCResponse, error) {
 	resultJSON, err := json.Marshal(result)
@@ -0,0 +1,45 @@
+package mcp
+
+// MCP protocol revisions the gateway can speak, on either side
+const (
+	ProtocolVersion20241105 = "2024-11-05"
+	ProtocolVersion20250326 = "2025-03-26"
+	ProtocolVersion20250618 = "2025-06-18"
+)
+
+// SupportedProtocolVersions lists the supported revisions, newest first
+var SupportedProtocolVersions = []string{
+	ProtocolVersion20250618,
+	ProtocolVersion20250326,
+	ProtocolVersion20241105,
+}
+
+// IsSupportedProtocolVersion reports whether version is a revision the gateway speaks
+func IsSupportedProtocolVersion(version string) bool {
+	for _, v := range SupportedProtocolVersions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// NegotiateProtocolVersion picks the revision to answer an initialize request with.
+// Per the spec, a supported requested version is echoed back; otherwise the server
+// offers its latest and the client decides whether it can proceed.
+func NegotiateProtocolVersion(requested string) string {
+	if IsSupportedProtocolVersion(requested) {
+		return requested
+	}
+	return MCPProtocolVersion
+}
+
+// ProtocolAtLeast reports whether version is the same as or newer than minimum.
+// Revisions are ISO dates, so they order lexically. An empty version is treated
+// as the oldest supported revision.
+func ProtocolAtLeast(version, minimum string) bool {
+	if version == "" {
+		version = ProtocolVersion20241105
+	}
+	return version >= minimum
+}
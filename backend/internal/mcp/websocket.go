@@ -0,0 +1,197 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WebSocketSubprotocol is the Sec-WebSocket-Protocol value for MCP over WebSocket.
+// Each text frame carries one JSON-RPC message (or batch).
+const WebSocketSubprotocol = "mcp"
+
+const (
+	// WebSocketMaxMessageSize bounds a single incoming frame
+	WebSocketMaxMessageSize = 16 << 20
+
+	// WebSocketWriteTimeout bounds a single frame write
+	WebSocketWriteTimeout = 10 * time.Second
+
+	// WebSocketPingInterval keeps idle connections alive through proxies
+	WebSocketPingInterval = 30 * time.Second
+)
+
+// webSocketURL maps an http(s) target URL onto the matching ws(s) scheme.
+// ws and wss URLs are returned unchanged.
+func webSocketURL(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	case "ws", "wss":
+	default:
+		return "", fmt.Errorf("unsupported URL scheme for WebSocket: %q", u.Scheme)
+	}
+	return u.String(), nil
+}
+
+// connectWebSocket dials the upstream and starts the background reader. Responses,
+// notifications and server-initiated requests all arrive on the one connection.
+func (c *Client) connectWebSocket(ctx context.Context) error {
+	wsURL, err := webSocketURL(c.url)
+	if err != nil {
+		return err
+	}
+
+	// Reuse the HTTP header helpers for the handshake request
+	handshake, err := http.NewRequest(http.MethodGet, c.url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create handshake request: %w", err)
+	}
+	c.applyAuthHeaders(handshake)
+	c.applyCustomHeaders(handshake)
+
+	dialer := websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: 15 * time.Second,
+		Subprotocols:     []string{WebSocketSubprotocol},
+	}
+	conn, resp, err := dialer.DialContext(ctx, wsURL, handshake.Header)
+	if err != nil {
+		if resp != nil {
+			return fmt.Errorf("handshake status %d: %w", resp.StatusCode, err)
+		}
+		return err
+	}
+	conn.SetReadLimit(WebSocketMaxMessageSize)
+
+	done := make(chan struct{})
+	c.mu.Lock()
+	c.wsConn = conn
+	c.sseDone = done
+	c.mu.Unlock()
+
+	log.Info().Str("url", wsURL).Msg("Upstream connected via WebSocket")
+
+	go c.readWebSocket(conn, done)
+	go c.pingWebSocket(conn, done)
+	return nil
+}
+
+// readWebSocket reads frames until the connection closes and routes each message
+func (c *Client) readWebSocket(conn *websocket.Conn, done chan struct{}) {
+	defer close(done)
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				log.Debug().Err(err).Str("url", c.url).Msg("Upstream WebSocket reader ended")
+			}
+			return
+		}
+		c.handleMessage(data)
+	}
+}
+
+// pingWebSocket sends periodic pings until the reader exits
+func (c *Client) pingWebSocket(conn *websocket.Conn, done chan struct{}) {
+	ticker := time.NewTicker(WebSocketPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(WebSocketWriteTimeout)); err != nil {
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// writeWebSocket sends one JSON-RPC message as a text frame
+func (c *Client) writeWebSocket(msg interface{}) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	c.mu.RLock()
+	conn := c.wsConn
+	c.mu.RUnlock()
+	if conn == nil {
+		return fmt.Errorf("WebSocket not connected")
+	}
+
+	c.wsWriteMu.Lock()
+	defer c.wsWriteMu.Unlock()
+	conn.SetWriteDeadline(time.Now().Add(WebSocketWriteTimeout))
+	if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		return fmt.Errorf("WebSocket write failed: %w", err)
+	}
+	return nil
+}
+
+// sendWebSocketRequest writes a request and waits for the reader to route its response
+func (c *Client) sendWebSocketRequest(ctx context.Context, req *JSONRPCRequest, start time.Time) (*JSONRPCResponse, error) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.String("url", c.url))
+
+	reqID := string(req.ID)
+	responseCh := make(chan *JSONRPCResponse, 1)
+	c.mu.Lock()
+	c.sseResponses[reqID] = responseCh
+	done := c.sseDone
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.sseResponses, reqID)
+		c.mu.Unlock()
+	}()
+
+	if err := c.writeWebSocket(req); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		c.recordUpstreamMetrics(ctx, req.Method, "error", start)
+		return nil, err
+	}
+
+	timer := time.NewTimer(c.httpClient.Timeout)
+	defer timer.Stop()
+
+	select {
+	case resp := <-responseCh:
+		c.recordUpstreamMetrics(ctx, req.Method, "ok", start)
+		return resp, nil
+	case <-done:
+		c.recordUpstreamMetrics(ctx, req.Method, "error", start)
+		return nil, fmt.Errorf("WebSocket connection closed while waiting for response")
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-timer.C:
+		c.recordUpstreamMetrics(ctx, req.Method, "error", start)
+		return nil, fmt.Errorf("timeout waiting for WebSocket response")
+	}
+}
+
+// closeWebSocket sends a close frame and tears the connection down
+func (c *Client) closeWebSocket(conn *websocket.Conn) {
+	msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+	conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+	conn.Close()
+}
@@ -18,10 +18,13 @@ var upgrader = websocket.Upgrader{
 type EventType string
 
 const (
-	EventActivity EventType = "activity"
-	EventMetrics  EventType = "metrics"
-	EventSession  EventType = "session"
-	EventError    EventType = "error"
+	EventActivity  EventType = "activity"
+	EventMetrics   EventType = "metrics"
+	EventSession   EventType = "session"
+	EventError     EventType = "error"
+	EventRedaction EventType = "redaction"
+	EventApproval  EventType = "approval"
+	EventQuota     EventType = "quota"
 )
 
 // Event is a message sent to WebSocket clients.
@@ -60,6 +63,48 @@ type ErrorEvent struct {
 	Message   string    `json:"message"`
 }
 
+// RedactionEvent reports a redaction rule matching upstream content.
+type RedactionEvent struct {
+	Timestamp time.Time `json:"timestamp"`
+	UserID    string    `json:"user_id"`
+	UserEmail string    `json:"user_email,omitempty"`
+	Method    string    `json:"method"`
+	Target    string    `json:"target,omitempty"`
+	Name      string    `json:"name"` // tool, resource URI or prompt as the client named it
+	Rule      string    `json:"rule"`
+	Detector  string    `json:"detector"`
+	Action    string    `json:"action"` // "mask", "drop" or "block"
+	Count     int       `json:"count"`
+}
+
+// ApprovalEvent reports a tool call waiting for approval, or its outcome.
+type ApprovalEvent struct {
+	Timestamp    time.Time `json:"timestamp"`
+	ApprovalID   string    `json:"approval_id"`
+	UserID       string    `json:"user_id"`
+	UserEmail    string    `json:"user_email,omitempty"`
+	Target       string    `json:"target,omitempty"`
+	Tool         string    `json:"tool"`
+	Policy       string    `json:"policy"`
+	ApproverRole string    `json:"approver_role"`
+	Status       string    `json:"status"` // "pending", "approved", "rejected", "expired" or "cancelled"
+	DecidedBy    string    `json:"decided_by,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// QuotaEvent reports a user reaching a usage quota's warning threshold, or a
+// call rejected because the quota is used up.
+type QuotaEvent struct {
+	Timestamp time.Time `json:"timestamp"`
+	UserID    string    `json:"user_id"`
+	UserEmail string    `json:"user_email,omitempty"`
+	Quota     string    `json:"quota"`
+	Period    string    `json:"period"` // "day" or "month"
+	Used      float64   `json:"used"`
+	MaxCost   float64   `json:"max_cost"`
+	Status    string    `json:"status"` // "warning" or "exceeded"
+}
+
 // Hub manages WebSocket connections and broadcasts events to admin clients.
 type Hub struct {
 	clients    map[*wsClient]struct{}
@@ -211,3 +256,18 @@ func (h *Hub) EmitSession(e SessionEvent) {
 func (h *Hub) EmitError(e ErrorEvent) {
 	h.Publish(Event{Type: EventError, Data: e})
 }
+
+// EmitRedaction publishes a redaction event.
+func (h *Hub) EmitRedaction(e RedactionEvent) {
+	h.Publish(Event{Type: EventRedaction, Data: e})
+}
+
+// EmitApproval publishes an approval event.
+func (h *Hub) EmitApproval(e ApprovalEvent) {
+	h.Publish(Event{Type: EventApproval, Data: e})
+}
+
+// EmitQuota publishes a usage quota event.
+func (h *Hub) EmitQuota(e QuotaEvent) {
+	h.Publish(Event{Type: EventQuota, Data: e})
+}
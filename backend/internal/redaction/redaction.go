@@ -0,0 +1,207 @@
+// Package redaction finds secrets and personal data in text and masks them.
+package redaction
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// Actions, from least to most severe
+const (
+	ActionMask  = "mask"  // replace each match with a placeholder
+	ActionDrop  = "drop"  // remove the content block containing a match
+	ActionBlock = "block" // fail the whole request
+)
+
+// DetectorRegex is the detector of admin-defined rules, which bring their own pattern
+const DetectorRegex = "regex"
+
+type detector struct {
+	pattern *regexp.Regexp
+	valid   func(match string) bool // optional check on top of the pattern
+}
+
+var detectors = map[string]detector{
+	"aws_access_key": {pattern: regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA|AGPA|AIDA|AIPA|ANPA|ANVA|AROA|APKA)[A-Z0-9]{16}\b`)},
+	"github_token":   {pattern: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{22,255})\b`)},
+	"jwt":            {pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)},
+	"email":          {pattern: regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}\b`)},
+	"credit_card":    {pattern: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), valid: luhn},
+}
+
+// Detectors returns the names of the built-in detectors
+func Detectors() []string {
+	names := make([]string, 0, len(detectors))
+	for name := range detectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Rule is a compiled redaction rule
+type Rule struct {
+	Name     string
+	Detector string
+	Action   string
+	pattern  *regexp.Regexp
+	valid    func(string) bool
+}
+
+// Compile builds a rule from a built-in detector, or from pattern when the
+// detector is "regex"
+func Compile(name, detectorName, pattern, action string) (*Rule, error) {
+	switch action {
+	case ActionMask, ActionDrop, ActionBlock:
+	default:
+		return nil, fmt.Errorf("action must be '%s', '%s' or '%s'", ActionMask, ActionDrop, ActionBlock)
+	}
+
+	rule := &Rule{Name: name, Detector: detectorName, Action: action}
+	if detectorName == DetectorRegex {
+		if pattern == "" {
+			return nil, fmt.Errorf("pattern is required for detector %q", DetectorRegex)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern: %w", err)
+		}
+		rule.pattern = re
+		return rule, nil
+	}
+
+	d, ok := detectors[detectorName]
+	if !ok {
+		return nil, fmt.Errorf("unknown detector %q", detectorName)
+	}
+	rule.pattern = d.pattern
+	rule.valid = d.valid
+	return rule, nil
+}
+
+// Placeholder is the text a masked match is replaced with
+func (r *Rule) Placeholder() string {
+	return "[REDACTED:" + r.Name + "]"
+}
+
+// Finding records how often one rule matched
+type Finding struct {
+	Rule     string `json:"rule"`
+	Detector string `json:"detector"`
+	Action   string `json:"action"`
+	Count    int    `json:"count"`
+}
+
+// Findings accumulates the matches of one or more scans, one entry per rule
+type Findings []Finding
+
+func (f *Findings) add(finding Finding) {
+	for i := range *f {
+		if (*f)[i].Rule == finding.Rule {
+			(*f)[i].Count += finding.Count
+			return
+		}
+	}
+	*f = append(*f, finding)
+}
+
+// Merge adds other's findings to f
+func (f *Findings) Merge(other Findings) {
+	for _, finding := range other {
+		f.add(finding)
+	}
+}
+
+// Action returns the most severe action among the findings, or "" if there are none
+func (f Findings) Action() string {
+	action := ""
+	for _, finding := range f {
+		if severity(finding.Action) > severity(action) {
+			action = finding.Action
+		}
+	}
+	return action
+}
+
+// Blocking returns the first finding whose rule blocks the request, or nil
+func (f Findings) Blocking() *Finding {
+	for i := range f {
+		if f[i].Action == ActionBlock {
+			return &f[i]
+		}
+	}
+	return nil
+}
+
+func severity(action string) int {
+	switch action {
+	case ActionMask:
+		return 1
+	case ActionDrop:
+		return 2
+	case ActionBlock:
+		return 3
+	}
+	return 0
+}
+
+// Scan replaces every match of the rules in text with the rule's placeholder
+// and records the matches in findings
+func Scan(rules []*Rule, text string, findings *Findings) string {
+	for _, rule := range rules {
+		count := 0
+		text = rule.pattern.ReplaceAllStringFunc(text, func(match string) string {
+			if rule.valid != nil && !rule.valid(match) {
+				return match
+			}
+			count++
+			return rule.Placeholder()
+		})
+		if count > 0 {
+			findings.add(Finding{Rule: rule.Name, Detector: rule.Detector, Action: rule.Action, Count: count})
+		}
+	}
+	return text
+}
+
+// ScanValue scans every string in a decoded JSON value, at any depth, and
+// returns the masked value
+func ScanValue(rules []*Rule, value interface{}, findings *Findings) interface{} {
+	switch v := value.(type) {
+	case string:
+		return Scan(rules, v, findings)
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = ScanValue(rules, item, findings)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = ScanValue(rules, item, findings)
+		}
+		return v
+	}
+	return value
+}
+
+// luhn reports whether the digits in s pass the Luhn checksum
+func luhn(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && sum%10 == 0
+}
@@ -213,6 +213,25 @@ func (m *Manager) Stats() Stats {
 	}
 }
 
+// TargetProcesses counts the processes of a target that are running and that
+// exited without being stopped by the manager.
+func (m *Manager) TargetProcesses(targetName string) (alive, exited int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, proc := range m.processes {
+		if proc.targetName != targetName {
+			continue
+		}
+		if proc.IsAlive() {
+			alive++
+		} else {
+			exited++
+		}
+	}
+	return alive, exited
+}
+
 // ComputeSubjectKey computes the subject key for isolation routing.
 func ComputeSubjectKey(target *database.Target, userID string, role string, groups []string) string {
 	switch target.IsolationBoundary {
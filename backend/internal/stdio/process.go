@@ -23,19 +23,28 @@ type Process struct {
 	stdout io.ReadCloser
 	stderr io.ReadCloser
 
-	mu           sync.RWMutex
-	initialized  bool
-	capabilities *mcp.ServerCapabilities
-	serverInfo   *mcp.ServerInfo
+	mu              sync.RWMutex
+	initialized     bool
+	capabilities    *mcp.ServerCapabilities
+	serverInfo      *mcp.ServerInfo
+	protocolVersion string // revision negotiated with the process
 
 	pending  map[string]chan *mcp.JSONRPCResponse
 	pendMu   sync.Mutex
+	writeMu  sync.Mutex // serializes writes to stdin so lines never interleave
 	nextID   int64
 	lastUsed atomic.Int64
 	done     chan struct{}
 
 	subjectKey string
 	targetName string
+
+	// Server-to-client notifications, fanned out to every session using this process
+	mcp.NotificationDispatcher
+	// Server-to-client requests, answered by the single session using this process
+	mcp.RequestDispatcher
+	// Resource subscriptions, reference-counted across the sessions using this process
+	mcp.SubscriptionSet
 }
 
 // ProcessConfig holds configuration for creating a STDIO process.
@@ -94,7 +103,7 @@ func NewProcess(cfg ProcessConfig) (*Process, error) {
 	return p, nil
 }
 
-// readLoop reads line-delimited JSON from stdout and routes responses.
+// readLoop reads line-delimited JSON from stdout and routes responses and notifications.
 func (p *Process) readLoop() {
 	defer close(p.done)
 	scanner := bufio.NewScanner(p.stdout)
@@ -106,8 +115,8 @@ func (p *Process) readLoop() {
 			continue
 		}
 
-		var resp mcp.JSONRPCResponse
-		if err := json.Unmarshal(line, &resp); err != nil {
+		var msg mcp.JSONRPCMessage
+		if err := json.Unmarshal(line, &msg); err != nil {
 			log.Debug().
 				Str("target", p.targetName).
 				Str("line", string(line)).
@@ -116,11 +125,21 @@ func (p *Process) readLoop() {
 			continue
 		}
 
-		if resp.ID != nil {
-			reqID := string(resp.ID)
+		switch {
+		case msg.IsNotification():
+			notification := msg.ToNotification()
+			if notification.Method == mcp.MethodNotificationCancelled {
+				p.CancelServerRequest(notification)
+			} else {
+				p.Dispatch(notification)
+			}
+		case msg.IsRequest():
+			go p.serveRequest(msg.ToRequest())
+		case msg.IsResponse():
+			reqID := string(msg.ID)
 			p.pendMu.Lock()
 			if ch, ok := p.pending[reqID]; ok {
-				ch <- &resp
+				ch <- msg.ToResponse()
 				delete(p.pending, reqID)
 			}
 			p.pendMu.Unlock()
@@ -167,14 +186,8 @@ func (p *Process) sendRequest(ctx context.Context, req *mcp.JSONRPCRequest) (*mc
 		p.pendMu.Unlock()
 	}()
 
-	data, err := json.Marshal(req)
-	if err != nil {
-		return nil, fmt.Errorf("marshal request: %w", err)
-	}
-	data = append(data, '\n')
-
-	if _, err := p.stdin.Write(data); err != nil {
-		return nil, fmt.Errorf("write to stdin: %w", err)
+	if err := p.writeMessage(req); err != nil {
+		return nil, err
 	}
 
 	select {
@@ -183,21 +196,48 @@ func (p *Process) sendRequest(ctx context.Context, req *mcp.JSONRPCRequest) (*mc
 	case <-p.done:
 		return nil, fmt.Errorf("STDIO process exited while waiting for response")
 	case <-ctx.Done():
+		p.notifyCancelled(req, mcp.CancelReason(ctx))
 		return nil, ctx.Err()
 	case <-time.After(60 * time.Second):
+		p.notifyCancelled(req, "Request timed out")
 		return nil, fmt.Errorf("timeout waiting for STDIO response")
 	}
 }
 
+// notifyCancelled tells the process to stop working on an abandoned request.
+func (p *Process) notifyCancelled(req *mcp.JSONRPCRequest, reason string) {
+	if err := p.writeMessage(mcp.NewCancelledNotification(req.ID, reason)); err != nil {
+		log.Debug().Err(err).Str("target", p.targetName).Str("method", req.Method).Msg("Failed to send cancellation")
+	}
+}
+
 // sendNotification writes a JSON-RPC notification to stdin (no response expected).
 func (p *Process) sendNotification(req *mcp.JSONRPCNotification) error {
-	data, err := json.Marshal(req)
+	return p.writeMessage(req)
+}
+
+// serveRequest answers a server-initiated request and writes the response to stdin.
+func (p *Process) serveRequest(req *mcp.JSONRPCRequest) {
+	resp := p.HandleServerRequest(context.Background(), req)
+	if err := p.writeMessage(resp); err != nil {
+		log.Warn().Err(err).Str("target", p.targetName).Str("method", req.Method).Msg("Failed to answer server request")
+	}
+}
+
+// writeMessage writes one newline-delimited JSON-RPC message to stdin.
+func (p *Process) writeMessage(msg interface{}) error {
+	data, err := json.Marshal(msg)
 	if err != nil {
-		return fmt.Errorf("marshal notification: %w", err)
+		return fmt.Errorf("marshal message: %w", err)
 	}
 	data = append(data, '\n')
-	_, err = p.stdin.Write(data)
-	return err
+
+	p.writeMu.Lock()
+	defer p.writeMu.Unlock()
+	if _, err := p.stdin.Write(data); err != nil {
+		return fmt.Errorf("write to stdin: %w", err)
+	}
+	return nil
 }
 
 // Initialize sends the initialize request to the STDIO process.
@@ -218,18 +258,22 @@ func (p *Process) Initialize(ctx context.Context, params *mcp.InitializeParams)
 		return nil, err
 	}
 	if resp.Error != nil {
-		return nil, fmt.Errorf("initialize error: %s (code: %d)", resp.Error.Message, resp.Error.Code)
+		return nil, mcp.NewUpstreamError("initialize", resp.Error)
 	}
 
 	var result mcp.InitializeResult
 	if err := json.Unmarshal(resp.Result, &result); err != nil {
 		return nil, fmt.Errorf("unmarshal initialize result: %w", err)
 	}
+	if !mcp.IsSupportedProtocolVersion(result.ProtocolVersion) {
+		return nil, fmt.Errorf("process negotiated unsupported protocol version %q", result.ProtocolVersion)
+	}
 
 	p.mu.Lock()
 	p.initialized = true
 	p.capabilities = &result.Capabilities
 	p.serverInfo = &result.ServerInfo
+	p.protocolVersion = result.ProtocolVersion
 	p.mu.Unlock()
 
 	// Send initialized notification
@@ -262,7 +306,7 @@ func (p *Process) ListTools(ctx context.Context, cursor *string) (*mcp.ToolsList
 		return nil, err
 	}
 	if resp.Error != nil {
-		return nil, fmt.Errorf("tools/list error: %s (code: %d)", resp.Error.Message, resp.Error.Code)
+		return nil, mcp.NewUpstreamError("tools/list", resp.Error)
 	}
 
 	var result mcp.ToolsListResult
@@ -290,7 +334,7 @@ func (p *Process) CallTool(ctx context.Context, params *mcp.ToolCallParams) (*mc
 		return nil, err
 	}
 	if resp.Error != nil {
-		return nil, fmt.Errorf("tools/call error: %s (code: %d)", resp.Error.Message, resp.Error.Code)
+		return nil, mcp.NewUpstreamError("tools/call", resp.Error)
 	}
 
 	var result mcp.ToolCallResult
@@ -318,7 +362,7 @@ func (p *Process) ListResources(ctx context.Context, cursor *string) (*mcp.Resou
 		return nil, err
 	}
 	if resp.Error != nil {
-		return nil, fmt.Errorf("resources/list error: %s (code: %d)", resp.Error.Message, resp.Error.Code)
+		return nil, mcp.NewUpstreamError("resources/list", resp.Error)
 	}
 
 	var result mcp.ResourcesListResult
@@ -344,7 +388,7 @@ func (p *Process) ReadResource(ctx context.Context, uri string) (*mcp.ResourceRe
 		return nil, err
 	}
 	if resp.Error != nil {
-		return nil, fmt.Errorf("resources/read error: %s (code: %d)", resp.Error.Message, resp.Error.Code)
+		return nil, mcp.NewUpstreamError("resources/read", resp.Error)
 	}
 
 	var result mcp.ResourceReadResult
@@ -354,6 +398,92 @@ func (p *Process) ReadResource(ctx context.Context, uri string) (*mcp.ResourceRe
 	return &result, nil
 }
 
+// ListResourceTemplates retrieves resource templates from the STDIO process.
+func (p *Process) ListResourceTemplates(ctx context.Context, cursor *string) (*mcp.ResourceTemplatesListResult, error) {
+	req := &mcp.JSONRPCRequest{
+		JSONRPC: mcp.JSONRPCVersion,
+		ID:      p.allocID(),
+		Method:  mcp.MethodResourcesTemplates,
+	}
+	if cursor != nil {
+		params := map[string]string{"cursor": *cursor}
+		paramsJSON, _ := json.Marshal(params)
+		req.Params = paramsJSON
+	}
+
+	resp, err := p.sendRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, mcp.NewUpstreamError("resources/templates/list", resp.Error)
+	}
+
+	var result mcp.ResourceTemplatesListResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal resources/templates/list: %w", err)
+	}
+	return &result, nil
+}
+
+// SubscribeResource subscribes to updates of a resource on behalf of a session.
+// Only the first session subscribing to a URI causes a resources/subscribe.
+func (p *Process) SubscribeResource(ctx context.Context, subscriberID, uri string) error {
+	if !p.AddSubscriber(uri, subscriberID) {
+		return nil
+	}
+	if err := p.sendSubscription(ctx, mcp.MethodResourcesSubscribe, uri); err != nil {
+		p.RemoveSubscriber(uri, subscriberID)
+		return err
+	}
+	return nil
+}
+
+// UnsubscribeResource drops a session's subscription to a resource.
+// Only the last session leaving a URI causes a resources/unsubscribe.
+func (p *Process) UnsubscribeResource(ctx context.Context, subscriberID, uri string) error {
+	if !p.RemoveSubscriber(uri, subscriberID) {
+		return nil
+	}
+	return p.sendSubscription(ctx, mcp.MethodResourcesUnsubscribe, uri)
+}
+
+// ReleaseSubscriptions drops every subscription of a session, unsubscribing in
+// the background from URIs no other session is subscribed to.
+func (p *Process) ReleaseSubscriptions(subscriberID string) {
+	orphaned := p.RemoveAllSubscriptions(subscriberID)
+	if len(orphaned) == 0 {
+		return
+	}
+	go func() {
+		for _, uri := range orphaned {
+			if err := p.sendSubscription(context.Background(), mcp.MethodResourcesUnsubscribe, uri); err != nil {
+				log.Debug().Err(err).Str("target", p.targetName).Str("uri", uri).Msg("Failed to unsubscribe resource")
+			}
+		}
+	}()
+}
+
+// sendSubscription sends resources/subscribe or resources/unsubscribe for a URI.
+func (p *Process) sendSubscription(ctx context.Context, method, uri string) error {
+	req := &mcp.JSONRPCRequest{
+		JSONRPC: mcp.JSONRPCVersion,
+		ID:      p.allocID(),
+		Method:  method,
+	}
+	paramsJSON, _ := json.Marshal(mcp.ResourceSubscribeParams{URI: uri})
+	req.Params = paramsJSON
+
+	resp, err := p.sendRequest(ctx, req)
+	if err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return mcp.NewUpstreamError(method, resp.Error)
+	}
+	return nil
+}
+
 // ListPrompts retrieves prompts from the STDIO process.
 func (p *Process) ListPrompts(ctx context.Context, cursor *string) (*mcp.PromptsListResult, error) {
 	req := &mcp.JSONRPCRequest{
@@ -372,7 +502,7 @@ func (p *Process) ListPrompts(ctx context.Context, cursor *string) (*mcp.Prompts
 		return nil, err
 	}
 	if resp.Error != nil {
-		return nil, fmt.Errorf("prompts/list error: %s (code: %d)", resp.Error.Message, resp.Error.Code)
+		return nil, mcp.NewUpstreamError("prompts/list", resp.Error)
 	}
 
 	var result mcp.PromptsListResult
@@ -400,7 +530,7 @@ func (p *Process) GetPrompt(ctx context.Context, params *mcp.PromptGetParams) (*
 		return nil, err
 	}
 	if resp.Error != nil {
-		return nil, fmt.Errorf("prompts/get error: %s (code: %d)", resp.Error.Message, resp.Error.Code)
+		return nil, mcp.NewUpstreamError("prompts/get", resp.Error)
 	}
 
 	var result mcp.PromptGetResult
@@ -410,6 +540,59 @@ func (p *Process) GetPrompt(ctx context.Context, params *mcp.PromptGetParams) (*
 	return &result, nil
 }
 
+// Complete requests argument completions from the STDIO process.
+func (p *Process) Complete(ctx context.Context, params *mcp.CompleteParams) (*mcp.CompleteResult, error) {
+	req := &mcp.JSONRPCRequest{
+		JSONRPC: mcp.JSONRPCVersion,
+		ID:      p.allocID(),
+		Method:  mcp.MethodCompletionComplete,
+	}
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("marshal params: %w", err)
+	}
+	req.Params = paramsJSON
+
+	resp, err := p.sendRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, mcp.NewUpstreamError("completion/complete", resp.Error)
+	}
+
+	var result mcp.CompleteResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal completion/complete: %w", err)
+	}
+	return &result, nil
+}
+
+// SetLoggingLevel sets the minimum level of log notifications sent by the STDIO process.
+func (p *Process) SetLoggingLevel(ctx context.Context, level string) error {
+	req := &mcp.JSONRPCRequest{
+		JSONRPC: mcp.JSONRPCVersion,
+		ID:      p.allocID(),
+		Method:  mcp.MethodLoggingSetLevel,
+	}
+	paramsJSON, _ := json.Marshal(mcp.SetLevelParams{Level: level})
+	req.Params = paramsJSON
+
+	resp, err := p.sendRequest(ctx, req)
+	if err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return mcp.NewUpstreamError("logging/setLevel", resp.Error)
+	}
+	return nil
+}
+
+// SendNotification sends a client-to-server notification to the STDIO process.
+func (p *Process) SendNotification(ctx context.Context, notification *mcp.JSONRPCNotification) error {
+	return p.sendNotification(notification)
+}
+
 // SendRawRequest sends a raw JSON-RPC request to the STDIO process.
 func (p *Process) SendRawRequest(ctx context.Context, req *mcp.JSONRPCRequest) (*mcp.JSONRPCResponse, error) {
 	return p.sendRequest(ctx, req)
@@ -436,6 +619,16 @@ func (p *Process) GetServerInfo() *mcp.ServerInfo {
 	return p.serverInfo
 }
 
+// ProtocolVersion returns the revision negotiated with the process.
+func (p *Process) ProtocolVersion() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.protocolVersion == "" {
+		return mcp.MCPProtocolVersion
+	}
+	return p.protocolVersion
+}
+
 // IsAlive checks if the process is still running.
 func (p *Process) IsAlive() bool {
 	select {
@@ -11,10 +11,21 @@ var (
 	MCPRequestDuration        metric.Float64Histogram
 	MCPToolCallsTotal         metric.Int64Counter
 	MCPToolCallDuration       metric.Float64Histogram
+	MCPToolCallsRateLimited   metric.Int64Counter
+	MCPCacheHits              metric.Int64Counter
+	MCPCacheMisses            metric.Int64Counter
 	MCPAuthzDecisionsTotal    metric.Int64Counter
 	MCPSessionsActive         metric.Int64UpDownCounter
 	MCPUpstreamRequestsTotal  metric.Int64Counter
 	MCPUpstreamRequestDuration metric.Float64Histogram
+	MCPUpstreamRetries        metric.Int64Counter
+	MCPUpstreamRejected       metric.Int64Counter
+	MCPBreakerTransitions     metric.Int64Counter
+	MCPHealthCheckDuration    metric.Float64Histogram
+	MCPEndpointRequests       metric.Int64Counter
+	MCPEndpointDuration       metric.Float64Histogram
+	MCPEndpointOutstanding    metric.Int64UpDownCounter
+	MCPEndpointFailovers      metric.Int64Counter
 )
 
 // InitMetrics registers all custom MCP metrics.
@@ -36,6 +47,15 @@ func InitMetrics() {
 		metric.WithDescription("MCP tool call duration in milliseconds"),
 		metric.WithUnit("ms"),
 	)
+	MCPToolCallsRateLimited, _ = meter.Int64Counter("mcp.tool.calls.rate_limited",
+		metric.WithDescription("Total MCP tool calls rejected by a rate limit"),
+	)
+	MCPCacheHits, _ = meter.Int64Counter("mcp.cache.hits",
+		metric.WithDescription("Total cacheable MCP requests answered from the response cache"),
+	)
+	MCPCacheMisses, _ = meter.Int64Counter("mcp.cache.misses",
+		metric.WithDescription("Total cacheable MCP requests forwarded upstream"),
+	)
 	MCPAuthzDecisionsTotal, _ = meter.Int64Counter("mcp.authz.decisions.total",
 		metric.WithDescription("Total authorization decisions"),
 	)
@@ -49,4 +69,30 @@ func InitMetrics() {
 		metric.WithDescription("Upstream MCP request duration in milliseconds"),
 		metric.WithUnit("ms"),
 	)
+	MCPUpstreamRetries, _ = meter.Int64Counter("mcp.upstream.retries",
+		metric.WithDescription("Total upstream MCP requests retried after a failure"),
+	)
+	MCPUpstreamRejected, _ = meter.Int64Counter("mcp.upstream.rejected",
+		metric.WithDescription("Total upstream MCP requests failed fast by an open circuit breaker"),
+	)
+	MCPBreakerTransitions, _ = meter.Int64Counter("mcp.upstream.breaker.transitions",
+		metric.WithDescription("Total circuit breaker state changes"),
+	)
+	MCPHealthCheckDuration, _ = meter.Float64Histogram("mcp.target.health_check.duration",
+		metric.WithDescription("Target health check duration in milliseconds"),
+		metric.WithUnit("ms"),
+	)
+	MCPEndpointRequests, _ = meter.Int64Counter("mcp.upstream.endpoint.requests",
+		metric.WithDescription("Total upstream MCP requests sent to each target endpoint"),
+	)
+	MCPEndpointDuration, _ = meter.Float64Histogram("mcp.upstream.endpoint.duration",
+		metric.WithDescription("Upstream MCP request duration per target endpoint in milliseconds"),
+		metric.WithUnit("ms"),
+	)
+	MCPEndpointOutstanding, _ = meter.Int64UpDownCounter("mcp.upstream.endpoint.outstanding",
+		metric.WithDescription("Upstream MCP requests in flight to each target endpoint"),
+	)
+	MCPEndpointFailovers, _ = meter.Int64Counter("mcp.upstream.endpoint.failovers",
+		metric.WithDescription("Total failovers away from a target endpoint"),
+	)
 }
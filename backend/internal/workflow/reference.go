@@ -0,0 +1,216 @@
+// Package workflow resolves the data references that pass values between the
+// steps of a composite virtual tool.
+//
+// A reference is a JSONPath-style expression rooted at "$": "$.input.title",
+// "$.steps.ticket.result.key" or "$.steps.search.result.items[0].id". An
+// argument value that is exactly one reference is replaced by the referenced
+// value with its JSON type intact. References embedded in a longer string are
+// written as "{{$.steps.ticket.result.key}}" and interpolated as text.
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Path is a parsed reference: object keys (string) and array indexes (int)
+type Path []interface{}
+
+var (
+	segmentPattern  = regexp.MustCompile(`^\.([A-Za-z0-9_-]+)|^\[(\d+)\]`)
+	embeddedPattern = regexp.MustCompile(`\{\{\s*(\$[^}]*?)\s*\}\}`)
+)
+
+// ParsePath parses a reference such as "$.steps.ticket.result.items[0]"
+func ParsePath(ref string) (Path, error) {
+	if !strings.HasPrefix(ref, "$") {
+		return nil, fmt.Errorf("reference %q must start with $", ref)
+	}
+
+	var path Path
+	rest := ref[1:]
+	for rest != "" {
+		m := segmentPattern.FindStringSubmatch(rest)
+		if m == nil {
+			return nil, fmt.Errorf("invalid reference %q at %q", ref, rest)
+		}
+		if m[1] != "" {
+			path = append(path, m[1])
+		} else {
+			index, _ := strconv.Atoi(m[2])
+			path = append(path, index)
+		}
+		rest = rest[len(m[0]):]
+	}
+	if len(path) == 0 {
+		return nil, fmt.Errorf("reference %q selects nothing", ref)
+	}
+	return path, nil
+}
+
+// String formats the path back into reference syntax
+func (p Path) String() string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, segment := range p {
+		switch s := segment.(type) {
+		case string:
+			b.WriteString("." + s)
+		case int:
+			b.WriteString("[" + strconv.Itoa(s) + "]")
+		}
+	}
+	return b.String()
+}
+
+// Lookup follows the path through decoded JSON data
+func (p Path) Lookup(data interface{}) (interface{}, error) {
+	current := data
+	for i, segment := range p {
+		switch s := segment.(type) {
+		case string:
+			obj, ok := current.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s is not an object", p[:i])
+			}
+			value, ok := obj[s]
+			if !ok {
+				return nil, fmt.Errorf("%s is not set", p[:i+1])
+			}
+			current = value
+		case int:
+			arr, ok := current.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s is not an array", p[:i])
+			}
+			if s >= len(arr) {
+				return nil, fmt.Errorf("%s is out of range", p[:i+1])
+			}
+			current = arr[s]
+		}
+	}
+	return current, nil
+}
+
+// References returns every reference used in a template value
+func References(template interface{}) ([]Path, error) {
+	var paths []Path
+	err := walkStrings(template, func(s string) error {
+		if isReference(s) {
+			path, err := ParsePath(s)
+			if err != nil {
+				return err
+			}
+			paths = append(paths, path)
+			return nil
+		}
+		for _, m := range embeddedPattern.FindAllStringSubmatch(s, -1) {
+			path, err := ParsePath(m[1])
+			if err != nil {
+				return err
+			}
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	return paths, err
+}
+
+// Resolve returns a copy of the template with every reference replaced by its
+// value in data. Objects and arrays are resolved recursively.
+func Resolve(template, data interface{}) (interface{}, error) {
+	switch t := template.(type) {
+	case string:
+		return resolveString(t, data)
+	case map[string]interface{}:
+		resolved := make(map[string]interface{}, len(t))
+		for key, value := range t {
+			v, err := Resolve(value, data)
+			if err != nil {
+				return nil, err
+			}
+			resolved[key] = v
+		}
+		return resolved, nil
+	case []interface{}:
+		resolved := make([]interface{}, len(t))
+		for i, value := range t {
+			v, err := Resolve(value, data)
+			if err != nil {
+				return nil, err
+			}
+			resolved[i] = v
+		}
+		return resolved, nil
+	default:
+		return template, nil
+	}
+}
+
+func resolveString(s string, data interface{}) (interface{}, error) {
+	if isReference(s) {
+		path, err := ParsePath(s)
+		if err != nil {
+			return nil, err
+		}
+		return path.Lookup(data)
+	}
+
+	var resolveErr error
+	out := embeddedPattern.ReplaceAllStringFunc(s, func(match string) string {
+		if resolveErr != nil {
+			return match
+		}
+		path, err := ParsePath(embeddedPattern.FindStringSubmatch(match)[1])
+		if err != nil {
+			resolveErr = err
+			return match
+		}
+		value, err := path.Lookup(data)
+		if err != nil {
+			resolveErr = err
+			return match
+		}
+		return formatText(value)
+	})
+	if resolveErr != nil {
+		return nil, resolveErr
+	}
+	return out, nil
+}
+
+// formatText renders an interpolated value: strings as-is, anything else as JSON
+func formatText(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+func isReference(s string) bool {
+	return strings.HasPrefix(s, "$.") || strings.HasPrefix(s, "$[")
+}
+
+func walkStrings(template interface{}, fn func(string) error) error {
+	switch t := template.(type) {
+	case string:
+		return fn(t)
+	case map[string]interface{}:
+		for _, value := range t {
+			if err := walkStrings(value, fn); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, value := range t {
+			if err := walkStrings(value, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
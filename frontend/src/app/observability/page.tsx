@@ -356,6 +356,7 @@ function nodeLabel(label: string, Icon: React.ElementType) {
 const transportLabels: Record<string, string> = {
   "streamable-http": "Streamable HTTP",
   sse: "SSE",
+  websocket: "WebSocket",
   stdio: "STDIO",
   kubernetes: "Kubernetes",
 };
@@ -7,6 +7,7 @@ import {
   targetsApi,
   AuthorizationPolicy,
   CreatePolicyRequest,
+  PolicyEffect,
   Target,
 } from "@/lib/api";
 import { DashboardLayout } from "@/components/dashboard-layout";
@@ -29,6 +30,7 @@ import {
   Trash2,
   ShieldCheck,
   ShieldX,
+  ShieldAlert,
   MoreVertical,
   Loader2,
   UserCircle,
@@ -76,11 +78,15 @@ function PolicyCard({
             <div className={`flex h-11 w-11 items-center justify-center rounded-lg ${policy.enabled
                 ? policy.effect === "allow"
                   ? "bg-emerald-500 text-white"
-                  : "bg-red-500 text-white"
+                  : policy.effect === "require_approval"
+                    ? "bg-amber-500 text-white"
+                    : "bg-red-500 text-white"
                 : "bg-muted text-muted-foreground"
               }`}>
               {policy.effect === "allow" ? (
                 <ShieldCheck className="h-5 w-5" />
+              ) : policy.effect === "require_approval" ? (
+                <ShieldAlert className="h-5 w-5" />
               ) : (
                 <ShieldX className="h-5 w-5" />
               )}
@@ -93,10 +99,12 @@ function PolicyCard({
               <div className="flex flex-wrap gap-2">
                 <span className={`inline-flex items-center gap-1 rounded px-2 py-0.5 text-xs font-medium ${policy.effect === "allow"
                     ? "bg-emerald-100 text-emerald-700 dark:bg-emerald-900/30 dark:text-emerald-400"
-                    : "bg-red-100 text-red-700 dark:bg-red-900/30 dark:text-red-400"
+                    : policy.effect === "require_approval"
+                      ? "bg-amber-100 text-amber-700 dark:bg-amber-900/30 dark:text-amber-400"
+                      : "bg-red-100 text-red-700 dark:bg-red-900/30 dark:text-red-400"
                   }`}>
-                  {policy.effect === "allow" ? <ShieldCheck className="h-3 w-3" /> : <ShieldX className="h-3 w-3" />}
-                  {policy.effect.toUpperCase()}
+                  {policy.effect === "allow" ? <ShieldCheck className="h-3 w-3" /> : policy.effect === "require_approval" ? <ShieldAlert className="h-3 w-3" /> : <ShieldX className="h-3 w-3" />}
+                  {policy.effect === "require_approval" ? `APPROVAL BY ${(policy.approver_role || "admin").toUpperCase()}` : policy.effect.toUpperCase()}
                 </span>
                 <span className="rounded bg-muted px-2 py-0.5 text-xs">{targetName}</span>
                 <span className="rounded bg-muted px-2 py-0.5 text-xs">
@@ -192,7 +200,7 @@ export default function PoliciesPage() {
   const [selectedPolicy, setSelectedPolicy] = useState<AuthorizationPolicy | null>(null);
   const [newPolicy, setNewPolicy] = useState<CreatePolicyRequest>({
     name: "", description: "", target_id: undefined, resource_type: "all",
-    resource_pattern: "", effect: "allow", priority: 0, enabled: true,
+    resource_pattern: "", condition: "", effect: "allow", priority: 0, enabled: true,
   });
   const [newPolicySubjects, setNewPolicySubjects] = useState<{ subject_type: "user" | "role" | "group" | "everyone"; subject_value: string }[]>([]);
   const [newSubject, setNewSubject] = useState({
@@ -217,7 +225,7 @@ export default function PoliciesPage() {
       setIsCreateOpen(false);
       setNewPolicy({
         name: "", description: "", target_id: undefined, resource_type: "all",
-        resource_pattern: "", effect: "allow", priority: 0, enabled: true,
+        resource_pattern: "", condition: "", effect: "allow", priority: 0, enabled: true,
       });
       setNewPolicySubjects([]);
     },
@@ -260,6 +268,8 @@ export default function PoliciesPage() {
     const data: CreatePolicyRequest = { ...newPolicy };
     if (!data.target_id) delete data.target_id;
     if (!data.resource_pattern) delete data.resource_pattern;
+    if (!data.condition) delete data.condition;
+    if (data.effect !== "require_approval") delete data.approver_role;
     // Add subjects to the request
     if (newPolicySubjects.length > 0) {
       data.subjects = newPolicySubjects.map(s => ({
@@ -281,7 +291,9 @@ export default function PoliciesPage() {
           target_id: selectedPolicy.target_id,
           resource_type: selectedPolicy.resource_type,
           resource_pattern: selectedPolicy.resource_pattern,
+          condition: selectedPolicy.condition || "",
           effect: selectedPolicy.effect,
+          approver_role: selectedPolicy.approver_role || "",
           priority: selectedPolicy.priority,
           enabled: selectedPolicy.enabled,
         },
@@ -347,10 +359,11 @@ export default function PoliciesPage() {
                       id="effect"
                       className="w-full rounded-md border border-input bg-background px-3 py-2 text-sm"
                       value={newPolicy.effect}
-                      onChange={(e) => setNewPolicy({ ...newPolicy, effect: e.target.value as "allow" | "deny" })}
+                      onChange={(e) => setNewPolicy({ ...newPolicy, effect: e.target.value as PolicyEffect })}
                     >
                       <option value="allow">Allow</option>
                       <option value="deny">Deny</option>
+                      <option value="require_approval">Require approval</option>
                     </select>
                   </div>
                   <div className="space-y-2">
@@ -402,6 +415,26 @@ export default function PoliciesPage() {
                     />
                   </div>
                 </div>
+                <div className="space-y-2">
+                  <Label htmlFor="condition">Condition</Label>
+                  <Input
+                    id="condition"
+                    placeholder='e.g., args.database == "staging"'
+                    value={newPolicy.condition}
+                    onChange={(e) => setNewPolicy({ ...newPolicy, condition: e.target.value })}
+                  />
+                </div>
+                {newPolicy.effect === "require_approval" && (
+                  <div className="space-y-2">
+                    <Label htmlFor="approver_role">Approver Role</Label>
+                    <Input
+                      id="approver_role"
+                      placeholder="admin"
+                      value={newPolicy.approver_role || ""}
+                      onChange={(e) => setNewPolicy({ ...newPolicy, approver_role: e.target.value })}
+                    />
+                  </div>
+                )}
 
                 {/* Subjects Section */}
                 <div className="border-t border-border pt-4">
@@ -557,10 +590,11 @@ export default function PoliciesPage() {
                       id="edit-effect"
                       className="w-full rounded-md border border-input bg-background px-3 py-2 text-sm"
                       value={selectedPolicy.effect}
-                      onChange={(e) => setSelectedPolicy({ ...selectedPolicy, effect: e.target.value as "allow" | "deny" })}
+                      onChange={(e) => setSelectedPolicy({ ...selectedPolicy, effect: e.target.value as PolicyEffect })}
                     >
                       <option value="allow">Allow</option>
                       <option value="deny">Deny</option>
+                      <option value="require_approval">Require approval</option>
                     </select>
                   </div>
                   <div className="space-y-2">
@@ -611,6 +645,25 @@ export default function PoliciesPage() {
                     />
                   </div>
                 </div>
+                <div className="space-y-2">
+                  <Label htmlFor="edit-condition">Condition</Label>
+                  <Input
+                    id="edit-condition"
+                    value={selectedPolicy.condition || ""}
+                    onChange={(e) => setSelectedPolicy({ ...selectedPolicy, condition: e.target.value })}
+                  />
+                </div>
+                {selectedPolicy.effect === "require_approval" && (
+                  <div className="space-y-2">
+                    <Label htmlFor="edit-approver_role">Approver Role</Label>
+                    <Input
+                      id="edit-approver_role"
+                      placeholder="admin"
+                      value={selectedPolicy.approver_role || ""}
+                      onChange={(e) => setSelectedPolicy({ ...selectedPolicy, approver_role: e.target.value })}
+                    />
+                  </div>
+                )}
               </div>
             )}
             <DialogFooter>
@@ -2,7 +2,7 @@
 
 import { useState } from "react";
 import { useQuery, useMutation, useQueryClient } from "@tanstack/react-query";
-import { targetsApi, envConfigsApi, Target, CreateTargetRequest, UpdateTargetRequest, TargetEnvConfig, TransportType, Statefulness, IsolationBoundary } from "@/lib/api";
+import { targetsApi, envConfigsApi, Target, TargetHealth, CreateTargetRequest, UpdateTargetRequest, TargetEnvConfig, TransportType, Statefulness, IsolationBoundary, ArgumentValidation, LoadBalancing, TargetEndpoint } from "@/lib/api";
 import { DashboardLayout } from "@/components/dashboard-layout";
 import { Button } from "@/components/ui/button";
 import { Input } from "@/components/ui/input";
@@ -39,8 +39,22 @@ import {
   RefreshCw,
 } from "lucide-react";
 
+// Endpoints are edited as one "url [weight]" per line
+function formatEndpoints(endpoints?: TargetEndpoint[]): string {
+  return (endpoints || []).map((e) => (e.weight > 1 ? `${e.url} ${e.weight}` : e.url)).join("\n");
+}
+
+function parseEndpoints(text: string): TargetEndpoint[] {
+  return text
+    .split("\n")
+    .map((line) => line.trim().split(/\s+/))
+    .filter(([url]) => url)
+    .map(([url, weight]) => ({ url, weight: weight ? parseInt(weight) || 1 : 1 }));
+}
+
 function TargetCard({
   target,
+  health,
   onToggle,
   onDelete,
   onEdit,
@@ -48,6 +62,7 @@ function TargetCard({
   onRestartInstances,
 }: {
   target: Target;
+  health?: TargetHealth;
   onToggle: (enabled: boolean) => void;
   onDelete: () => void;
   onEdit: () => void;
@@ -101,7 +116,8 @@ function TargetCard({
                    <Radio className="h-3 w-3" />}
                   {target.transport_type === "stdio" ? "STDIO" :
                    target.transport_type === "kubernetes" ? "K8s" :
-                   target.transport_type === "sse" ? "SSE" : "HTTP"}
+                   target.transport_type === "sse" ? "SSE" :
+                   target.transport_type === "websocket" ? "WS" : "HTTP"}
                 </span>
                 <span className="inline-flex items-center gap-1 rounded bg-muted px-2 py-0.5 text-xs">
                   <Shield className="h-3 w-3" />
@@ -117,6 +133,28 @@ function TargetCard({
                     {target.isolation_boundary.replace(/_/g, " ")}
                   </span>
                 )}
+                {target.endpoints && target.endpoints.length > 1 && (
+                  <span
+                    className="rounded bg-muted px-2 py-0.5 text-xs"
+                    title={target.endpoints.map((e) => e.url).join("\n")}
+                  >
+                    {target.endpoints.length} endpoints · {target.load_balancing.replace(/_/g, " ")}
+                  </span>
+                )}
+                {target.enabled && health && health.status !== "unknown" && (
+                  <span
+                    className={`rounded px-2 py-0.5 text-xs ${
+                      health.status === "healthy"
+                        ? "bg-emerald-100 text-emerald-700 dark:bg-emerald-900 dark:text-emerald-300"
+                        : "bg-red-100 text-red-700 dark:bg-red-900 dark:text-red-300"
+                    }`}
+                    title={health.status === "unhealthy" ? health.last_error : undefined}
+                  >
+                    {health.status}
+                    {health.latency_ms !== undefined && ` · ${health.latency_ms}ms`}
+                    {health.status === "healthy" && health.tool_count !== undefined && ` · ${health.tool_count} tools`}
+                  </span>
+                )}
               </div>
             </div>
           </div>
@@ -269,15 +307,19 @@ export default function TargetsPage() {
   const [newEnvConfig, setNewEnvConfig] = useState({ key: "", value: "", description: "" });
   const [isEditOpen, setIsEditOpen] = useState(false);
   const [editTarget, setEditTarget] = useState<UpdateTargetRequest & { id: string }>({
-    id: "", name: "", url: "", transport_type: "streamable-http", command: "", args: [],
+    id: "", name: "", namespace: "", url: "", transport_type: "streamable-http", command: "", args: [],
     image: "", port: 8080, health_path: "",
     statefulness: "stateless", isolation_boundary: "shared", auth_type: "none", auth_header_name: "",
+    argument_validation: "off", cache_ttl_seconds: 0, load_balancing: "round_robin",
   });
   const [newTarget, setNewTarget] = useState<CreateTargetRequest>({
     name: "", url: "", transport_type: "streamable-http", command: "", args: [],
     image: "", port: 8080, health_path: "",
     statefulness: "stateless", isolation_boundary: "shared", auth_type: "none", auth_header_name: "",
+    argument_validation: "off", cache_ttl_seconds: 0,
   });
+  const [newEndpoints, setNewEndpoints] = useState("");
+  const [editEndpoints, setEditEndpoints] = useState("");
   const [newTargetEnvVars, setNewTargetEnvVars] = useState<{
     scopeType: "default" | "role" | "group" | "user";
     scopeValue?: string;
@@ -300,6 +342,12 @@ export default function TargetsPage() {
     queryFn: targetsApi.list,
   });
 
+  const { data: healthSummary } = useQuery({
+    queryKey: ["targetHealth"],
+    queryFn: targetsApi.listHealth,
+    refetchInterval: 30000,
+  });
+
   const { data: envConfigs = [], refetch: refetchEnvConfigs } = useQuery({
     queryKey: ["envConfigs", selectedTarget?.id],
     queryFn: () => (selectedTarget ? envConfigsApi.listAll(selectedTarget.id) : Promise.resolve([])),
@@ -334,7 +382,8 @@ export default function TargetsPage() {
     onSuccess: () => {
       queryClient.invalidateQueries({ queryKey: ["targets"] });
       setIsCreateOpen(false);
-      setNewTarget({ name: "", url: "", transport_type: "streamable-http", command: "", args: [], image: "", port: 8080, health_path: "", statefulness: "stateless", isolation_boundary: "shared", auth_type: "none", auth_header_name: "" });
+      setNewTarget({ name: "", url: "", transport_type: "streamable-http", command: "", args: [], image: "", port: 8080, health_path: "", statefulness: "stateless", isolation_boundary: "shared", auth_type: "none", auth_header_name: "", argument_validation: "off", cache_ttl_seconds: 0 });
+      setNewEndpoints("");
       setNewTargetEnvVars([]);
       setCreateEnvExpanded({ default: true, role: false, group: false, user: false });
     },
@@ -400,18 +449,21 @@ export default function TargetsPage() {
 
   const handleCreate = (e: React.FormEvent) => {
     e.preventDefault();
-    createMutation.mutate(newTarget);
+    const isHTTP = !["stdio", "kubernetes"].includes(newTarget.transport_type || "streamable-http");
+    createMutation.mutate(isHTTP ? { ...newTarget, endpoints: parseEndpoints(newEndpoints) } : newTarget);
   };
 
   const handleEdit = (e: React.FormEvent) => {
     e.preventDefault();
-    editMutation.mutate(editTarget);
+    const isHTTP = !["stdio", "kubernetes"].includes(editTarget.transport_type || "streamable-http");
+    editMutation.mutate(isHTTP ? { ...editTarget, endpoints: parseEndpoints(editEndpoints) } : editTarget);
   };
 
   const openEditDialog = (target: Target) => {
     setEditTarget({
       id: target.id,
       name: target.name,
+      namespace: target.namespace,
       url: target.url,
       transport_type: target.transport_type,
       command: target.command || "",
@@ -423,7 +475,11 @@ export default function TargetsPage() {
       isolation_boundary: target.isolation_boundary || "shared",
       auth_type: target.auth_type,
       auth_header_name: target.auth_header_name || "",
+      argument_validation: target.argument_validation || "off",
+      cache_ttl_seconds: target.cache_ttl_seconds || 0,
+      load_balancing: target.load_balancing || "round_robin",
     });
+    setEditEndpoints(formatEndpoints(target.endpoints));
     setIsEditOpen(true);
   };
 
@@ -476,6 +532,16 @@ export default function TargetsPage() {
                     required
                   />
                 </div>
+                <div className="space-y-2">
+                  <Label htmlFor="namespace">Namespace</Label>
+                  <Input
+                    id="namespace"
+                    placeholder="derived from name"
+                    value={newTarget.namespace || ""}
+                    onChange={(e) => setNewTarget({ ...newTarget, namespace: e.target.value })}
+                  />
+                  <p className="text-xs text-muted-foreground">Prefix for this server's tools, resources and prompts</p>
+                </div>
                 <div className="space-y-2">
                   <Label htmlFor="transport_type">Transport</Label>
                   <select
@@ -486,6 +552,7 @@ export default function TargetsPage() {
                   >
                     <option value="streamable-http">Streamable HTTP (auto-detects SSE)</option>
                     <option value="sse">SSE (legacy)</option>
+                    <option value="websocket">WebSocket</option>
                     <option value="stdio">STDIO (local process)</option>
                     <option value="kubernetes">Kubernetes (CRD-managed)</option>
                   </select>
@@ -571,16 +638,46 @@ export default function TargetsPage() {
                     </div>
                   </>
                 ) : (
-                  <div className="space-y-2">
-                    <Label htmlFor="url">URL</Label>
-                    <Input
-                      id="url"
-                      placeholder="http://localhost:3001/mcp"
-                      value={newTarget.url || ""}
-                      onChange={(e) => setNewTarget({ ...newTarget, url: e.target.value })}
-                      required
-                    />
-                  </div>
+                  <>
+                    <div className="space-y-2">
+                      <Label htmlFor="url">URL</Label>
+                      <Input
+                        id="url"
+                        placeholder="http://localhost:3001/mcp"
+                        value={newTarget.url || ""}
+                        onChange={(e) => setNewTarget({ ...newTarget, url: e.target.value })}
+                        required={!newEndpoints.trim()}
+                      />
+                    </div>
+                    <div className="space-y-2">
+                      <Label htmlFor="endpoints">Endpoints (optional)</Label>
+                      <textarea
+                        id="endpoints"
+                        rows={3}
+                        className="w-full rounded-md border border-input bg-background px-3 py-2 text-sm font-mono"
+                        placeholder={"http://replica-0:3001/mcp 2\nhttp://replica-1:3001/mcp"}
+                        value={newEndpoints}
+                        onChange={(e) => setNewEndpoints(e.target.value)}
+                      />
+                      <p className="text-xs text-muted-foreground">
+                        One replica per line, with an optional weight. Sessions are spread across them and fail over when one is down.
+                      </p>
+                    </div>
+                    <div className="space-y-2">
+                      <Label htmlFor="load_balancing">Load Balancing</Label>
+                      <select
+                        id="load_balancing"
+                        className="w-full rounded-md border border-input bg-background px-3 py-2 text-sm"
+                        value={newTarget.load_balancing || ""}
+                        onChange={(e) => setNewTarget({ ...newTarget, load_balancing: (e.target.value || undefined) as LoadBalancing | undefined })}
+                      >
+                        <option value="">Default (consistent hash if stateful)</option>
+                        <option value="round_robin">Round robin</option>
+                        <option value="least_outstanding">Least outstanding requests</option>
+                        <option value="consistent_hash">Consistent hash on subject</option>
+                      </select>
+                    </div>
+                  </>
                 )}
                 <div className="grid grid-cols-2 gap-3">
                   <div className="space-y-2">
@@ -634,6 +731,36 @@ export default function TargetsPage() {
                     />
                   </div>
                 )}
+                <div className="space-y-2">
+                  <Label htmlFor="argument_validation">Argument Validation</Label>
+                  <select
+                    id="argument_validation"
+                    className="w-full rounded-md border border-input bg-background px-3 py-2 text-sm"
+                    value={newTarget.argument_validation}
+                    onChange={(e) => setNewTarget({ ...newTarget, argument_validation: e.target.value as ArgumentValidation })}
+                  >
+                    <option value="off">Off</option>
+                    <option value="warn">Warn (forward and audit)</option>
+                    <option value="enforce">Enforce (reject invalid calls)</option>
+                  </select>
+                  <p className="text-xs text-muted-foreground">
+                    Checks tool call arguments against the tool&apos;s inputSchema before forwarding.
+                  </p>
+                </div>
+                <div className="space-y-2">
+                  <Label htmlFor="cache_ttl_seconds">Response Cache TTL (seconds)</Label>
+                  <Input
+                    id="cache_ttl_seconds"
+                    type="number"
+                    min={0}
+                    placeholder="0"
+                    value={newTarget.cache_ttl_seconds || ""}
+                    onChange={(e) => setNewTarget({ ...newTarget, cache_ttl_seconds: e.target.value ? parseInt(e.target.value) : 0 })}
+                  />
+                  <p className="text-xs text-muted-foreground">
+                    Caches resource reads, prompts and read-only tool results. 0 disables caching.
+                  </p>
+                </div>
 
                 {/* Environment Variables Section */}
                 <div className="border-t border-border pt-4 space-y-3">
@@ -767,6 +894,7 @@ export default function TargetsPage() {
             <TargetCard
               key={target.id}
               target={target}
+              health={healthSummary?.targets.find((h) => h.target_id === target.id)}
               onToggle={(enabled) => toggleMutation.mutate({ id: target.id, enabled })}
               onDelete={() => deleteMutation.mutate(target.id)}
               onEdit={() => openEditDialog(target)}
@@ -795,6 +923,14 @@ export default function TargetsPage() {
                   required
                 />
               </div>
+              <div className="space-y-2">
+                <Label htmlFor="edit_namespace">Namespace</Label>
+                <Input
+                  id="edit_namespace"
+                  value={editTarget.namespace || ""}
+                  onChange={(e) => setEditTarget({ ...editTarget, namespace: e.target.value })}
+                />
+              </div>
               <div className="space-y-2">
                 <Label htmlFor="edit_transport_type">Transport</Label>
                 <select
@@ -805,6 +941,7 @@ export default function TargetsPage() {
                 >
                   <option value="streamable-http">Streamable HTTP (auto-detects SSE)</option>
                   <option value="sse">SSE (legacy)</option>
+                  <option value="websocket">WebSocket</option>
                   <option value="stdio">STDIO (local process)</option>
                   <option value="kubernetes">Kubernetes (CRD-managed)</option>
                 </select>
@@ -890,15 +1027,44 @@ export default function TargetsPage() {
                   </div>
                 </>
               ) : (
-                <div className="space-y-2">
-                  <Label htmlFor="edit_url">URL</Label>
-                  <Input
-                    id="edit_url"
-                    value={editTarget.url}
-                    onChange={(e) => setEditTarget({ ...editTarget, url: e.target.value })}
-                    required
-                  />
-                </div>
+                <>
+                  <div className="space-y-2">
+                    <Label htmlFor="edit_url">URL</Label>
+                    <Input
+                      id="edit_url"
+                      value={editTarget.url}
+                      onChange={(e) => setEditTarget({ ...editTarget, url: e.target.value })}
+                      required={!editEndpoints.trim()}
+                    />
+                  </div>
+                  <div className="space-y-2">
+                    <Label htmlFor="edit_endpoints">Endpoints (optional)</Label>
+                    <textarea
+                      id="edit_endpoints"
+                      rows={3}
+                      className="w-full rounded-md border border-input bg-background px-3 py-2 text-sm font-mono"
+                      placeholder={"http://replica-0:3001/mcp 2\nhttp://replica-1:3001/mcp"}
+                      value={editEndpoints}
+                      onChange={(e) => setEditEndpoints(e.target.value)}
+                    />
+                    <p className="text-xs text-muted-foreground">
+                      One replica per line, with an optional weight. The URL defaults to the first endpoint.
+                    </p>
+                  </div>
+                  <div className="space-y-2">
+                    <Label htmlFor="edit_load_balancing">Load Balancing</Label>
+                    <select
+                      id="edit_load_balancing"
+                      className="w-full rounded-md border border-input bg-background px-3 py-2 text-sm"
+                      value={editTarget.load_balancing || "round_robin"}
+                      onChange={(e) => setEditTarget({ ...editTarget, load_balancing: e.target.value as LoadBalancing })}
+                    >
+                      <option value="round_robin">Round robin</option>
+                      <option value="least_outstanding">Least outstanding requests</option>
+                      <option value="consistent_hash">Consistent hash on subject</option>
+                    </select>
+                  </div>
+                </>
               )}
               <div className="grid grid-cols-2 gap-3">
                 <div className="space-y-2">
@@ -952,6 +1118,36 @@ export default function TargetsPage() {
                   />
                 </div>
               )}
+              <div className="space-y-2">
+                <Label htmlFor="edit_argument_validation">Argument Validation</Label>
+                <select
+                  id="edit_argument_validation"
+                  className="w-full rounded-md border border-input bg-background px-3 py-2 text-sm"
+                  value={editTarget.argument_validation}
+                  onChange={(e) => setEditTarget({ ...editTarget, argument_validation: e.target.value as ArgumentValidation })}
+                >
+                  <option value="off">Off</option>
+                  <option value="warn">Warn (forward and audit)</option>
+                  <option value="enforce">Enforce (reject invalid calls)</option>
+                </select>
+                <p className="text-xs text-muted-foreground">
+                  Checks tool call arguments against the tool&apos;s inputSchema before forwarding.
+                </p>
+              </div>
+              <div className="space-y-2">
+                <Label htmlFor="edit_cache_ttl_seconds">Response Cache TTL (seconds)</Label>
+                <Input
+                  id="edit_cache_ttl_seconds"
+                  type="number"
+                  min={0}
+                  placeholder="0"
+                  value={editTarget.cache_ttl_seconds || ""}
+                  onChange={(e) => setEditTarget({ ...editTarget, cache_ttl_seconds: e.target.value ? parseInt(e.target.value) : 0 })}
+                />
+                <p className="text-xs text-muted-foreground">
+                  Caches resource reads, prompts and read-only tool results. 0 disables caching.
+                </p>
+              </div>
             </div>
             <DialogFooter>
               <Button type="button" variant="outline" onClick={() => setIsEditOpen(false)}>
@@ -123,6 +123,10 @@ export const targetsApi = {
       method: "POST",
     }),
 
+  getHealth: (id: string) => request<TargetHealth>(`/api/targets/${id}/health`),
+
+  listHealth: () => request<TargetHealthSummary>("/api/health/targets"),
+
   // Default token (legacy, now sets default_encrypted_token)
   getToken: (id: string) =>
     request<{ has_token: boolean; created_at?: string; updated_at?: string }>(
@@ -221,6 +225,12 @@ export const policiesApi = {
     request<void>(`/api/policies/${policyId}/subjects/${subjectId}`, {
       method: "DELETE",
     }),
+
+  validateCondition: (data: ValidateConditionRequest) =>
+    request<ValidateConditionResult>("/api/policies/validate-condition", {
+      method: "POST",
+      body: data,
+    }),
 };
 
 // Environment Configs API
@@ -298,6 +308,164 @@ export const envConfigsApi = {
     }),
 };
 
+export const toolOverridesApi = {
+  list: (targetId: string) =>
+    request<ToolOverride[]>(`/api/targets/${targetId}/tools`),
+
+  get: (targetId: string, toolName: string) =>
+    request<ToolOverride>(`/api/targets/${targetId}/tools/${toolName}`),
+
+  set: (targetId: string, toolName: string, data: SetToolOverrideRequest) =>
+    request<ToolOverride>(`/api/targets/${targetId}/tools/${toolName}`, {
+      method: "PUT",
+      body: data,
+    }),
+
+  delete: (targetId: string, toolName: string) =>
+    request<void>(`/api/targets/${targetId}/tools/${toolName}`, {
+      method: "DELETE",
+    }),
+};
+
+export const virtualToolsApi = {
+  list: () => request<VirtualTool[]>("/api/virtual-tools"),
+
+  get: (id: string) => request<VirtualTool>(`/api/virtual-tools/${id}`),
+
+  create: (data: CreateVirtualToolRequest) =>
+    request<VirtualTool>("/api/virtual-tools", {
+      method: "POST",
+      body: data,
+    }),
+
+  update: (id: string, data: Partial<CreateVirtualToolRequest>) =>
+    request<VirtualTool>(`/api/virtual-tools/${id}`, {
+      method: "PUT",
+      body: data,
+    }),
+
+  delete: (id: string) =>
+    request<void>(`/api/virtual-tools/${id}`, {
+      method: "DELETE",
+    }),
+};
+
+export const redactionRulesApi = {
+  list: () => request<RedactionRule[]>("/api/redaction-rules"),
+
+  detectors: () => request<string[]>("/api/redaction-rules/detectors"),
+
+  get: (id: string) => request<RedactionRule>(`/api/redaction-rules/${id}`),
+
+  create: (data: CreateRedactionRuleRequest) =>
+    request<RedactionRule>("/api/redaction-rules", {
+      method: "POST",
+      body: data,
+    }),
+
+  update: (id: string, data: UpdateRedactionRuleRequest) =>
+    request<RedactionRule>(`/api/redaction-rules/${id}`, {
+      method: "PUT",
+      body: data,
+    }),
+
+  delete: (id: string) =>
+    request<void>(`/api/redaction-rules/${id}`, {
+      method: "DELETE",
+    }),
+};
+
+export const rateLimitsApi = {
+  list: () => request<RateLimit[]>("/api/rate-limits"),
+
+  get: (id: string) => request<RateLimit>(`/api/rate-limits/${id}`),
+
+  create: (data: CreateRateLimitRequest) =>
+    request<RateLimit>("/api/rate-limits", {
+      method: "POST",
+      body: data,
+    }),
+
+  update: (id: string, data: UpdateRateLimitRequest) =>
+    request<RateLimit>(`/api/rate-limits/${id}`, {
+      method: "PUT",
+      body: data,
+    }),
+
+  delete: (id: string) =>
+    request<void>(`/api/rate-limits/${id}`, {
+      method: "DELETE",
+    }),
+};
+
+export const usageApi = {
+  report: (params: UsageReportParams = {}) => {
+    const query = new URLSearchParams(
+      Object.entries(params).filter(([, v]) => v !== undefined && v !== "") as [string, string][]
+    ).toString();
+    return request<UsageReportRow[]>(`/api/usage${query ? `?${query}` : ""}`);
+  },
+
+  listQuotas: () => request<UsageQuota[]>("/api/usage/quotas"),
+
+  getQuota: (id: string) => request<UsageQuota>(`/api/usage/quotas/${id}`),
+
+  createQuota: (data: CreateUsageQuotaRequest) =>
+    request<UsageQuota>("/api/usage/quotas", {
+      method: "POST",
+      body: data,
+    }),
+
+  updateQuota: (id: string, data: UpdateUsageQuotaRequest) =>
+    request<UsageQuota>(`/api/usage/quotas/${id}`, {
+      method: "PUT",
+      body: data,
+    }),
+
+  deleteQuota: (id: string) =>
+    request<void>(`/api/usage/quotas/${id}`, {
+      method: "DELETE",
+    }),
+
+  listCostWeights: () => request<CostWeight[]>("/api/usage/cost-weights"),
+
+  createCostWeight: (data: CreateCostWeightRequest) =>
+    request<CostWeight>("/api/usage/cost-weights", {
+      method: "POST",
+      body: data,
+    }),
+
+  updateCostWeight: (id: string, weight: number) =>
+    request<CostWeight>(`/api/usage/cost-weights/${id}`, {
+      method: "PUT",
+      body: { weight },
+    }),
+
+  deleteCostWeight: (id: string) =>
+    request<void>(`/api/usage/cost-weights/${id}`, {
+      method: "DELETE",
+    }),
+};
+
+export const approvalsApi = {
+  list: (status?: ApprovalStatus) =>
+    request<Approval[]>(`/api/approvals${status ? `?status=${status}` : ""}`),
+
+  get: (id: string) => request<Approval>(`/api/approvals/${id}`),
+
+  approve: (id: string, reason?: string) =>
+    request<Approval>(`/api/approvals/${id}/approve`, {
+      method: "POST",
+      body: { reason },
+    }),
+
+  reject: (id: string, reason?: string) =>
+    request<Approval>(`/api/approvals/${id}/reject`, {
+      method: "POST",
+      body: { reason },
+    }),
+};
+
 // Types
 export interface User {
   id: string;
@@ -323,13 +491,21 @@ export interface APIToken {
   revoked_at: string | null;
 }
 
-export type TransportType = "streamable-http" | "sse" | "stdio" | "kubernetes";
+export type TransportType = "streamable-http" | "sse" | "websocket" | "stdio" | "kubernetes";
 export type Statefulness = "stateless" | "stateful";
 export type IsolationBoundary = "shared" | "per_group" | "per_role" | "per_user";
+export type ArgumentValidation = "off" | "warn" | "enforce";
+export type LoadBalancing = "round_robin" | "least_outstanding" | "consistent_hash";
+
+export interface TargetEndpoint {
+  url: string;
+  weight: number;
+}
 
 export interface Target {
   id: string;
   name: string;
+  namespace: string;
   url: string;
   transport_type: TransportType;
   command?: string;
@@ -341,13 +517,50 @@ export interface Target {
   isolation_boundary: IsolationBoundary;
   auth_type: string;
   auth_header_name?: string;
+  argument_validation: ArgumentValidation;
+  cache_ttl_seconds: number;
+  endpoints: TargetEndpoint[];
+  load_balancing: LoadBalancing;
   enabled: boolean;
   created_at: string;
   updated_at: string;
 }
 
+export type TargetHealthStatus = "healthy" | "unhealthy" | "unknown";
+
+export interface TargetHealth {
+  target_id: string;
+  target_name: string;
+  transport_type: TransportType;
+  enabled: boolean;
+  status: TargetHealthStatus;
+  latency_ms?: number;
+  tool_count?: number;
+  last_error?: string;
+  consecutive_failures: number;
+  last_checked_at?: string;
+  last_success_at?: string;
+  last_failure_at?: string;
+  endpoints?: EndpointHealth[];
+}
+
+export interface EndpointHealth {
+  url: string;
+  status: TargetHealthStatus;
+  latency_ms?: number;
+  error?: string;
+}
+
+export interface TargetHealthSummary {
+  healthy: number;
+  unhealthy: number;
+  unknown: number;
+  targets: TargetHealth[];
+}
+
 export interface CreateTargetRequest {
   name: string;
+  namespace?: string;
   url?: string;
   transport_type?: TransportType;
   command?: string;
@@ -359,10 +572,15 @@ export interface CreateTargetRequest {
   isolation_boundary?: IsolationBoundary;
   auth_type?: string;
   auth_header_name?: string;
+  argument_validation?: ArgumentValidation;
+  cache_ttl_seconds?: number;
+  endpoints?: TargetEndpoint[];
+  load_balancing?: LoadBalancing;
 }
 
 export interface UpdateTargetRequest {
   name?: string;
+  namespace?: string;
   url?: string;
   transport_type?: TransportType;
   command?: string;
@@ -375,6 +593,10 @@ export interface UpdateTargetRequest {
   auth_type?: string;
   auth_header_name?: string;
   enabled?: boolean;
+  argument_validation?: ArgumentValidation;
+  cache_ttl_seconds?: number;
+  endpoints?: TargetEndpoint[];
+  load_balancing?: LoadBalancing;
 }
 
 export interface RequestLog {
@@ -410,6 +632,8 @@ export interface GroupTokenInfo {
 }
 
 // Authorization Policy types
+export type PolicyEffect = "allow" | "deny" | "require_approval";
+
 export interface AuthorizationPolicy {
   id: string;
   name: string;
@@ -417,7 +641,9 @@ export interface AuthorizationPolicy {
   target_id?: string;
   resource_type: string;
   resource_pattern?: string;
-  effect: "allow" | "deny";
+  condition?: string;
+  effect: PolicyEffect;
+  approver_role?: string;
   priority: number;
   enabled: boolean;
   subjects?: PolicySubject[];
@@ -439,7 +665,9 @@ export interface CreatePolicyRequest {
   target_id?: string;
   resource_type?: string;
   resource_pattern?: string;
-  effect: "allow" | "deny";
+  condition?: string;
+  effect: PolicyEffect;
+  approver_role?: string;
   priority?: number;
   enabled?: boolean;
   subjects?: CreateSubjectRequest[];
@@ -451,16 +679,269 @@ export interface UpdatePolicyRequest {
   target_id?: string;
   resource_type?: string;
   resource_pattern?: string;
-  effect?: "allow" | "deny";
+  condition?: string;
+  effect?: PolicyEffect;
+  approver_role?: string;
   priority?: number;
   enabled?: boolean;
 }
 
+export interface ValidateConditionRequest {
+  condition: string;
+  input?: Record<string, unknown>;
+}
+
+export interface ValidateConditionResult {
+  valid: boolean;
+  error?: string;
+  result?: boolean;
+}
+
 export interface CreateSubjectRequest {
   subject_type: "user" | "role" | "group" | "everyone";
   subject_value?: string;
 }
 
+// Tool override types
+export interface ToolOverride {
+  id: string;
+  target_id: string;
+  tool_name: string;
+  name?: string;
+  description?: string;
+  input_schema?: Record<string, unknown>;
+  fixed_arguments?: Record<string, unknown>;
+  cache_ttl_seconds?: number;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface SetToolOverrideRequest {
+  name?: string;
+  description?: string;
+  input_schema?: Record<string, unknown>;
+  fixed_arguments?: Record<string, unknown>;
+  cache_ttl_seconds?: number;
+}
+
+// Virtual tool types
+export interface WorkflowStep {
+  id: string;
+  tool: string;
+  arguments?: Record<string, unknown>;
+  on_error?: "abort" | "continue";
+}
+
+export interface VirtualTool {
+  id: string;
+  name: string;
+  description?: string;
+  input_schema: Record<string, unknown>;
+  steps: WorkflowStep[];
+  output?: unknown;
+  enabled: boolean;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface CreateVirtualToolRequest {
+  name: string;
+  description?: string;
+  input_schema?: Record<string, unknown>;
+  steps: WorkflowStep[];
+  output?: unknown;
+  enabled?: boolean;
+}
+
+export type RedactionAction = "mask" | "drop" | "block";
+
+export interface RedactionRule {
+  id: string;
+  name: string;
+  description?: string;
+  detector: string;
+  pattern?: string;
+  action: RedactionAction;
+  target_id?: string;
+  role?: string;
+  enabled: boolean;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface CreateRedactionRuleRequest {
+  name: string;
+  description?: string;
+  detector: string;
+  pattern?: string;
+  action?: RedactionAction;
+  target_id?: string;
+  role?: string;
+  enabled?: boolean;
+}
+
+export interface UpdateRedactionRuleRequest {
+  name?: string;
+  description?: string;
+  detector?: string;
+  pattern?: string;
+  action?: RedactionAction;
+  target_id?: string;
+  role?: string;
+  enabled?: boolean;
+}
+
+export type RateLimitSubjectType = "user" | "role" | "group" | "everyone";
+
+export interface RateLimit {
+  id: string;
+  name: string;
+  description?: string;
+  subject_type: RateLimitSubjectType;
+  subject_value?: string;
+  target_id?: string;
+  tool_pattern?: string;
+  requests: number;
+  period_seconds: number;
+  burst: number;
+  per_user: boolean;
+  enabled: boolean;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface CreateRateLimitRequest {
+  name: string;
+  description?: string;
+  subject_type?: RateLimitSubjectType;
+  subject_value?: string;
+  target_id?: string;
+  tool_pattern?: string;
+  requests: number;
+  period_seconds: number;
+  burst?: number;
+  per_user?: boolean;
+  enabled?: boolean;
+}
+
+export interface UpdateRateLimitRequest {
+  name?: string;
+  description?: string;
+  subject_type?: RateLimitSubjectType;
+  subject_value?: string;
+  target_id?: string;
+  tool_pattern?: string;
+  requests?: number;
+  period_seconds?: number;
+  burst?: number;
+  per_user?: boolean;
+  enabled?: boolean;
+}
+
+export type UsageGroupBy = "user" | "group" | "role" | "target" | "tool" | "method" | "day" | "month";
+
+export interface UsageReportParams {
+  group_by?: UsageGroupBy;
+  from?: string;
+  to?: string;
+  user_id?: string;
+  role?: string;
+  group?: string;
+  target_id?: string;
+}
+
+export interface UsageReportRow {
+  key: string;
+  calls: number;
+  errors: number;
+  cached_calls: number;
+  request_bytes: number;
+  response_bytes: number;
+  duration_ms: number;
+  cost: number;
+}
+
+export type QuotaPeriod = "day" | "month";
+
+export interface UsageQuota {
+  id: string;
+  name: string;
+  description?: string;
+  subject_type: RateLimitSubjectType;
+  subject_value?: string;
+  target_id?: string;
+  period: QuotaPeriod;
+  max_cost: number;
+  warn_thresholds: number[];
+  per_user: boolean;
+  enabled: boolean;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface CreateUsageQuotaRequest {
+  name: string;
+  description?: string;
+  subject_type?: RateLimitSubjectType;
+  subject_value?: string;
+  target_id?: string;
+  period: QuotaPeriod;
+  max_cost: number;
+  warn_thresholds?: number[];
+  per_user?: boolean;
+  enabled?: boolean;
+}
+
+export interface UpdateUsageQuotaRequest {
+  name?: string;
+  description?: string;
+  subject_type?: RateLimitSubjectType;
+  subject_value?: string;
+  target_id?: string;
+  period?: QuotaPeriod;
+  max_cost?: number;
+  warn_thresholds?: number[];
+  per_user?: boolean;
+  enabled?: boolean;
+}
+
+export interface CostWeight {
+  id: string;
+  target_id: string;
+  tool_name?: string;
+  weight: number;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface CreateCostWeightRequest {
+  target_id: string;
+  tool_name?: string;
+  weight: number;
+}
+
+export type ApprovalStatus = "pending" | "approved" | "rejected" | "expired" | "cancelled";
+
+export interface Approval {
+  id: string;
+  policy_id?: string;
+  policy_name: string;
+  approver_role: string;
+  session_id: string;
+  user_id: string;
+  user_email?: string;
+  target_name?: string;
+  tool_name: string;
+  arguments: Record<string, unknown>;
+  status: ApprovalStatus;
+  decided_by?: string;
+  decided_by_email?: string;
+  reason?: string;
+  created_at: string;
+  expires_at: string;
+  decided_at?: string;
+}
+
 // Environment Config types
 export interface TargetEnvConfig {
   id: string;
@@ -511,7 +992,7 @@ export interface TargetStats {
 }
 
 export interface WSEvent {
-  type: "activity" | "metrics" | "session" | "error";
+  type: "activity" | "metrics" | "session" | "error" | "redaction" | "approval" | "quota";
   data: unknown;
 }
 
@@ -542,4 +1023,28 @@ export interface ErrorEvent {
   message: string;
 }
 
+export interface RedactionEvent {
+  timestamp: string;
+  user_id: string;
+  user_email?: string;
+  method: string;
+  target?: string;
+  name: string;
+  rule: string;
+  detector: string;
+  action: RedactionAction;
+  count: number;
+}
+
+export interface QuotaEvent {
+  timestamp: string;
+  user_id: string;
+  user_email?: string;
+  quota: string;
+  period: QuotaPeriod;
+  used: number;
+  max_cost: number;
+  status: "warning" | "exceeded";
+}
+
 export { ApiError };
@@ -11,10 +11,15 @@ const sidebars = {
         'authentication',
         'authorization',
         'credential-management',
+        'redaction',
+        'rate-limiting',
+        'usage',
       ],
     },
     'transports',
     'session-management',
+    'caching',
+    'resilience',
     'kubernetes-operator',
     'observability',
     'api-reference',